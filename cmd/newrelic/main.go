@@ -46,6 +46,8 @@ func init() {
 	Command.AddCommand(events.Command)
 	Command.AddCommand(install.Command)
 	Command.AddCommand(install.TestCommand)
+	Command.AddCommand(install.UninstallCommand)
+	Command.AddCommand(install.RecipeCommand)
 	Command.AddCommand(nerdgraph.Command)
 	Command.AddCommand(nerdstorage.Command)
 	Command.AddCommand(nrql.Command)
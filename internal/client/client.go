@@ -9,6 +9,7 @@ import (
 
 	"github.com/newrelic/newrelic-cli/internal/config"
 	"github.com/newrelic/newrelic-cli/internal/credentials"
+	"github.com/newrelic/newrelic-cli/internal/utils"
 )
 
 var (
@@ -44,6 +45,7 @@ func CreateNRClient(cfg *config.Config, creds *credentials.Credentials) (*newrel
 		newrelic.ConfigPersonalAPIKey(apiKey),
 		newrelic.ConfigInsightsInsertKey(insightsInsertKey),
 		newrelic.ConfigLogLevel(cfg.LogLevel),
+		newrelic.ConfigLogger(newRedactingLogger(cfg.LogLevel)),
 		newrelic.ConfigRegion(regionValue),
 		newrelic.ConfigUserAgent(userAgent),
 		newrelic.ConfigServiceName(serviceName),
@@ -54,6 +56,22 @@ func CreateNRClient(cfg *config.Config, creds *credentials.Credentials) (*newrel
 		cfgOpts = append(cfgOpts, newrelic.ConfigNerdGraphBaseURL(nerdGraphURLOverride))
 	}
 
+	proxy := cfg.Proxy
+	if proxy == "" && defProfile != nil {
+		proxy = defProfile.Proxy
+	}
+
+	if proxy != "" {
+		config.RegisterProxySecrets(proxy)
+
+		transport, err := utils.NewHTTPTransport(proxy)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		cfgOpts = append(cfgOpts, newrelic.ConfigHTTPTransport(transport))
+	}
+
 	nrClient, err := newrelic.New(cfgOpts...)
 
 	if err != nil {
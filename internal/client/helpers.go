@@ -47,3 +47,23 @@ func WithClientAndProfileFrom(configDir string, f func(c *newrelic.NewRelic, p *
 		})
 	})
 }
+
+// WithClientAndProfileProxy returns a New Relic client that sends requests
+// through the given proxy, and the default profile used to initialize it.  The
+// proxy set in the configuration is used when proxy is empty.
+func WithClientAndProfileProxy(proxy string, f func(c *newrelic.NewRelic, p *credentials.Profile)) {
+	config.WithConfigFrom(config.DefaultConfigDirectory, func(cfg *config.Config) {
+		if proxy != "" {
+			cfg.Proxy = proxy
+		}
+
+		credentials.WithCredentialsFrom(config.DefaultConfigDirectory, func(creds *credentials.Credentials) {
+			nrClient, defaultProfile, err := CreateNRClient(cfg, creds)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			f(nrClient, defaultProfile)
+		})
+	})
+}
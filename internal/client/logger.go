@@ -0,0 +1,75 @@
+package client
+
+import (
+	log "github.com/sirupsen/logrus"
+
+	"github.com/newrelic/newrelic-cli/internal/config"
+)
+
+const defaultClientLogLevel = "info"
+
+// redactingLogger implements the logging interface of the New Relic client with
+// a logger that redacts secrets, such as the license keys in the NerdGraph
+// responses the client logs at trace level.
+type redactingLogger struct {
+	logger *log.Logger
+}
+
+func newRedactingLogger(level string) *redactingLogger {
+	l := log.New()
+	l.AddHook(config.NewRedactionHook())
+
+	r := redactingLogger{
+		logger: l,
+	}
+	r.SetLevel(level)
+
+	return &r
+}
+
+func (r *redactingLogger) Error(msg string, fields ...interface{}) {
+	r.logger.WithFields(fieldMap(fields)).Error(msg)
+}
+
+func (r *redactingLogger) Warn(msg string, fields ...interface{}) {
+	r.logger.WithFields(fieldMap(fields)).Warn(msg)
+}
+
+func (r *redactingLogger) Info(msg string, fields ...interface{}) {
+	r.logger.WithFields(fieldMap(fields)).Info(msg)
+}
+
+func (r *redactingLogger) Debug(msg string, fields ...interface{}) {
+	r.logger.WithFields(fieldMap(fields)).Debug(msg)
+}
+
+func (r *redactingLogger) Trace(msg string, fields ...interface{}) {
+	r.logger.WithFields(fieldMap(fields)).Trace(msg)
+}
+
+func (r *redactingLogger) SetLevel(levelName string) {
+	if levelName == "" {
+		levelName = defaultClientLogLevel
+	}
+
+	level, err := log.ParseLevel(levelName)
+	if err != nil {
+		r.logger.Warnf("could not parse log level '%s', logging will proceed at %s level", levelName, defaultClientLogLevel)
+		level = log.InfoLevel
+	}
+
+	r.logger.SetLevel(level)
+}
+
+// fieldMap pairs the keys and values of the client's log fields.
+func fieldMap(fields []interface{}) log.Fields {
+	m := log.Fields{}
+
+	for i := 0; i+1 < len(fields); i += 2 {
+		if k, ok := fields[i].(string); ok {
+			m[k] = fields[i+1]
+		}
+	}
+
+	return m
+}
@@ -42,6 +42,8 @@ type Config struct {
 	PluginDir          string  `mapstructure:"pluginDir"`          // PluginDir is the directory where plugins will be installed
 	SendUsageData      Ternary `mapstructure:"sendUsageData"`      // SendUsageData enables sending usage statistics to New Relic
 	PreReleaseFeatures Ternary `mapstructure:"preReleaseFeatures"` // PreReleaseFeatures enables display on features within the CLI that are announced but not generally available to customers
+	RecipeRepositories string  `mapstructure:"recipeRepositories"` // RecipeRepositories is a comma-separated list of recipe repositories layered over the recipe service
+	Proxy              string  `mapstructure:"proxy"`              // Proxy is the URL of the proxy requests to New Relic and downloads are sent through
 
 	configDir string
 }
@@ -53,6 +55,18 @@ type Value struct {
 	Default interface{}
 }
 
+// RecipeRepositoryList returns the configured recipe repositories.
+func (c *Config) RecipeRepositoryList() []string {
+	repositories := []string{}
+	for _, r := range strings.Split(c.RecipeRepositories, ",") {
+		if r = strings.TrimSpace(r); r != "" {
+			repositories = append(repositories, r)
+		}
+	}
+
+	return repositories
+}
+
 // IsDefault returns true if the field's value is the default value.
 func (c *Value) IsDefault() bool {
 	if v, ok := c.Value.(string); ok {
@@ -301,6 +315,10 @@ func (c *Config) applyOverrides() error {
 		c.PreReleaseFeatures = Ternary(v)
 	}
 
+	if v := os.Getenv("NEW_RELIC_CLI_PROXY"); v != "" {
+		c.Proxy = v
+	}
+
 	return nil
 }
 
@@ -331,6 +349,12 @@ func (c *Config) validate() error {
 			if err != nil {
 				return fmt.Errorf("invalid value for '%s': %s", v.Name, err)
 			}
+		case "proxy":
+			if p := v.Value.(string); p != "" {
+				if _, err := utils.ParseProxyURL(p); err != nil {
+					return fmt.Errorf("invalid value for '%s': %s", v.Name, err)
+				}
+			}
 		}
 
 		return nil
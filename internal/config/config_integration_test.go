@@ -114,3 +114,34 @@ func TestConfigSetPluginDir(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "test", c.PluginDir)
 }
+
+func TestConfigSetRecipeRepositories(t *testing.T) {
+	f, err := ioutil.TempDir("/tmp", "newrelic")
+	assert.NoError(t, err)
+	defer os.RemoveAll(f)
+
+	c, err := LoadConfig(f)
+	assert.NoError(t, err)
+	assert.Empty(t, c.RecipeRepositoryList())
+
+	err = c.Set("recipeRepositories", "git+https://github.com/acme/recipes#v1, /opt/recipes")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"git+https://github.com/acme/recipes#v1", "/opt/recipes"}, c.RecipeRepositoryList())
+}
+
+func TestConfigSetProxy(t *testing.T) {
+	f, err := ioutil.TempDir("/tmp", "newrelic")
+	assert.NoError(t, err)
+	defer os.RemoveAll(f)
+
+	c, err := LoadConfig(f)
+	assert.NoError(t, err)
+	assert.Empty(t, c.Proxy)
+
+	err = c.Set("proxy", "http://proxy.example.com:3128")
+	assert.NoError(t, err)
+	assert.Equal(t, "http://proxy.example.com:3128", c.Proxy)
+
+	err = c.Set("proxy", "proxy.example.com:3128")
+	assert.Error(t, err)
+}
@@ -21,6 +21,8 @@ var (
 )
 
 func initLogger(logLevel string) {
+	addRedactionHook()
+
 	l := log.StandardLogger()
 
 	l.SetFormatter(&log.TextFormatter{
@@ -58,6 +60,9 @@ func InitFileLogger() {
 		}
 	}
 
+	// Secrets are redacted before entries are written to the log file.
+	addRedactionHook()
+
 	fileHook, err := NewLogrusFileHook(DefaultConfigDirectory+"/"+DefaultLogFile, os.O_CREATE|os.O_APPEND|os.O_RDWR, 0640)
 	if err == nil && !fileHookConfigured {
 		l := log.StandardLogger()
@@ -15,6 +15,7 @@ var (
 	insightsInsertKey string
 	accountID         int
 	licenseKey        string
+	proxy             string
 )
 
 // Command is the base command for managing profiles
@@ -35,7 +36,7 @@ The add command creates a new profile for use with the New Relic CLI.
 API key and region are required. An Insights insert key is optional, but required
 for posting custom events with the ` + "`newrelic events`" + `command.
 `,
-	Example: "newrelic profile add --name <profileName> --region <region> --apiKey <apiKey> --insightsInsertKey <insightsInsertKey> --accountId <accountId> --licenseKey <licenseKey>",
+	Example: "newrelic profile add --name <profileName> --region <region> --apiKey <apiKey> --insightsInsertKey <insightsInsertKey> --accountId <accountId> --licenseKey <licenseKey> --proxy <proxyURL>",
 	Run: func(cmd *cobra.Command, args []string) {
 		WithCredentials(func(creds *Credentials) {
 			p := Profile{
@@ -44,6 +45,7 @@ for posting custom events with the ` + "`newrelic events`" + `command.
 				InsightsInsertKey: insightsInsertKey,
 				AccountID:         accountID,
 				LicenseKey:        licenseKey,
+				Proxy:             proxy,
 			}
 
 			err := creds.AddProfile(profileName, p)
@@ -142,6 +144,7 @@ func init() {
 	cmdAdd.Flags().StringVarP(&insightsInsertKey, "insightsInsertKey", "", "", "your Insights insert key")
 	cmdAdd.Flags().StringVarP(&licenseKey, "licenseKey", "", "", "your license key")
 	cmdAdd.Flags().IntVarP(&accountID, "accountId", "", 0, "your account ID")
+	cmdAdd.Flags().StringVarP(&proxy, "proxy", "", "", "the proxy to send requests through when none is configured, such as http://proxy.example.com:3128")
 	err = cmdAdd.MarkFlagRequired("name")
 	if err != nil {
 		log.Error(err)
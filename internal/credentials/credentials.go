@@ -57,6 +57,11 @@ func LoadCredentials(configDir string) (*Credentials, error) {
 	creds.Profiles = *profiles
 	creds.DefaultProfile = defaultProfile
 
+	// Keep the keys of every profile out of log output.
+	for _, p := range creds.Profiles {
+		config.RegisterSecrets(p.APIKey, p.InsightsInsertKey, p.LicenseKey)
+	}
+
 	return creds, nil
 }
 
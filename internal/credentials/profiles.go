@@ -28,6 +28,7 @@ type Profile struct {
 	Region            string `mapstructure:"region" json:"region,omitempty"`                       // Region to use for New Relic resources
 	AccountID         int    `mapstructure:"accountID" json:"accountID,omitempty"`                 // AccountID to use for New Relic resources
 	LicenseKey        string `mapstructure:"licenseKey" json:"licenseKey,omitempty"`               // License key to use for agent config and ingest
+	Proxy             string `mapstructure:"proxy" json:"proxy,omitempty"`                         // Proxy to send requests through when none is configured
 }
 
 // LoadProfiles reads the credential profiles from the default path.
@@ -192,11 +193,13 @@ func (p Profile) MarshalJSON() ([]byte, error) {
 		Region            string `json:"region,omitempty"`
 		AccountID         int    `json:"accountID,omitempty"`
 		LicenseKey        string `json:"licenseKey,omitempty"`
+		Proxy             string `json:"proxy,omitempty"`
 	}{
 		APIKey:            p.APIKey,
 		InsightsInsertKey: p.InsightsInsertKey,
 		AccountID:         p.AccountID,
 		LicenseKey:        p.LicenseKey,
+		Proxy:             p.Proxy,
 		Region:            strings.ToLower(p.Region),
 	})
 }
@@ -20,4 +20,9 @@ func TestProfileMarshal(t *testing.T) {
 	m, err := p.MarshalJSON()
 	assert.NoError(t, err)
 	assert.Equal(t, `{"apiKey":"testAPIKey","region":"test"}`, string(m))
+
+	p.Proxy = "http://proxy.example.com:3128"
+	m, err = p.MarshalJSON()
+	assert.NoError(t, err)
+	assert.Equal(t, `{"apiKey":"testAPIKey","region":"test","proxy":"http://proxy.example.com:3128"}`, string(m))
 }
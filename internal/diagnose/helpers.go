@@ -6,12 +6,13 @@ import (
 	"io"
 	"io/ioutil"
 	"math/bits"
-	"net/http"
 	"os"
 	"os/exec"
 	"path"
 	"runtime"
 
+	"github.com/newrelic/newrelic-cli/internal/config"
+	"github.com/newrelic/newrelic-cli/internal/credentials"
 	"github.com/newrelic/newrelic-cli/internal/utils"
 
 	log "github.com/sirupsen/logrus"
@@ -38,8 +39,13 @@ func downloadBinary() error {
 		return fmt.Errorf("unknown operating system: %s", runtime.GOOS)
 	}
 
+	client, err := utils.NewHTTPClient(configuredProxy())
+	if err != nil {
+		return err
+	}
+
 	log.Infof("Downloading %s", downloadURL)
-	resp, err := http.Get(downloadURL)
+	resp, err := client.Get(downloadURL)
 	if err != nil {
 		log.Warnf("failed to download the latest nrdiag: %s", err)
 		home, _ := utils.GetDefaultConfigDirectory()
@@ -124,6 +130,23 @@ func runDiagnostics(args ...string) error {
 	return diagnostics.Run()
 }
 
+// configuredProxy returns the proxy set in the proxy config key, or the one set
+// in the default profile when there is none.
+func configuredProxy() string {
+	var proxy string
+	config.WithConfig(func(cfg *config.Config) {
+		proxy = cfg.Proxy
+	})
+
+	if proxy == "" {
+		if p := credentials.DefaultProfile(); p != nil {
+			proxy = p.Proxy
+		}
+	}
+
+	return proxy
+}
+
 func getBinaryPath() string {
 	configDirectory, err := utils.GetDefaultConfigDirectory()
 	if err != nil {
@@ -2,6 +2,10 @@ package install
 
 import (
 	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -9,13 +13,36 @@ import (
 	"github.com/newrelic/newrelic-cli/internal/client"
 	"github.com/newrelic/newrelic-cli/internal/config"
 	"github.com/newrelic/newrelic-cli/internal/credentials"
+	"github.com/newrelic/newrelic-cli/internal/install/execution"
+	"github.com/newrelic/newrelic-cli/internal/install/fleet"
+	"github.com/newrelic/newrelic-cli/internal/install/recipes"
 	"github.com/newrelic/newrelic-cli/internal/install/types"
+	"github.com/newrelic/newrelic-cli/internal/install/validation"
+	"github.com/newrelic/newrelic-cli/internal/utils"
 	"github.com/newrelic/newrelic-client-go/newrelic"
 )
 
 var (
 	assumeYes          bool
+	dryRun             bool
+	planFormat         string
+	resumeDocumentID   string
+	varsFile           string
+	validationTimeout  time.Duration
+	validationInterval time.Duration
+	reportPath         string
+	reportFormat       string
+	offline            bool
+	recipeCacheTTL     time.Duration
+	requireSigned      bool
+	kubernetesOutput   string
+	proxy              string
+	noRollback         bool
+	inventory          string
+	fleetConcurrency   int
 	localRecipes       string
+	recipeRepositories []string
+	allowInsecureRepos bool
 	recipeNames        []string
 	recipePaths        []string
 	skipDiscovery      bool
@@ -34,24 +61,88 @@ var Command = &cobra.Command{
 	Short: "Install New Relic.",
 	Run: func(cmd *cobra.Command, args []string) {
 		ic := InstallerContext{
-			AssumeYes:          assumeYes,
-			LocalRecipes:       localRecipes,
-			RecipeNames:        recipeNames,
-			RecipePaths:        recipePaths,
-			SkipDiscovery:      skipDiscovery,
-			SkipIntegrations:   skipIntegrations,
-			SkipLoggingInstall: skipLoggingInstall,
-			SkipApm:            skipApm,
-			SkipInfra:          skipInfra,
+			AssumeYes:            assumeYes,
+			LocalRecipes:         localRecipes,
+			RecipeRepositories:   configuredRecipeRepositories(recipeRepositories),
+			InsecureRepositories: allowInsecureRepos,
+			RecipeNames:          recipeNames,
+			RecipePaths:          recipePaths,
+			SkipDiscovery:        skipDiscovery,
+			SkipIntegrations:     skipIntegrations,
+			SkipLoggingInstall:   skipLoggingInstall,
+			SkipApm:              skipApm,
+			SkipInfra:            skipInfra,
+			DryRun:               dryRun,
+			PlanFormat:           planFormat,
+			ResumeDocumentID:     resumeDocumentID,
+			ValidationTimeout:    validationTimeout,
+			ValidationInterval:   validationInterval,
+			ReportPath:           reportPath,
+			ReportFormat:         strings.ToLower(reportFormat),
+			Offline:              offline,
+			RecipeCacheTTL:       recipeCacheTTL,
+			RequireSignedRecipes: requireSigned,
+			KubernetesOutputDir:  kubernetesOutput,
+			Proxy:                configuredProxy(proxy),
+			NoRollback:           noRollback,
+			Debug:                debug,
+			Trace:                trace,
+		}
+
+		if err := assertRecipeRepositoriesAreValid(ic.RecipeRepositories, ic.InsecureRepositories); err != nil {
+			log.Fatal(err)
+		}
+
+		if err := assertSignedRecipeRepositoriesAreValid(ic.RecipeRepositories, ic.RequireSignedRecipes); err != nil {
+			log.Fatal(err)
+		}
+
+		if err := assertPlanFormatIsValid(planFormat); err != nil {
+			log.Fatal(err)
+		}
+
+		if err := assertProxyIsValid(ic.Proxy); err != nil {
+			log.Fatal(err)
+		}
+
+		if err := assertReportFormatIsValid(reportFormat); err != nil {
+			log.Fatal(err)
+		}
+
+		if err := assertValidationPollingIsValid(validationTimeout, validationInterval); err != nil {
+			log.Fatal(err)
+		}
+
+		if varsFile != "" {
+			answers, err := execution.LoadRecipeAnswers(varsFile)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			ic.Answers = answers
+		}
+
+		if inventory != "" {
+			if err := runFleetInstall(ic, inventory, fleetConcurrency); err != nil {
+				log.Fatal(err)
+			}
+
+			return
+		}
+
+		if ic.Proxy != "" {
+			// Commands run by recipes, such as curl and package managers, read
+			// the proxy from the environment.
+			setProxyEnvironment(ic.Proxy)
 		}
 
 		config.InitFileLogger()
 
-		client.WithClientAndProfile(func(nrClient *newrelic.NewRelic, profile *credentials.Profile) {
-			if trace {
+		client.WithClientAndProfileProxy(ic.Proxy, func(nrClient *newrelic.NewRelic, profile *credentials.Profile) {
+			if ic.Trace {
 				log.SetLevel(log.TraceLevel)
 				nrClient.SetLogLevel("trace")
-			} else if debug {
+			} else if ic.Debug {
 				log.SetLevel(log.DebugLevel)
 				nrClient.SetLogLevel("debug")
 			}
@@ -63,6 +154,17 @@ var Command = &cobra.Command{
 
 			i := NewRecipeInstaller(ic, nrClient)
 
+			if ic.ResumeDocumentID != "" {
+				record, err := execution.LoadInstallHistoryRecord(execution.DefaultInstallHistoryDirectory(), ic.ResumeDocumentID)
+				if err != nil {
+					log.Fatal(err)
+				}
+
+				if err = i.ResumeFrom(record.Status); err != nil {
+					log.Fatal(err)
+				}
+			}
+
 			// Run the install.
 			if err := i.Install(); err != nil {
 				if err == types.ErrInterrupt {
@@ -82,6 +184,137 @@ func assertProfileIsValid(profile *credentials.Profile) error {
 	return nil
 }
 
+// configuredRecipeRepositories returns the recipe repositories given as flags,
+// or the ones set in the recipeRepositories config key when there are none.
+func configuredRecipeRepositories(flagValues []string) []string {
+	if len(flagValues) > 0 {
+		return flagValues
+	}
+
+	var repositories []string
+	config.WithConfig(func(cfg *config.Config) {
+		repositories = cfg.RecipeRepositoryList()
+	})
+
+	return repositories
+}
+
+// configuredProxy returns the proxy given as a flag, or the one set in the proxy
+// config key when there is none, or else the one set in the default profile.
+func configuredProxy(flagValue string) string {
+	if flagValue != "" {
+		return flagValue
+	}
+
+	var p string
+	config.WithConfig(func(cfg *config.Config) {
+		p = cfg.Proxy
+	})
+
+	if p == "" {
+		if profile := credentials.DefaultProfile(); profile != nil {
+			p = profile.Proxy
+		}
+	}
+
+	return p
+}
+
+func assertProxyIsValid(proxy string) error {
+	if proxy == "" {
+		return nil
+	}
+
+	_, err := utils.ParseProxyURL(proxy)
+	return err
+}
+
+func setProxyEnvironment(proxy string) {
+	noProxy := utils.NoProxy()
+
+	env := map[string]string{
+		"HTTPS_PROXY": proxy,
+		"HTTP_PROXY":  proxy,
+		"https_proxy": proxy,
+		"http_proxy":  proxy,
+		"NO_PROXY":    noProxy,
+		"no_proxy":    noProxy,
+	}
+
+	for k, v := range env {
+		if err := os.Setenv(k, v); err != nil {
+			log.Debugf("could not set %s: %s", k, err)
+		}
+	}
+}
+
+func assertRecipeRepositoriesAreValid(specs []string, allowInsecure bool) error {
+	opts := recipes.RecipeRepositoryOptions{
+		CacheDir:      recipeRepositoryCacheDirectory(),
+		AllowInsecure: allowInsecure,
+	}
+
+	for _, spec := range specs {
+		if _, err := recipes.NewRecipeRepositoryFetcher(spec, opts); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// assertSignedRecipeRepositoriesAreValid refuses git recipe repositories when
+// signed recipes are required, since their recipes have no signatures to check.
+// HTTP repositories are verified, and local directories are trusted.
+func assertSignedRecipeRepositoriesAreValid(specs []string, requireSigned bool) error {
+	if !requireSigned {
+		return nil
+	}
+
+	for _, spec := range specs {
+		f, err := recipes.NewRecipeRepositoryFetcher(spec, recipes.RecipeRepositoryOptions{AllowInsecure: true})
+		if err != nil {
+			return err
+		}
+
+		if _, ok := f.(*recipes.GitRecipeFetcher); ok {
+			return fmt.Errorf("recipe repository %s: git repositories cannot be used when signed recipes are required", spec)
+		}
+	}
+
+	return nil
+}
+
+func assertPlanFormatIsValid(format string) error {
+	switch execution.PlanFormat(strings.ToLower(format)) {
+	case execution.PlanFormats.TEXT, execution.PlanFormats.JSON:
+		return nil
+	}
+
+	return fmt.Errorf("unknown plan format %s, valid values are text and json", format)
+}
+
+func assertReportFormatIsValid(format string) error {
+	switch execution.ReportFormat(strings.ToLower(format)) {
+	case execution.ReportFormats.JSON, execution.ReportFormats.JUNIT:
+		return nil
+	}
+
+	return fmt.Errorf("unknown report format %s, valid values are json and junit", format)
+}
+
+func assertValidationPollingIsValid(timeout time.Duration, interval time.Duration) error {
+	if timeout <= 0 || interval <= 0 {
+		return errors.New("validation timeout and interval must be greater than zero")
+	}
+
+	if interval > timeout {
+		return fmt.Errorf("validation interval %s cannot be longer than the validation timeout %s", interval, timeout)
+	}
+
+	return nil
+}
+
 func init() {
 	Command.Flags().StringSliceVarP(&recipePaths, "recipePath", "c", []string{}, "the path to a recipe file to install")
 	Command.Flags().StringSliceVarP(&recipeNames, "recipe", "n", []string{}, "the name of a recipe to install")
@@ -95,4 +328,22 @@ func init() {
 	Command.Flags().BoolVar(&trace, "trace", false, "trace level logging")
 	Command.Flags().BoolVarP(&assumeYes, "assumeYes", "y", false, "use \"yes\" for all questions during install")
 	Command.Flags().StringVarP(&localRecipes, "localRecipes", "", "", "a path to local recipes to load instead of service other fetching")
+	Command.Flags().StringSliceVar(&recipeRepositories, "recipeRepository", []string{}, "a git repository (URL#ref), HTTP recipe index or directory to load recipes from before the recipe service; repeat for more, in order of precedence")
+	Command.Flags().BoolVar(&allowInsecureRepos, "allowInsecureRecipeRepositories", false, "allows HTTP recipe indexes served over plain http")
+	Command.Flags().BoolVar(&dryRun, "dryRun", false, "prints the install plan without executing any recipes")
+	Command.Flags().StringVar(&varsFile, "varsFile", "", "a YAML file of recipe input var values, namespaced by recipe name, for unattended installs")
+	Command.Flags().StringVar(&resumeDocumentID, "resume", "", "the document ID of a previous install to resume, re-running only the recipes that failed or were canceled")
+	Command.Flags().DurationVar(&validationTimeout, "validationTimeout", validation.DefaultTimeout, "how long to wait for data from each installed recipe to be reported to New Relic")
+	Command.Flags().DurationVar(&validationInterval, "validationInterval", validation.DefaultInterval, "how often to check for data from installed recipes")
+	Command.Flags().StringVar(&reportPath, "report", "", "a file to write the final install status to, or the outcome on each host with --inventory, for provisioning pipelines to gate on")
+	Command.Flags().StringVar(&reportFormat, "reportFormat", string(execution.ReportFormats.JSON), "the format of the --report file (json, junit)")
+	Command.Flags().BoolVar(&offline, "offline", false, "installs using only recipes cached by a previous install, without querying the recipe service or validating recipes")
+	Command.Flags().DurationVar(&recipeCacheTTL, "recipeCacheTTL", recipes.DefaultRecipeCacheTTL, "how long recipes cached from the recipe service are used before querying it again")
+	Command.Flags().BoolVar(&requireSigned, "requireSignedRecipes", false, "refuses recipe files and HTTP recipe repositories fetched by URL unless they are signed by a key in the trusted-recipe-keys directory of the config directory; cannot be combined with git recipe repositories")
+	Command.Flags().StringVar(&kubernetesOutput, "kubernetesOutput", execution.DefaultKubernetesOutputDirectory, "a directory to render the manifests and Helm values of recipes targeting a Kubernetes cluster to")
+	Command.Flags().StringVar(&proxy, "proxy", "", "the URL of a proxy to send requests to New Relic and downloads through, and to configure installed agents and integrations with; defaults to the proxy config key")
+	Command.Flags().BoolVar(&noRollback, "noRollback", false, "leaves a recipe that fails to install as it is, instead of running its rollback tasks, for debugging")
+	Command.Flags().StringVar(&inventory, "inventory", "", "a YAML inventory of hosts to install on over SSH instead of this host")
+	Command.Flags().IntVar(&fleetConcurrency, "concurrency", fleet.DefaultConcurrency, "how many hosts of the --inventory to install on at once")
+	Command.Flags().StringVar(&planFormat, "planFormat", string(execution.PlanFormats.TEXT), "the format of the install plan printed by --dryRun (text, json)")
 }
@@ -0,0 +1,114 @@
+package install
+
+import (
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/newrelic/newrelic-cli/internal/client"
+	"github.com/newrelic/newrelic-cli/internal/config"
+	"github.com/newrelic/newrelic-cli/internal/credentials"
+	"github.com/newrelic/newrelic-cli/internal/install/recipes"
+	"github.com/newrelic/newrelic-cli/internal/utils"
+	"github.com/newrelic/newrelic-client-go/newrelic"
+)
+
+const (
+	graphFormatText = "text"
+	graphFormatDOT  = "dot"
+)
+
+var (
+	graphFormat      string
+	graphRecipeNames []string
+	graphRecipePaths []string
+)
+
+var cmdGraph = &cobra.Command{
+	Use:   "graph",
+	Short: "Print the dependency graph of recipes",
+	Long: `Print the dependency graph of recipes
+
+The graph command resolves the transitive dependencies of the given recipes and
+prints the order they would be installed in.  The DOT format can be rendered
+with Graphviz.
+`,
+	Example: `newrelic install graph --recipe mysql-open-source-integration
+newrelic install graph --recipe a,b --graphFormat dot | dot -Tpng > graph.png`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := assertGraphFormatIsValid(graphFormat); err != nil {
+			log.Fatal(err)
+		}
+
+		ic := InstallerContext{
+			LocalRecipes:         localRecipes,
+			RecipeRepositories:   configuredRecipeRepositories(recipeRepositories),
+			InsecureRepositories: allowInsecureRepos,
+			RecipeNames:          graphRecipeNames,
+			RecipePaths:          graphRecipePaths,
+			SkipInfra:            skipInfra,
+		}
+
+		if err := assertRecipeRepositoriesAreValid(ic.RecipeRepositories, ic.InsecureRepositories); err != nil {
+			log.Fatal(err)
+		}
+
+		config.InitFileLogger()
+
+		client.WithClientAndProfile(func(nrClient *newrelic.NewRelic, profile *credentials.Profile) {
+			if trace {
+				log.SetLevel(log.TraceLevel)
+				nrClient.SetLogLevel("trace")
+			} else if debug {
+				log.SetLevel(log.DebugLevel)
+				nrClient.SetLogLevel("debug")
+			}
+
+			i := NewRecipeInstaller(ic, nrClient)
+
+			g, err := i.DependencyGraph(utils.SignalCtx)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			out, err := renderDependencyGraph(g, graphFormat)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			fmt.Print(out)
+		})
+	},
+}
+
+func assertGraphFormatIsValid(format string) error {
+	switch strings.ToLower(format) {
+	case graphFormatText, graphFormatDOT:
+		return nil
+	}
+
+	return fmt.Errorf("unknown graph format %s, valid values are text and dot", format)
+}
+
+func renderDependencyGraph(g *recipes.DependencyGraph, format string) (string, error) {
+	if strings.ToLower(format) == graphFormatDOT {
+		return g.DOT(), nil
+	}
+
+	return g.Text()
+}
+
+func init() {
+	Command.AddCommand(cmdGraph)
+	cmdGraph.Flags().StringSliceVarP(&graphRecipeNames, "recipe", "n", []string{}, "the name of a recipe to resolve")
+	cmdGraph.Flags().StringSliceVarP(&graphRecipePaths, "recipePath", "c", []string{}, "the path to a recipe file to resolve")
+	cmdGraph.Flags().StringVar(&graphFormat, "graphFormat", graphFormatText, "the output format of the graph (text, dot)")
+	cmdGraph.Flags().StringVarP(&localRecipes, "localRecipes", "", "", "a path to local recipes to load instead of service other fetching")
+	cmdGraph.Flags().StringSliceVar(&recipeRepositories, "recipeRepository", []string{}, "a git repository (URL#ref), HTTP recipe index or directory to load recipes from before the recipe service; repeat for more, in order of precedence")
+	cmdGraph.Flags().BoolVar(&allowInsecureRepos, "allowInsecureRecipeRepositories", false, "allows HTTP recipe indexes served over plain http")
+	cmdGraph.Flags().BoolVarP(&skipInfra, "skipInfra", "i", false, "leaves the infrastructure agent out of the graph")
+	cmdGraph.Flags().BoolVar(&debug, "debug", false, "debug level logging")
+	cmdGraph.Flags().BoolVar(&trace, "trace", false, "trace level logging")
+}
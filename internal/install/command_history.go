@@ -0,0 +1,103 @@
+package install
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/newrelic/newrelic-cli/internal/install/execution"
+	"github.com/newrelic/newrelic-cli/internal/output"
+	"github.com/newrelic/newrelic-cli/internal/utils"
+)
+
+var (
+	installHistoryLimit int
+)
+
+// installHistorySummary is a one line summary of a locally recorded install.
+type installHistorySummary struct {
+	DocumentID string
+	StartedAt  string
+	CLIVersion string
+	Complete   bool
+	Installed  int
+	Failed     int
+	Canceled   int
+	Skipped    int
+}
+
+var cmdHistory = &cobra.Command{
+	Use:   "history",
+	Short: "List installs recorded on this host",
+	Long: `List installs recorded on this host
+
+The history command lists the installs that were run on this host, most recent
+first, as recorded in the install history directory of the CLI configuration.
+`,
+	Example: `newrelic install history`,
+	Run: func(cmd *cobra.Command, args []string) {
+		records, err := execution.LoadInstallHistory(execution.DefaultInstallHistoryDirectory())
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if len(records) == 0 {
+			log.Info("no install history found. Try using the 'newrelic install' command")
+			return
+		}
+
+		if installHistoryLimit > 0 && len(records) > installHistoryLimit {
+			records = records[0:installHistoryLimit]
+		}
+
+		summaries := []installHistorySummary{}
+		for _, r := range records {
+			summaries = append(summaries, summarizeInstallHistoryRecord(r))
+		}
+
+		utils.LogIfFatal(output.Print(summaries))
+	},
+}
+
+var cmdShow = &cobra.Command{
+	Use:   "show <documentID>",
+	Short: "Show the recorded status of an install",
+	Long: `Show the recorded status of an install
+
+The show command prints the full status recorded for an install on this host,
+including recipe statuses, errors, entity GUIDs and the CLI version used.
+`,
+	Example: `newrelic install show 2e3d5d63-1b5e-4a26-9e4a-cb9b4e0a9d11`,
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		record, err := execution.LoadInstallHistoryRecord(execution.DefaultInstallHistoryDirectory(), args[0])
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		utils.LogIfFatal(output.Print(record))
+	},
+}
+
+func summarizeInstallHistoryRecord(r execution.InstallHistoryRecord) installHistorySummary {
+	s := r.Status
+
+	return installHistorySummary{
+		DocumentID: s.DocumentID,
+		StartedAt:  time.Unix(r.StartedAt, 0).Format(time.RFC3339),
+		CLIVersion: s.CLIVersion,
+		Complete:   s.Complete,
+		Installed:  len(s.RecipesInstalled),
+		Failed:     len(s.RecipesFailed),
+		Canceled:   len(s.RecipesCanceled),
+		Skipped:    len(s.RecipesSkipped),
+	}
+}
+
+func init() {
+	Command.AddCommand(cmdHistory)
+	cmdHistory.Flags().IntVarP(&installHistoryLimit, "limit", "l", 0, "the maximum number of installs to list, 0 for all")
+
+	Command.AddCommand(cmdShow)
+}
@@ -0,0 +1,88 @@
+package install
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/newrelic/newrelic-cli/internal/credentials"
+	"github.com/newrelic/newrelic-cli/internal/install/preflight"
+	"github.com/newrelic/newrelic-cli/internal/utils"
+	"github.com/newrelic/newrelic-client-go/pkg/region"
+)
+
+var (
+	preflightFormat string
+)
+
+var cmdPreflight = &cobra.Command{
+	Use:   "preflight",
+	Short: "Check this host is ready for an install",
+	Long: `Check this host is ready for an install
+
+The preflight command checks the environment an install depends on, before any
+recipe runs: the operating system, root or sudo privileges, free disk space,
+systemd and the commands recipes use, reachability of the New Relic endpoints
+of the default profile's region, and clock skew.  Endpoints are reached through
+the proxy given with --proxy or set in the proxy config key.  Each check
+passes, warns or fails.  The command exits with a non-zero code when any check
+fails.
+`,
+	Example: `newrelic install preflight
+newrelic install preflight --preflightFormat json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := assertPreflightFormatIsValid(preflightFormat); err != nil {
+			log.Fatal(err)
+		}
+
+		if debug {
+			log.SetLevel(log.DebugLevel)
+		}
+
+		results := preflight.Run(utils.SignalCtx, preflight.DefaultChecks(preflightRegion(), configuredProxy(proxy)))
+
+		if err := preflight.Render(os.Stdout, results, preflight.Format(strings.ToLower(preflightFormat))); err != nil {
+			log.Fatal(err)
+		}
+
+		if preflight.HasFailures(results) {
+			log.Fatal("preflight checks failed")
+		}
+	},
+}
+
+// preflightRegion returns the region of the default profile, or the default
+// region when there is no profile.
+func preflightRegion() region.Name {
+	p := credentials.DefaultProfile()
+	if p == nil || p.Region == "" {
+		return region.Default
+	}
+
+	r, err := region.Parse(p.Region)
+	if err != nil {
+		log.Debugf("using the default region: %s", err)
+		return region.Default
+	}
+
+	return r
+}
+
+func assertPreflightFormatIsValid(format string) error {
+	switch preflight.Format(strings.ToLower(format)) {
+	case preflight.Formats.TEXT, preflight.Formats.JSON:
+		return nil
+	}
+
+	return fmt.Errorf("unknown preflight format %s, valid values are text and json", format)
+}
+
+func init() {
+	Command.AddCommand(cmdPreflight)
+	cmdPreflight.Flags().StringVar(&preflightFormat, "preflightFormat", string(preflight.Formats.TEXT), "the output format of the checks (text, json)")
+	cmdPreflight.Flags().StringVar(&proxy, "proxy", "", "the URL of a proxy to reach New Relic endpoints through; defaults to the proxy config key")
+	cmdPreflight.Flags().BoolVar(&debug, "debug", false, "debug level logging")
+}
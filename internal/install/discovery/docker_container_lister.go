@@ -0,0 +1,141 @@
+package discovery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/newrelic/newrelic-cli/internal/install/types"
+)
+
+const (
+	defaultDockerSocketPath = "/var/run/docker.sock"
+	defaultPodmanSocketPath = "/run/podman/podman.sock"
+	dockerHostEnvVar        = "DOCKER_HOST"
+	unixSocketScheme        = "unix://"
+	containerListTimeout    = 5 * time.Second
+)
+
+// ContainerLister lists the containers running on the host.
+type ContainerLister interface {
+	ListContainers(context.Context) ([]types.Container, error)
+}
+
+// DockerContainerLister is an implementation of the ContainerLister interface
+// that lists the running containers of a runtime serving the Docker Engine API
+// on a unix socket, such as Docker or Podman.
+type DockerContainerLister struct {
+	SocketPath string
+	Runtime    string
+	client     *http.Client
+}
+
+// NewDockerContainerLister returns a new instance of DockerContainerLister.
+func NewDockerContainerLister(socketPath string, runtime string) *DockerContainerLister {
+	l := DockerContainerLister{
+		SocketPath: socketPath,
+		Runtime:    runtime,
+		client: &http.Client{
+			Timeout: containerListTimeout,
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					var d net.Dialer
+					return d.DialContext(ctx, "unix", socketPath)
+				},
+			},
+		},
+	}
+
+	return &l
+}
+
+// DefaultContainerListers returns listers for the Docker socket, or the socket
+// in DOCKER_HOST when it is a unix socket, and for the Podman socket.
+func DefaultContainerListers() []ContainerLister {
+	dockerSocketPath := defaultDockerSocketPath
+	if h := os.Getenv(dockerHostEnvVar); strings.HasPrefix(h, unixSocketScheme) {
+		dockerSocketPath = strings.TrimPrefix(h, unixSocketScheme)
+	}
+
+	return []ContainerLister{
+		NewDockerContainerLister(dockerSocketPath, "docker"),
+		NewDockerContainerLister(defaultPodmanSocketPath, "podman"),
+	}
+}
+
+type dockerContainer struct {
+	ID     string            `json:"Id"`
+	Names  []string          `json:"Names"`
+	Image  string            `json:"Image"`
+	Labels map[string]string `json:"Labels"`
+	Ports  []dockerPort      `json:"Ports"`
+}
+
+type dockerPort struct {
+	PrivatePort int    `json:"PrivatePort"`
+	PublicPort  int    `json:"PublicPort"`
+	Type        string `json:"Type"`
+}
+
+// ListContainers lists the running containers.  No containers are listed when
+// the socket does not exist.
+func (l *DockerContainerLister) ListContainers(ctx context.Context) ([]types.Container, error) {
+	if _, err := os.Stat(l.SocketPath); os.IsNotExist(err) {
+		log.Debugf("no container runtime socket at %s", l.SocketPath)
+		return nil, nil
+	}
+
+	// The host is ignored when dialing the socket.
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://localhost/containers/json", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("could not list containers from %s: %s", l.SocketPath, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("received non-2xx status code %d when listing containers from %s", resp.StatusCode, l.SocketPath)
+	}
+
+	var dcs []dockerContainer
+	if err = json.NewDecoder(resp.Body).Decode(&dcs); err != nil {
+		return nil, fmt.Errorf("could not parse containers from %s: %s", l.SocketPath, err)
+	}
+
+	containers := []types.Container{}
+	for _, dc := range dcs {
+		c := types.Container{
+			ID:      dc.ID,
+			Image:   dc.Image,
+			Labels:  dc.Labels,
+			Runtime: l.Runtime,
+		}
+
+		if len(dc.Names) > 0 {
+			c.Name = strings.TrimPrefix(dc.Names[0], "/")
+		}
+
+		for _, p := range dc.Ports {
+			c.Ports = append(c.Ports, types.ContainerPort{
+				PrivatePort: p.PrivatePort,
+				PublicPort:  p.PublicPort,
+				Protocol:    p.Type,
+			})
+		}
+
+		containers = append(containers, c)
+	}
+
+	return containers, nil
+}
@@ -0,0 +1,106 @@
+// +build unit
+
+package discovery
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/newrelic/newrelic-cli/internal/install/types"
+)
+
+const dockerContainersResponse = `[
+  {
+    "Id": "8dfafdbc3a40",
+    "Names": ["/db"],
+    "Image": "mysql:8.0",
+    "Labels": {"com.docker.compose.service": "db"},
+    "Ports": [{"PrivatePort": 3306, "PublicPort": 13306, "Type": "tcp"}, {"PrivatePort": 33060, "Type": "tcp"}]
+  }
+]`
+
+func TestDockerContainerLister_ListContainers(t *testing.T) {
+	socketPath := newFakeDockerSocket(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/containers/json", r.URL.Path)
+		_, _ = w.Write([]byte(dockerContainersResponse))
+	})
+
+	l := NewDockerContainerLister(socketPath, "docker")
+
+	containers, err := l.ListContainers(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []types.Container{
+		{
+			ID:      "8dfafdbc3a40",
+			Name:    "db",
+			Image:   "mysql:8.0",
+			Labels:  map[string]string{"com.docker.compose.service": "db"},
+			Runtime: "docker",
+			Ports: []types.ContainerPort{
+				{PrivatePort: 3306, PublicPort: 13306, Protocol: "tcp"},
+				{PrivatePort: 33060, Protocol: "tcp"},
+			},
+		},
+	}, containers)
+}
+
+func TestDockerContainerLister_ErrorStatus(t *testing.T) {
+	socketPath := newFakeDockerSocket(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	_, err := NewDockerContainerLister(socketPath, "docker").ListContainers(context.Background())
+	require.Error(t, err)
+}
+
+func TestDockerContainerLister_MissingSocket(t *testing.T) {
+	l := NewDockerContainerLister(filepath.Join(os.TempDir(), "newrelic-missing.sock"), "docker")
+
+	containers, err := l.ListContainers(context.Background())
+	require.NoError(t, err)
+	require.Empty(t, containers)
+}
+
+func TestPSUtilDiscoverer_ListContainersSkipsFailingRuntimes(t *testing.T) {
+	socketPath := newFakeDockerSocket(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(dockerContainersResponse))
+	})
+
+	failing := newFakeDockerSocket(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	d := NewPSUtilDiscovererWithContainers(nil,
+		NewDockerContainerLister(failing, "podman"),
+		NewDockerContainerLister(socketPath, "docker"),
+	)
+
+	containers := d.listContainers(context.Background())
+	require.Len(t, containers, 1)
+	require.Equal(t, "db", containers[0].Name)
+}
+
+func newFakeDockerSocket(t *testing.T, handler http.HandlerFunc) string {
+	dir, err := ioutil.TempDir("", "newrelic-docker")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	socketPath := filepath.Join(dir, "docker.sock")
+	listener, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+
+	srv := httptest.NewUnstartedServer(handler)
+	srv.Listener = listener
+	srv.Start()
+	t.Cleanup(srv.Close)
+
+	return socketPath
+}
@@ -6,7 +6,8 @@ import (
 	"github.com/newrelic/newrelic-cli/internal/install/types"
 )
 
-// FileFilterer determines the existence of files on the underlying filesystem.
+// FileFilterer determines the existence of the log sources of recipes on the
+// underlying host.  The manifest provides the processes matched for the recipes.
 type FileFilterer interface {
-	Filter(context.Context, []types.Recipe) ([]types.LogMatch, error)
+	Filter(context.Context, *types.DiscoveryManifest, []types.Recipe) ([]types.LogMatch, error)
 }
@@ -22,7 +22,7 @@ func NewGlobFileFilterer() *GlobFileFilterer {
 
 // Filter uses the patterns provided in the passed recipe to return matches based
 // on which files exist in the underlying file system.
-func (f *GlobFileFilterer) Filter(ctx context.Context, recipes []types.Recipe) ([]types.LogMatch, error) {
+func (f *GlobFileFilterer) Filter(ctx context.Context, m *types.DiscoveryManifest, recipes []types.Recipe) ([]types.LogMatch, error) {
 	fileMatches := []types.LogMatch{}
 	for _, r := range recipes {
 		for _, l := range r.LogMatch {
@@ -53,7 +53,7 @@ func TestGlobFileFilter(t *testing.T) {
 	}
 
 	f := NewGlobFileFilterer()
-	filtered, err := f.Filter(context.Background(), recipes)
+	filtered, err := f.Filter(context.Background(), nil, recipes)
 
 	require.NoError(t, err)
 	require.NotNil(t, filtered)
@@ -0,0 +1,93 @@
+package discovery
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const journalctlTimeout = 10 * time.Second
+
+// JournalReader reads the systemd journal.
+type JournalReader interface {
+	// Units lists the systemd units that have written to the journal.
+	Units(context.Context) ([]string, error)
+	// Tail returns the latest lines a systemd unit wrote to the journal.
+	Tail(ctx context.Context, unit string, lines int) ([]string, error)
+}
+
+// JournalctlReader is an implementation of the JournalReader interface that
+// queries the journal with the journalctl command.
+type JournalctlReader struct {
+	command string
+}
+
+// NewJournalctlReader returns a new instance of JournalctlReader.
+func NewJournalctlReader() *JournalctlReader {
+	r := JournalctlReader{
+		command: "journalctl",
+	}
+
+	return &r
+}
+
+// Units lists the systemd units that have written to the journal.  No units
+// are listed when journalctl is not installed.
+func (r *JournalctlReader) Units(ctx context.Context) ([]string, error) {
+	if _, err := exec.LookPath(r.command); err != nil {
+		return nil, nil
+	}
+
+	out, err := r.run(ctx, "--field", "_SYSTEMD_UNIT")
+	if err != nil {
+		return nil, err
+	}
+
+	return parseJournalLines(bytes.NewReader(out)), nil
+}
+
+// Tail returns the latest lines a systemd unit wrote to the journal.
+func (r *JournalctlReader) Tail(ctx context.Context, unit string, lines int) ([]string, error) {
+	out, err := r.run(ctx, "--unit", unit, "--lines", strconv.Itoa(lines), "--output", "cat", "--no-pager")
+	if err != nil {
+		return nil, err
+	}
+
+	return parseJournalLines(bytes.NewReader(out)), nil
+}
+
+func (r *JournalctlReader) run(ctx context.Context, args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, journalctlTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	// #nosec G204 -- the arguments are built from recipe systemd unit names
+	cmd := exec.CommandContext(ctx, r.command, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("could not read the journal: %s: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.Bytes(), nil
+}
+
+func parseJournalLines(r io.Reader) []string {
+	lines := []string{}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if l := strings.TrimSpace(scanner.Text()); l != "" {
+			lines = append(lines, l)
+		}
+	}
+
+	return lines
+}
@@ -0,0 +1,305 @@
+package discovery
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+
+	"github.com/newrelic/newrelic-cli/internal/install/types"
+)
+
+const (
+	kubeconfigEnvVar           = "KUBECONFIG"
+	kubernetesServiceHostEnv   = "KUBERNETES_SERVICE_HOST"
+	kubernetesServicePortEnv   = "KUBERNETES_SERVICE_PORT"
+	defaultServiceAccountDir   = "/var/run/secrets/kubernetes.io/serviceaccount"
+	kubernetesVersionTimeout   = 5 * time.Second
+	kubernetesSourceKubeconfig = "kubeconfig"
+	kubernetesSourceInCluster  = "in-cluster"
+)
+
+// KubernetesDiscoverer is an implementation of the Discoverer interface that
+// adds the Kubernetes cluster the host can reach to the manifest of another
+// discoverer.  A cluster is found through an in-cluster service account or the
+// current context of a kubeconfig.  Failing to find a cluster never fails
+// discovery.
+type KubernetesDiscoverer struct {
+	discoverer        Discoverer
+	KubeconfigPath    string
+	ServiceAccountDir string
+}
+
+// NewKubernetesDiscoverer returns a new instance of KubernetesDiscoverer.  The
+// kubeconfig is read from KUBECONFIG, or ~/.kube/config.
+func NewKubernetesDiscoverer(d Discoverer) *KubernetesDiscoverer {
+	k := KubernetesDiscoverer{
+		discoverer:        d,
+		KubeconfigPath:    defaultKubeconfigPath(),
+		ServiceAccountDir: defaultServiceAccountDir,
+	}
+
+	return &k
+}
+
+func (k *KubernetesDiscoverer) Discover(ctx context.Context) (*types.DiscoveryManifest, error) {
+	m, err := k.discoverer.Discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cluster, err := k.DetectCluster(ctx)
+	if err != nil {
+		log.Debugf("skipping Kubernetes discovery: %s", err)
+		return m, nil
+	}
+
+	if cluster != nil {
+		log.WithFields(log.Fields{
+			"name":    cluster.Name,
+			"version": cluster.Version,
+			"source":  cluster.Source,
+		}).Debug("discovered Kubernetes cluster")
+	}
+
+	m.Kubernetes = cluster
+
+	return m, nil
+}
+
+// DetectCluster returns the Kubernetes cluster the host can reach, or nil when
+// there is none.
+func (k *KubernetesDiscoverer) DetectCluster(ctx context.Context) (*types.KubernetesCluster, error) {
+	if host := os.Getenv(kubernetesServiceHostEnv); host != "" {
+		if _, err := os.Stat(filepath.Join(k.ServiceAccountDir, "token")); err == nil {
+			return k.inClusterCluster(ctx, host)
+		}
+	}
+
+	if k.KubeconfigPath == "" {
+		return nil, nil
+	}
+
+	if _, err := os.Stat(k.KubeconfigPath); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	return k.kubeconfigCluster(ctx)
+}
+
+func (k *KubernetesDiscoverer) inClusterCluster(ctx context.Context, host string) (*types.KubernetesCluster, error) {
+	port := os.Getenv(kubernetesServicePortEnv)
+	if port == "" {
+		port = "443"
+	}
+
+	token, err := ioutil.ReadFile(filepath.Join(k.ServiceAccountDir, "token"))
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if ca, err := ioutil.ReadFile(filepath.Join(k.ServiceAccountDir, "ca.crt")); err == nil {
+		tlsConfig.RootCAs = x509.NewCertPool()
+		tlsConfig.RootCAs.AppendCertsFromPEM(ca)
+	}
+
+	cluster := types.KubernetesCluster{
+		Server: "https://" + net.JoinHostPort(host, port),
+		Source: kubernetesSourceInCluster,
+	}
+
+	cluster.Version = kubernetesVersion(ctx, cluster.Server, tlsConfig, strings.TrimSpace(string(token)))
+
+	return &cluster, nil
+}
+
+// kubeconfig is the part of a kubeconfig file needed to reach the cluster of
+// its current context.
+type kubeconfig struct {
+	CurrentContext string `yaml:"current-context"`
+	Contexts       []struct {
+		Name    string `yaml:"name"`
+		Context struct {
+			Cluster string `yaml:"cluster"`
+			User    string `yaml:"user"`
+		} `yaml:"context"`
+	} `yaml:"contexts"`
+	Clusters []struct {
+		Name    string `yaml:"name"`
+		Cluster struct {
+			Server                   string `yaml:"server"`
+			CertificateAuthority     string `yaml:"certificate-authority"`
+			CertificateAuthorityData string `yaml:"certificate-authority-data"`
+			InsecureSkipTLSVerify    bool   `yaml:"insecure-skip-tls-verify"`
+		} `yaml:"cluster"`
+	} `yaml:"clusters"`
+	Users []struct {
+		Name string `yaml:"name"`
+		User struct {
+			Token                 string `yaml:"token"`
+			ClientCertificateData string `yaml:"client-certificate-data"`
+			ClientKeyData         string `yaml:"client-key-data"`
+		} `yaml:"user"`
+	} `yaml:"users"`
+}
+
+func (k *KubernetesDiscoverer) kubeconfigCluster(ctx context.Context) (*types.KubernetesCluster, error) {
+	content, err := ioutil.ReadFile(k.KubeconfigPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var c kubeconfig
+	if err = yaml.Unmarshal(content, &c); err != nil {
+		return nil, fmt.Errorf("could not parse kubeconfig %s: %s", k.KubeconfigPath, err)
+	}
+
+	if c.CurrentContext == "" {
+		return nil, errors.New("kubeconfig has no current context")
+	}
+
+	var clusterName, userName string
+	for _, ctx := range c.Contexts {
+		if ctx.Name == c.CurrentContext {
+			clusterName, userName = ctx.Context.Cluster, ctx.Context.User
+		}
+	}
+
+	if clusterName == "" {
+		return nil, fmt.Errorf("kubeconfig context %s not found", c.CurrentContext)
+	}
+
+	cluster := types.KubernetesCluster{
+		Name:    clusterName,
+		Context: c.CurrentContext,
+		Source:  kubernetesSourceKubeconfig,
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	for _, cl := range c.Clusters {
+		if cl.Name != clusterName {
+			continue
+		}
+
+		cluster.Server = cl.Cluster.Server
+		// #nosec G402 -- only honored when the kubeconfig asks for it
+		tlsConfig.InsecureSkipVerify = cl.Cluster.InsecureSkipTLSVerify
+
+		ca, err := kubeconfigData(cl.Cluster.CertificateAuthorityData, cl.Cluster.CertificateAuthority)
+		if err != nil {
+			return nil, err
+		}
+
+		if ca != nil {
+			tlsConfig.RootCAs = x509.NewCertPool()
+			tlsConfig.RootCAs.AppendCertsFromPEM(ca)
+		}
+	}
+
+	var token string
+	for _, u := range c.Users {
+		if u.Name != userName {
+			continue
+		}
+
+		token = u.User.Token
+
+		if u.User.ClientCertificateData != "" && u.User.ClientKeyData != "" {
+			cert, certErr := kubeconfigData(u.User.ClientCertificateData, "")
+			key, keyErr := kubeconfigData(u.User.ClientKeyData, "")
+			if certErr == nil && keyErr == nil {
+				if pair, err := tls.X509KeyPair(cert, key); err == nil {
+					tlsConfig.Certificates = []tls.Certificate{pair}
+				}
+			}
+		}
+	}
+
+	if cluster.Server != "" {
+		cluster.Version = kubernetesVersion(ctx, cluster.Server, tlsConfig, token)
+	}
+
+	return &cluster, nil
+}
+
+// kubeconfigData returns base64-encoded inline data, or the content of a file.
+func kubeconfigData(data string, path string) ([]byte, error) {
+	if data != "" {
+		return base64.StdEncoding.DecodeString(data)
+	}
+
+	if path != "" {
+		return ioutil.ReadFile(path)
+	}
+
+	return nil, nil
+}
+
+// kubernetesVersion returns the version of a cluster's API server, or an empty
+// string when it cannot be reached.
+func kubernetesVersion(ctx context.Context, server string, tlsConfig *tls.Config, token string) string {
+	client := http.Client{
+		Timeout:   kubernetesVersionTimeout,
+		Transport: &http.Transport{TLSClientConfig: tlsConfig},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(server, "/")+"/version", nil)
+	if err != nil {
+		log.Debugf("could not request the Kubernetes version: %s", err)
+		return ""
+	}
+
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		log.Debugf("could not request the Kubernetes version: %s", err)
+		return ""
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		log.Debugf("received non-2xx status code %d when requesting the Kubernetes version", resp.StatusCode)
+		return ""
+	}
+
+	var v struct {
+		GitVersion string `json:"gitVersion"`
+	}
+
+	if err = json.NewDecoder(resp.Body).Decode(&v); err != nil {
+		log.Debugf("could not parse the Kubernetes version: %s", err)
+		return ""
+	}
+
+	return v.GitVersion
+}
+
+func defaultKubeconfigPath() string {
+	if p := os.Getenv(kubeconfigEnvVar); p != "" {
+		return filepath.SplitList(p)[0]
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(home, ".kube", "config")
+}
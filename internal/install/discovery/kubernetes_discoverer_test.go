@@ -0,0 +1,98 @@
+// +build unit
+
+package discovery
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+current-context: staging
+contexts:
+  - name: staging
+    context:
+      cluster: staging-cluster
+      user: staging-user
+clusters:
+  - name: staging-cluster
+    cluster:
+      server: %s
+      certificate-authority-data: %s
+users:
+  - name: staging-user
+    user:
+      token: test-token
+`
+
+func TestKubernetesDiscoverer_Kubeconfig(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/version", r.URL.Path)
+		require.Equal(t, "Bearer test-token", r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte(`{"major": "1", "minor": "21", "gitVersion": "v1.21.2"}`))
+	}))
+	defer server.Close()
+
+	tmp, err := ioutil.TempDir("", "newrelic-kubernetes")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmp)
+
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	kubeconfigPath := filepath.Join(tmp, "config")
+	content := fmt.Sprintf(testKubeconfig, server.URL, base64.StdEncoding.EncodeToString(ca))
+	require.NoError(t, ioutil.WriteFile(kubeconfigPath, []byte(content), 0600))
+
+	d := NewKubernetesDiscoverer(NewMockDiscoverer())
+	d.KubeconfigPath = kubeconfigPath
+	d.ServiceAccountDir = filepath.Join(tmp, "serviceaccount")
+
+	m, err := d.Discover(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, m.Kubernetes)
+	require.Equal(t, "staging-cluster", m.Kubernetes.Name)
+	require.Equal(t, "staging", m.Kubernetes.Context)
+	require.Equal(t, "v1.21.2", m.Kubernetes.Version)
+	require.Equal(t, "kubeconfig", m.Kubernetes.Source)
+}
+
+func TestKubernetesDiscoverer_NoCluster(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "newrelic-kubernetes")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmp)
+
+	d := NewKubernetesDiscoverer(NewMockDiscoverer())
+	d.KubeconfigPath = filepath.Join(tmp, "config")
+	d.ServiceAccountDir = filepath.Join(tmp, "serviceaccount")
+
+	m, err := d.Discover(context.Background())
+	require.NoError(t, err)
+	require.Nil(t, m.Kubernetes)
+}
+
+func TestKubernetesDiscoverer_InvalidKubeconfig(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "newrelic-kubernetes")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmp)
+
+	kubeconfigPath := filepath.Join(tmp, "config")
+	require.NoError(t, ioutil.WriteFile(kubeconfigPath, []byte("current-context: missing\n"), 0600))
+
+	d := NewKubernetesDiscoverer(NewMockDiscoverer())
+	d.KubeconfigPath = kubeconfigPath
+	d.ServiceAccountDir = filepath.Join(tmp, "serviceaccount")
+
+	m, err := d.Discover(context.Background())
+	require.NoError(t, err)
+	require.Nil(t, m.Kubernetes)
+}
@@ -0,0 +1,357 @@
+package discovery
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/newrelic/newrelic-cli/internal/install/types"
+)
+
+const (
+	// DefaultLogMaxAge is how recently a log file must have been written to for
+	// it to be discovered.
+	DefaultLogMaxAge = 7 * 24 * time.Hour
+
+	defaultProcRoot      = "/proc"
+	logPreviewLines      = 3
+	logPreviewLineLength = 120
+	logPreviewReadSize   = 4096
+
+	// The access mode bits of the open flags reported in /proc/<pid>/fdinfo.
+	openAccessModeMask = 03
+)
+
+// logDirectoryExtensions are the extensions of files in a log or logs
+// directory that are treated as logs, in addition to .log files anywhere.
+var logDirectoryExtensions = []string{"", ".out", ".err", ".txt"}
+
+// LogSourceFilterer is an implementation of the FileFilterer interface that
+// finds the log sources of recipes: the files matching their logMatch file
+// globs, the journald units named by their logMatch systemd fields, and the log
+// files their matched processes hold open for writing.  Files that are empty or
+// have not been written to within MaxAge are dropped.  Each log source found
+// carries a preview of its latest lines.
+type LogSourceFilterer struct {
+	// MaxAge is how recently a log file must have been written to.  Files of
+	// any age are kept when it is zero.
+	MaxAge time.Duration
+	// ProcRoot is where the open files of processes are read from.
+	ProcRoot string
+	journal  JournalReader
+	now      func() time.Time
+}
+
+// NewLogSourceFilterer returns a new instance of LogSourceFilterer.
+func NewLogSourceFilterer(journal JournalReader) *LogSourceFilterer {
+	f := LogSourceFilterer{
+		MaxAge:   DefaultLogMaxAge,
+		ProcRoot: defaultProcRoot,
+		journal:  journal,
+		now:      time.Now,
+	}
+
+	return &f
+}
+
+// Filter returns the log sources found for the passed recipes.
+func (f *LogSourceFilterer) Filter(ctx context.Context, m *types.DiscoveryManifest, recipes []types.Recipe) ([]types.LogMatch, error) {
+	matches := []types.LogMatch{}
+	seen := map[string]bool{}
+
+	var units []string
+	unitsRead := false
+
+	for _, r := range recipes {
+		for _, l := range r.LogMatch {
+			if match, ok := f.matchFiles(l, seen); ok {
+				matches = append(matches, match)
+				continue
+			}
+
+			if l.Systemd == "" {
+				continue
+			}
+
+			if !unitsRead {
+				units = f.journalUnits(ctx)
+				unitsRead = true
+			}
+
+			if match, ok := f.matchUnit(ctx, l, units); ok {
+				matches = append(matches, match)
+			}
+		}
+
+		if m == nil {
+			continue
+		}
+
+		for _, p := range m.RecipeProcesses(r) {
+			for _, path := range f.openLogFiles(p) {
+				if seen[path] || !f.isCurrent(path) {
+					continue
+				}
+
+				seen[path] = true
+				matches = append(matches, types.LogMatch{
+					Name:       r.Name,
+					File:       path,
+					Attributes: logAttributes(r),
+					Preview:    previewFile(path),
+				})
+			}
+		}
+	}
+
+	return matches, nil
+}
+
+// matchFiles matches a logMatch file glob against the current log files.  The
+// preview is taken from the most recently written file.
+func (f *LogSourceFilterer) matchFiles(l types.LogMatch, seen map[string]bool) (types.LogMatch, bool) {
+	if l.File == "" {
+		return l, false
+	}
+
+	_, files := matchLogFilesFromRecipe(l)
+
+	current := []string{}
+	for _, file := range files {
+		if f.isCurrent(file) {
+			current = append(current, file)
+		}
+	}
+
+	if len(current) == 0 {
+		log.Debugf("no current log files match %s", l.File)
+		return l, false
+	}
+
+	sort.Slice(current, func(i, j int) bool {
+		return modTime(current[i]).After(modTime(current[j]))
+	})
+
+	for _, file := range current {
+		seen[file] = true
+	}
+
+	l.Preview = previewFile(current[0])
+
+	return l, true
+}
+
+// matchUnit matches a logMatch systemd unit against the units that have written
+// to the journal, with or without the .service suffix.
+func (f *LogSourceFilterer) matchUnit(ctx context.Context, l types.LogMatch, units []string) (types.LogMatch, bool) {
+	for _, u := range units {
+		if u != l.Systemd && u != l.Systemd+".service" {
+			continue
+		}
+
+		lines, err := f.journal.Tail(ctx, u, logPreviewLines)
+		if err != nil {
+			log.Debugf("could not preview the journal of %s: %s", u, err)
+		}
+
+		// The unit's journal is forwarded instead of a file.
+		l.File = ""
+		l.Preview = formatPreview(lines)
+
+		return l, true
+	}
+
+	return l, false
+}
+
+func (f *LogSourceFilterer) journalUnits(ctx context.Context) []string {
+	if f.journal == nil {
+		return nil
+	}
+
+	units, err := f.journal.Units(ctx)
+	if err != nil {
+		log.Debugf("skipping journald discovery: %s", err)
+		return nil
+	}
+
+	return units
+}
+
+// isCurrent returns true for regular files that are not empty and were written
+// to within MaxAge.
+func (f *LogSourceFilterer) isCurrent(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() || info.Size() == 0 {
+		return false
+	}
+
+	return f.MaxAge == 0 || f.now().Sub(info.ModTime()) <= f.MaxAge
+}
+
+// openLogFiles returns the log files a process holds open for writing, read
+// from its file descriptors in /proc.
+func (f *LogSourceFilterer) openLogFiles(p types.MatchedProcess) []string {
+	if p.Process == nil {
+		return nil
+	}
+
+	pid := strconv.Itoa(int(p.Process.PID()))
+	fdDir := filepath.Join(f.ProcRoot, pid, "fd")
+
+	fds, err := ioutil.ReadDir(fdDir)
+	if err != nil {
+		log.Debugf("could not read the open files of process %s: %s", pid, err)
+		return nil
+	}
+
+	files := []string{}
+	for _, fd := range fds {
+		target, err := os.Readlink(filepath.Join(fdDir, fd.Name()))
+		if err != nil || !filepath.IsAbs(target) || !isLogPath(target) {
+			continue
+		}
+
+		if !f.openForWriting(pid, fd.Name()) {
+			continue
+		}
+
+		files = append(files, target)
+	}
+
+	return files
+}
+
+// openForWriting reads the access mode of a file descriptor from the flags in
+// /proc/<pid>/fdinfo/<fd>.
+func (f *LogSourceFilterer) openForWriting(pid string, fd string) bool {
+	b, err := ioutil.ReadFile(filepath.Join(f.ProcRoot, pid, "fdinfo", fd))
+	if err != nil {
+		return false
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 || fields[0] != "flags:" {
+			continue
+		}
+
+		flags, err := strconv.ParseUint(fields[1], 8, 64)
+		if err != nil {
+			return false
+		}
+
+		return flags&openAccessModeMask != 0
+	}
+
+	return false
+}
+
+// isLogPath returns true for .log files, and for files in a log or logs
+// directory with an extension logs commonly have.
+func isLogPath(path string) bool {
+	ext := filepath.Ext(path)
+	if ext == ".log" {
+		return true
+	}
+
+	dir := filepath.ToSlash(filepath.Dir(path)) + "/"
+	if !strings.Contains(dir, "/log/") && !strings.Contains(dir, "/logs/") {
+		return false
+	}
+
+	for _, e := range logDirectoryExtensions {
+		if ext == e {
+			return true
+		}
+	}
+
+	return false
+}
+
+// logAttributes returns the attributes of a recipe's first logMatch, which are
+// applied to the log files found for its processes.
+func logAttributes(r types.Recipe) types.LogMatchAttributes {
+	if len(r.LogMatch) > 0 {
+		return r.LogMatch[0].Attributes
+	}
+
+	return types.LogMatchAttributes{}
+}
+
+func modTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return info.ModTime()
+}
+
+// previewFile returns the latest lines of a file.  Files that look binary are
+// not previewed.
+func previewFile(path string) string {
+	file, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return ""
+	}
+
+	offset := info.Size() - logPreviewReadSize
+	if offset < 0 {
+		offset = 0
+	}
+
+	b := make([]byte, info.Size()-offset)
+	if _, err = file.ReadAt(b, offset); err != nil && err != io.EOF {
+		return ""
+	}
+
+	if bytes.IndexByte(b, 0) >= 0 {
+		return ""
+	}
+
+	lines := strings.Split(string(b), "\n")
+
+	// The first line is partial when the file was read from an offset.
+	if offset > 0 && len(lines) > 0 {
+		lines = lines[1:]
+	}
+
+	return formatPreview(lines)
+}
+
+// formatPreview joins the last non-empty lines of a log, truncating long lines.
+func formatPreview(lines []string) string {
+	preview := []string{}
+	for i := len(lines) - 1; i >= 0 && len(preview) < logPreviewLines; i-- {
+		l := strings.TrimRight(lines[i], "\r")
+		if strings.TrimSpace(l) == "" {
+			continue
+		}
+
+		if len(l) > logPreviewLineLength {
+			l = l[:logPreviewLineLength] + "..."
+		}
+
+		preview = append([]string{l}, preview...)
+	}
+
+	return strings.Join(preview, "\n")
+}
@@ -0,0 +1,180 @@
+// +build unit
+
+package discovery
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/newrelic/newrelic-cli/internal/install/types"
+)
+
+type mockJournalReader struct {
+	units    []string
+	unitsErr error
+	tails    map[string][]string
+}
+
+func (r *mockJournalReader) Units(ctx context.Context) ([]string, error) {
+	return r.units, r.unitsErr
+}
+
+func (r *mockJournalReader) Tail(ctx context.Context, unit string, lines int) ([]string, error) {
+	return r.tails[unit], nil
+}
+
+func writeLogFile(t *testing.T, path string, content string, modTime time.Time) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), 0600))
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+}
+
+func TestLogSourceFilterer_Files(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+
+	writeLogFile(t, filepath.Join(dir, "nginx", "access.log"), "GET /\nGET /health\n", now)
+	writeLogFile(t, filepath.Join(dir, "nginx", "error.log"), "", now)
+	writeLogFile(t, filepath.Join(dir, "mysql", "slow.log"), "SELECT 1\n", now.Add(-30*24*time.Hour))
+
+	recipes := []types.Recipe{
+		{Name: "nginx", LogMatch: []types.LogMatch{{Name: "nginx", File: filepath.Join(dir, "nginx", "*.log")}}},
+		{Name: "mysql", LogMatch: []types.LogMatch{{Name: "mysql", File: filepath.Join(dir, "mysql", "*.log")}}},
+	}
+
+	f := NewLogSourceFilterer(nil)
+	matches, err := f.Filter(context.Background(), nil, recipes)
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	require.Equal(t, filepath.Join(dir, "nginx", "*.log"), matches[0].File)
+	require.Equal(t, "GET /\nGET /health", matches[0].Preview)
+
+	f.MaxAge = 0
+	matches, err = f.Filter(context.Background(), nil, recipes)
+	require.NoError(t, err)
+	require.Len(t, matches, 2)
+}
+
+func TestLogSourceFilterer_Journald(t *testing.T) {
+	recipes := []types.Recipe{
+		{Name: "nginx", LogMatch: []types.LogMatch{{Name: "nginx", File: "/nonexistent/nginx/*.log", Systemd: "nginx"}}},
+		{Name: "redis", LogMatch: []types.LogMatch{{Name: "redis", Systemd: "redis-server"}}},
+	}
+
+	j := &mockJournalReader{
+		units: []string{"nginx.service", "sshd.service"},
+		tails: map[string][]string{"nginx.service": {"Started nginx."}},
+	}
+
+	f := NewLogSourceFilterer(j)
+	matches, err := f.Filter(context.Background(), nil, recipes)
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	require.Equal(t, "nginx", matches[0].Systemd)
+	require.Empty(t, matches[0].File)
+	require.Equal(t, "Started nginx.", matches[0].Preview)
+
+	j.unitsErr = errors.New("journalctl failed")
+	matches, err = f.Filter(context.Background(), nil, recipes)
+	require.NoError(t, err)
+	require.Empty(t, matches)
+}
+
+func TestLogSourceFilterer_ProcessOpenFiles(t *testing.T) {
+	dir := t.TempDir()
+	procRoot := filepath.Join(dir, "proc")
+	now := time.Now()
+
+	appLog := filepath.Join(dir, "var", "log", "app", "app.log")
+	globLog := filepath.Join(dir, "var", "log", "app", "access.log")
+	readLog := filepath.Join(dir, "var", "log", "app", "read.log")
+	data := filepath.Join(dir, "var", "lib", "app", "data.db")
+
+	writeLogFile(t, appLog, "started\n", now)
+	writeLogFile(t, globLog, "GET /\n", now)
+	writeLogFile(t, readLog, "old\n", now)
+	writeLogFile(t, data, "data", now)
+
+	fds := []struct {
+		target string
+		flags  string
+	}{
+		{appLog, "02102001"},
+		{globLog, "02102001"},
+		{readLog, "0100000"},
+		{data, "0100002"},
+		{"socket:[1234]", "02"},
+	}
+
+	require.NoError(t, os.MkdirAll(filepath.Join(procRoot, "42", "fd"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(procRoot, "42", "fdinfo"), 0755))
+	for i, fd := range fds {
+		name := string(rune('3' + i))
+		require.NoError(t, os.Symlink(fd.target, filepath.Join(procRoot, "42", "fd", name)))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(procRoot, "42", "fdinfo", name), []byte("pos:\t0\nflags:\t"+fd.flags+"\n"), 0600))
+	}
+
+	m := types.DiscoveryManifest{
+		Processes: []types.MatchedProcess{
+			{Command: "app", MatchingPattern: "app", Process: mockProcess{name: "app", pid: 42}},
+		},
+	}
+
+	recipes := []types.Recipe{
+		{
+			Name:         "app",
+			ProcessMatch: []string{"app"},
+			LogMatch: []types.LogMatch{
+				{Name: "app", File: filepath.Join(dir, "var", "log", "app", "access*.log"), Attributes: types.LogMatchAttributes{LogType: "app"}},
+			},
+		},
+	}
+
+	f := NewLogSourceFilterer(nil)
+	f.ProcRoot = procRoot
+
+	matches, err := f.Filter(context.Background(), &m, recipes)
+	require.NoError(t, err)
+	require.Len(t, matches, 2)
+	require.Equal(t, recipes[0].LogMatch[0].File, matches[0].File)
+	require.Equal(t, appLog, matches[1].File)
+	require.Equal(t, "app", matches[1].Name)
+	require.Equal(t, "app", matches[1].Attributes.LogType)
+	require.Equal(t, "started", matches[1].Preview)
+}
+
+func TestIsLogPath(t *testing.T) {
+	require.True(t, isLogPath("/opt/app/app.log"))
+	require.True(t, isLogPath("/var/log/messages"))
+	require.True(t, isLogPath("/opt/app/logs/stdout.out"))
+	require.False(t, isLogPath("/var/log/journal/system.journal"))
+	require.False(t, isLogPath("/var/lib/mysql/ibdata1"))
+}
+
+func TestPreviewFile(t *testing.T) {
+	dir := t.TempDir()
+
+	lines := []string{}
+	for i := 0; i < 1000; i++ {
+		lines = append(lines, strings.Repeat("x", 10))
+	}
+	lines = append(lines, strings.Repeat("y", 200), "", "last")
+
+	path := filepath.Join(dir, "big.log")
+	writeLogFile(t, path, strings.Join(lines, "\n")+"\n", time.Now())
+
+	preview := strings.Split(previewFile(path), "\n")
+	require.Equal(t, []string{strings.Repeat("x", 10), strings.Repeat("y", logPreviewLineLength) + "...", "last"}, preview)
+
+	binary := filepath.Join(dir, "binary.log")
+	writeLogFile(t, binary, "a\x00b", time.Now())
+	require.Empty(t, previewFile(binary))
+}
@@ -19,7 +19,7 @@ func NewMockFileFilterer() *MockFileFilterer {
 	return &MockFileFilterer{}
 }
 
-func (m *MockFileFilterer) Filter(ctx context.Context, recipes []types.Recipe) ([]types.LogMatch, error) {
+func (m *MockFileFilterer) Filter(ctx context.Context, manifest *types.DiscoveryManifest, recipes []types.Recipe) ([]types.LogMatch, error) {
 	m.FilterCallCount++
 	return m.FilterVal, m.FilterErr
 }
@@ -1,9 +1,12 @@
 package discovery
 
+import "github.com/newrelic/newrelic-cli/internal/install/types"
+
 type mockProcess struct {
 	cmdline string
 	name    string
 	pid     int32
+	ports   []types.ListeningPort
 }
 
 func (p mockProcess) Name() (string, error) {
@@ -17,3 +20,7 @@ func (p mockProcess) Cmdline() (string, error) {
 func (p mockProcess) PID() int32 {
 	return p.pid
 }
+
+func (p mockProcess) ListeningPorts() ([]types.ListeningPort, error) {
+	return p.ports, nil
+}
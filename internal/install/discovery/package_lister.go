@@ -0,0 +1,225 @@
+package discovery
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/newrelic/newrelic-cli/internal/install/types"
+)
+
+const (
+	defaultDpkgStatusPath = "/var/lib/dpkg/status"
+	dpkgInstalledStatus   = "install ok installed"
+	rpmQueryFormat        = `%{NAME}\t%{VERSION}\n`
+	packageListTimeout    = 30 * time.Second
+)
+
+// PackageLister lists the packages installed on the host.
+type PackageLister interface {
+	ListPackages(context.Context) ([]types.Package, error)
+}
+
+// DpkgPackageLister is an implementation of the PackageLister interface that
+// reads the installed packages from the dpkg status file.
+type DpkgPackageLister struct {
+	StatusPath string
+}
+
+// NewDpkgPackageLister returns a new instance of DpkgPackageLister.
+func NewDpkgPackageLister(statusPath string) *DpkgPackageLister {
+	l := DpkgPackageLister{
+		StatusPath: statusPath,
+	}
+
+	return &l
+}
+
+// ListPackages lists the installed packages.  No packages are listed when the
+// status file does not exist.
+func (l *DpkgPackageLister) ListPackages(ctx context.Context) ([]types.Package, error) {
+	f, err := os.Open(l.StatusPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+	defer f.Close()
+
+	return parseDpkgStatus(f)
+}
+
+// parseDpkgStatus parses the stanzas of a dpkg status file, keeping the
+// packages that are installed.
+func parseDpkgStatus(r io.Reader) ([]types.Package, error) {
+	packages := []types.Package{}
+
+	var name, version, status string
+	flush := func() {
+		if name != "" && status == dpkgInstalledStatus {
+			packages = append(packages, types.Package{Name: name, Version: version, Source: "dpkg"})
+		}
+
+		name, version, status = "", "", ""
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			flush()
+			continue
+		}
+
+		// Continuation lines belong to multi-line fields such as Description.
+		if strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") {
+			continue
+		}
+
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		value := strings.TrimSpace(parts[1])
+		switch parts[0] {
+		case "Package":
+			name = value
+		case "Version":
+			version = value
+		case "Status":
+			status = value
+		}
+	}
+
+	flush()
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("could not read dpkg status: %s", err)
+	}
+
+	return packages, nil
+}
+
+// RpmPackageLister is an implementation of the PackageLister interface that
+// reads the installed packages from the rpm database by querying it with the
+// rpm command, since the database format differs between rpm versions.
+type RpmPackageLister struct {
+	command string
+}
+
+// NewRpmPackageLister returns a new instance of RpmPackageLister.
+func NewRpmPackageLister() *RpmPackageLister {
+	l := RpmPackageLister{
+		command: "rpm",
+	}
+
+	return &l
+}
+
+// ListPackages lists the installed packages.  No packages are listed when rpm is
+// not installed.
+func (l *RpmPackageLister) ListPackages(ctx context.Context) ([]types.Package, error) {
+	path, err := exec.LookPath(l.command)
+	if err != nil {
+		log.Debugf("%s not found, skipping rpm package discovery", l.command)
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, packageListTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	// #nosec G204 -- the query is a constant
+	cmd := exec.CommandContext(ctx, path, "-qa", "--queryformat", rpmQueryFormat)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("could not query the rpm database: %s: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	return parseRpmQuery(&stdout), nil
+}
+
+// parseRpmQuery parses the tab-separated names and versions printed by rpm.
+func parseRpmQuery(r io.Reader) []types.Package {
+	packages := []types.Package{}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		parts := strings.SplitN(strings.TrimSpace(scanner.Text()), "\t", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			continue
+		}
+
+		packages = append(packages, types.Package{Name: parts[0], Version: parts[1], Source: "rpm"})
+	}
+
+	return packages
+}
+
+// DefaultPackageListers returns listers for the dpkg status file and the rpm
+// database.
+func DefaultPackageListers() []PackageLister {
+	return []PackageLister{
+		NewDpkgPackageLister(defaultDpkgStatusPath),
+		NewRpmPackageLister(),
+	}
+}
+
+// PackageDiscoverer is an implementation of the Discoverer interface that adds
+// the packages installed on the host to the manifest of another discoverer.  A
+// package manager that cannot be read is skipped, so that discovery never fails
+// because of packages.
+type PackageDiscoverer struct {
+	discoverer Discoverer
+	listers    []PackageLister
+}
+
+// NewPackageDiscoverer returns a new instance of PackageDiscoverer.
+func NewPackageDiscoverer(d Discoverer, listers ...PackageLister) *PackageDiscoverer {
+	p := PackageDiscoverer{
+		discoverer: d,
+		listers:    listers,
+	}
+
+	return &p
+}
+
+func (p *PackageDiscoverer) Discover(ctx context.Context) (*types.DiscoveryManifest, error) {
+	m, err := p.discoverer.Discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, l := range p.listers {
+		packages, err := l.ListPackages(ctx)
+		if err != nil {
+			log.Debugf("skipping package discovery: %s", err)
+			continue
+		}
+
+		for _, pkg := range packages {
+			m.AddPackage(pkg)
+		}
+	}
+
+	if len(m.Packages) > 0 {
+		log.Debugf("discovered %d installed packages", len(m.Packages))
+	}
+
+	return m, nil
+}
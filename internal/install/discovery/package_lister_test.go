@@ -0,0 +1,67 @@
+// +build unit
+
+package discovery
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/newrelic/newrelic-cli/internal/install/types"
+)
+
+const dpkgStatus = `Package: nginx
+Status: install ok installed
+Priority: optional
+Version: 1.18.0-0ubuntu1.2
+Description: small, powerful, scalable web/proxy server
+ Nginx ("engine X") is a high-performance web and reverse proxy server.
+
+Package: apache2
+Status: deinstall ok config-files
+Version: 2.4.41-4ubuntu3
+
+Package: redis-server
+Status: install ok installed
+Version: 5:5.0.7-2
+`
+
+func TestParseDpkgStatus(t *testing.T) {
+	packages, err := parseDpkgStatus(strings.NewReader(dpkgStatus))
+	require.NoError(t, err)
+	require.Equal(t, []types.Package{
+		{Name: "nginx", Version: "1.18.0-0ubuntu1.2", Source: "dpkg"},
+		{Name: "redis-server", Version: "5:5.0.7-2", Source: "dpkg"},
+	}, packages)
+}
+
+func TestParseRpmQuery(t *testing.T) {
+	packages := parseRpmQuery(strings.NewReader("nginx\t1.20.1\nmalformed\npostgresql-server\t13.4\n"))
+	require.Equal(t, []types.Package{
+		{Name: "nginx", Version: "1.20.1", Source: "rpm"},
+		{Name: "postgresql-server", Version: "13.4", Source: "rpm"},
+	}, packages)
+}
+
+type mockPackageLister struct {
+	packages []types.Package
+	err      error
+}
+
+func (l mockPackageLister) ListPackages(context.Context) ([]types.Package, error) {
+	return l.packages, l.err
+}
+
+func TestPackageDiscoverer_SkipsFailingListers(t *testing.T) {
+	d := NewPackageDiscoverer(NewMockDiscoverer(),
+		mockPackageLister{err: errors.New("rpm database locked")},
+		mockPackageLister{packages: []types.Package{{Name: "nginx", Version: "1.18.0", Source: "dpkg"}}},
+	)
+
+	m, err := d.Discover(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []types.Package{{Name: "nginx", Version: "1.18.0", Source: "dpkg"}}, m.Packages)
+}
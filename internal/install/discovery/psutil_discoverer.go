@@ -14,7 +14,8 @@ import (
 )
 
 type PSUtilDiscoverer struct {
-	processFilterer ProcessFilterer
+	processFilterer  ProcessFilterer
+	containerListers []ContainerLister
 }
 
 func NewPSUtilDiscoverer(f ProcessFilterer) *PSUtilDiscoverer {
@@ -25,6 +26,17 @@ func NewPSUtilDiscoverer(f ProcessFilterer) *PSUtilDiscoverer {
 	return &d
 }
 
+// NewPSUtilDiscovererWithContainers returns a PSUtilDiscoverer that also lists
+// the containers running on the host, so that recipes can match them.
+func NewPSUtilDiscovererWithContainers(f ProcessFilterer, listers ...ContainerLister) *PSUtilDiscoverer {
+	d := PSUtilDiscoverer{
+		processFilterer:  f,
+		containerListers: listers,
+	}
+
+	return &d
+}
+
 func (p *PSUtilDiscoverer) Discover(ctx context.Context) (*types.DiscoveryManifest, error) {
 	i, err := host.InfoWithContext(ctx)
 	if err != nil {
@@ -43,6 +55,10 @@ func (p *PSUtilDiscoverer) Discover(ctx context.Context) (*types.DiscoveryManife
 
 	m = filterValues(m)
 
+	for _, c := range p.listContainers(ctx) {
+		m.AddContainer(c)
+	}
+
 	pids, err := process.PidsWithContext(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("cannot retrieve processes: %s", err)
@@ -74,6 +90,27 @@ func (p *PSUtilDiscoverer) Discover(ctx context.Context) (*types.DiscoveryManife
 	return &m, nil
 }
 
+// listContainers lists containers from every lister.  A runtime that cannot be
+// reached is skipped, so that discovery never fails because of containers.
+func (p *PSUtilDiscoverer) listContainers(ctx context.Context) []types.Container {
+	containers := []types.Container{}
+	for _, l := range p.containerListers {
+		c, err := l.ListContainers(ctx)
+		if err != nil {
+			log.Debugf("skipping container discovery: %s", err)
+			continue
+		}
+
+		containers = append(containers, c...)
+	}
+
+	if len(containers) > 0 {
+		log.Debugf("discovered %d containers", len(containers))
+	}
+
+	return containers
+}
+
 func filterValues(m types.DiscoveryManifest) types.DiscoveryManifest {
 	if !isValidOpenInstallationPlatform(m.Platform) {
 		m.Platform = ""
@@ -1,7 +1,16 @@
 package discovery
 
 import (
+	"github.com/shirou/gopsutil/net"
 	"github.com/shirou/gopsutil/process"
+
+	"github.com/newrelic/newrelic-cli/internal/install/types"
+)
+
+const (
+	connectionTypeTCP = 1
+	connectionTypeUDP = 2
+	listenStatus      = "LISTEN"
 )
 
 type PSUtilProcess process.Process
@@ -29,3 +38,24 @@ func (p PSUtilProcess) Cmdline() (string, error) {
 func (p PSUtilProcess) PID() int32 {
 	return process.Process(p).Pid
 }
+
+// ListeningPorts returns the TCP ports the process listens on and the UDP ports
+// it has bound without connecting to a remote address.
+func (p PSUtilProcess) ListeningPorts() ([]types.ListeningPort, error) {
+	conns, err := net.ConnectionsPid("inet", process.Process(p).Pid)
+	if err != nil {
+		return nil, err
+	}
+
+	ports := []types.ListeningPort{}
+	for _, c := range conns {
+		switch {
+		case c.Type == connectionTypeTCP && c.Status == listenStatus:
+			ports = append(ports, types.ListeningPort{Port: int(c.Laddr.Port), Protocol: "tcp"})
+		case c.Type == connectionTypeUDP && c.Laddr.Port != 0 && c.Raddr.Port == 0:
+			ports = append(ports, types.ListeningPort{Port: int(c.Laddr.Port), Protocol: "udp"})
+		}
+	}
+
+	return ports, nil
+}
@@ -41,6 +41,21 @@ func (f *RegexProcessFilterer) filter(ctx context.Context, processes []types.Gen
 		log.Tracef("Match using process command: %s", p.Command)
 		for _, r := range recipes {
 			if match(r, &p) {
+				p.Ports = listeningPorts(&p)
+				matches = append(matches, p)
+				continue
+			}
+
+			if matchPort(r, &p) {
+				matches = append(matches, p)
+			}
+		}
+	}
+
+	for _, c := range manifest.Containers {
+		log.Tracef("Match using container image: %s", c.Image)
+		for _, r := range recipes {
+			if p, ok := matchContainer(r, c); ok {
 				matches = append(matches, p)
 			}
 		}
@@ -50,6 +65,89 @@ func (f *RegexProcessFilterer) filter(ctx context.Context, processes []types.Gen
 	return matches, nil
 }
 
+// matchContainer matches a container's image against a recipe's imageMatch
+// patterns.  The recipe service recommends recipes by the processMatch pattern
+// of each matched process, so a matched container stands in for the process it
+// runs and reports the recipe's first processMatch pattern when it has one.
+func matchContainer(r types.Recipe, c types.Container) (types.MatchedProcess, bool) {
+	for _, pattern := range r.ImageMatch {
+		matched, err := regexp.MatchString(pattern, c.Image)
+		if err != nil {
+			log.Debugf("could not execute pattern %s against container image %s", pattern, c.Image)
+			continue
+		}
+
+		if !matched {
+			continue
+		}
+
+		matchingPattern := pattern
+		if len(r.ProcessMatch) > 0 {
+			matchingPattern = r.ProcessMatch[0]
+		}
+
+		log.Debugf("Container image %s matching pattern %s for recipe %s.", c.Image, pattern, r.DisplayName)
+
+		return types.MatchedProcess{
+			Command:         fmt.Sprintf("%s container %s (%s)", c.Runtime, c.Name, c.Image),
+			MatchingPattern: matchingPattern,
+		}, true
+	}
+
+	return types.MatchedProcess{}, false
+}
+
+// matchPort matches the ports a process listens on against a recipe's portMatch
+// ports, for services run by generic launchers such as java or python whose
+// command lines do not identify them.  As with containers, a process matched by
+// port reports the recipe's first processMatch pattern, which the recipe
+// service recommends it by, so recipes without one are not matched by port.
+func matchPort(r types.Recipe, matchedProcess *types.MatchedProcess) bool {
+	if len(r.PortMatch) == 0 || len(r.ProcessMatch) == 0 {
+		return false
+	}
+
+	ports := listeningPorts(matchedProcess)
+
+	for _, lp := range ports {
+		for _, port := range r.PortMatch {
+			if lp.Port != port {
+				continue
+			}
+
+			matchedProcess.MatchingPattern = r.ProcessMatch[0]
+
+			matchedProcess.Ports = ports
+			log.Debugf("Process %s listening on port %d for recipe %s.", matchedProcess.Command, port, r.DisplayName)
+			return true
+		}
+	}
+
+	return false
+}
+
+// listeningPorts returns the ports a matched process listens on, reading them
+// once per process since doing so scans the host's sockets.
+func listeningPorts(matchedProcess *types.MatchedProcess) []types.ListeningPort {
+	if matchedProcess.Ports != nil || matchedProcess.Process == nil {
+		return matchedProcess.Ports
+	}
+
+	ports, err := matchedProcess.Process.ListeningPorts()
+	if err != nil {
+		log.Debugf("could not read the listening ports of process %d: %s", matchedProcess.Process.PID(), err)
+		ports = []types.ListeningPort{}
+	}
+
+	if ports == nil {
+		ports = []types.ListeningPort{}
+	}
+
+	matchedProcess.Ports = ports
+
+	return ports
+}
+
 func match(r types.Recipe, matchedProcess *types.MatchedProcess) bool {
 	for _, pattern := range r.ProcessMatch {
 		matched, err := regexp.Match(pattern, []byte(matchedProcess.Command))
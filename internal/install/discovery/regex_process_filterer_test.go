@@ -267,3 +267,117 @@ func TestFilter_MultipleMatchingProcesses_MultipleRecipes(t *testing.T) {
 	require.Equal(t, filtered[2].MatchingPattern, "java")
 	require.Equal(t, filtered[3].MatchingPattern, "java.*jboss")
 }
+
+func TestFilter_MatchesContainerImages(t *testing.T) {
+	r := []types.Recipe{
+		{
+			ID:           "1",
+			Name:         "mysql-open-source-integration",
+			ProcessMatch: []string{"mysqld"},
+			ImageMatch:   []string{`(^|/)mysql(:|$)`},
+		},
+		{
+			ID:         "2",
+			Name:       "redis-open-source-integration",
+			ImageMatch: []string{`(^|/)redis(:|$)`},
+		},
+	}
+
+	m := types.DiscoveryManifest{
+		Containers: []types.Container{
+			{Name: "db", Image: "mysql:8.0", Runtime: "docker"},
+			{Name: "cache", Image: "docker.io/library/redis", Runtime: "podman"},
+			{Name: "web", Image: "nginx:latest", Runtime: "docker"},
+		},
+	}
+
+	mockRecipeFetcher := recipes.NewMockRecipeFetcher()
+	mockRecipeFetcher.FetchRecipesVal = r
+	f := NewRegexProcessFilterer(mockRecipeFetcher)
+	filtered, err := f.filter(context.Background(), []types.GenericProcess{}, m)
+
+	require.NoError(t, err)
+	require.Equal(t, 2, len(filtered))
+	require.Equal(t, "docker container db (mysql:8.0)", filtered[0].Command)
+	require.Equal(t, "mysqld", filtered[0].MatchingPattern)
+	require.Equal(t, "podman container cache (docker.io/library/redis)", filtered[1].Command)
+	require.Equal(t, `(^|/)redis(:|$)`, filtered[1].MatchingPattern)
+}
+
+func TestFilter_MatchesListeningPorts(t *testing.T) {
+	r := []types.Recipe{
+		{
+			ID:           "1",
+			Name:         "redis-open-source-integration",
+			ProcessMatch: []string{"redis-server"},
+			PortMatch:    []int{6379},
+		},
+		{
+			ID:        "2",
+			Name:      "postgres-open-source-integration",
+			PortMatch: []int{5432},
+		},
+	}
+
+	processes := []types.GenericProcess{
+		mockProcess{
+			name:    "java",
+			cmdline: "java -jar cache.jar",
+			ports:   []types.ListeningPort{{Port: 6379, Protocol: "tcp"}},
+		},
+		mockProcess{
+			name:    "python",
+			cmdline: "python main.py",
+			ports:   []types.ListeningPort{{Port: 8080, Protocol: "tcp"}},
+		},
+		mockProcess{
+			name:    "redis-server",
+			cmdline: "redis-server *:6380",
+			ports:   []types.ListeningPort{{Port: 6380, Protocol: "tcp"}},
+		},
+		mockProcess{
+			name:    "java",
+			cmdline: "java -jar db.jar",
+			ports:   []types.ListeningPort{{Port: 5432, Protocol: "tcp"}},
+		},
+	}
+
+	mockRecipeFetcher := recipes.NewMockRecipeFetcher()
+	mockRecipeFetcher.FetchRecipesVal = r
+	f := NewRegexProcessFilterer(mockRecipeFetcher)
+	filtered, err := f.filter(context.Background(), processes, types.DiscoveryManifest{})
+
+	require.NoError(t, err)
+	require.Equal(t, 2, len(filtered))
+	require.Equal(t, "java -jar cache.jar", filtered[0].Command)
+	require.Equal(t, "redis-server", filtered[0].MatchingPattern)
+	require.Equal(t, []types.ListeningPort{{Port: 6379, Protocol: "tcp"}}, filtered[0].Ports)
+	require.Equal(t, "redis-server *:6380", filtered[1].Command)
+	require.Equal(t, []types.ListeningPort{{Port: 6380, Protocol: "tcp"}}, filtered[1].Ports)
+}
+
+func TestFilter_PortMatchRequiresProcessMatch(t *testing.T) {
+	r := []types.Recipe{
+		{
+			ID:        "1",
+			Name:      "postgres-open-source-integration",
+			PortMatch: []int{5432},
+		},
+	}
+
+	processes := []types.GenericProcess{
+		mockProcess{
+			name:    "java",
+			cmdline: "java -jar db.jar",
+			ports:   []types.ListeningPort{{Port: 5432, Protocol: "tcp"}},
+		},
+	}
+
+	mockRecipeFetcher := recipes.NewMockRecipeFetcher()
+	mockRecipeFetcher.FetchRecipesVal = r
+	f := NewRegexProcessFilterer(mockRecipeFetcher)
+	filtered, err := f.filter(context.Background(), processes, types.DiscoveryManifest{})
+
+	require.NoError(t, err)
+	require.Empty(t, filtered)
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 
@@ -17,14 +19,32 @@ import (
 	log "github.com/sirupsen/logrus"
 	"gopkg.in/yaml.v2"
 
+	"github.com/newrelic/newrelic-cli/internal/config"
 	"github.com/newrelic/newrelic-cli/internal/credentials"
 	"github.com/newrelic/newrelic-cli/internal/install/recipes"
 	"github.com/newrelic/newrelic-cli/internal/install/types"
+	"github.com/newrelic/newrelic-cli/internal/utils"
 )
 
 // GoTaskRecipeExecutor is an implementation of the recipeExecutor interface that
 // uses the go-task module to execute the steps defined in each recipe.
-type GoTaskRecipeExecutor struct{}
+type GoTaskRecipeExecutor struct {
+	// Answers optionally provides values for recipe input vars.
+	Answers *RecipeAnswers
+	// Dir is the directory tasks run in.  Tasks run in the current directory
+	// when it is empty.
+	Dir string
+	// Stdout and Stderr receive the output of tasks, os.Stdout and os.Stderr
+	// when nil.
+	Stdout io.Writer
+	Stderr io.Writer
+	// Proxy is the URL of the proxy recipes configure agents and integrations
+	// to send data through.
+	Proxy string
+	// LogDir is the directory the output of each recipe's tasks is logged to,
+	// in addition to Stdout and Stderr.  Output is not logged when it is empty.
+	LogDir string
+}
 
 // NewGoTaskRecipeExecutor returns a new instance of GoTaskRecipeExecutor.
 func NewGoTaskRecipeExecutor() *GoTaskRecipeExecutor {
@@ -41,6 +61,8 @@ func (re *GoTaskRecipeExecutor) Prepare(ctx context.Context, m types.DiscoveryMa
 	results := []types.RecipeVars{}
 
 	systemInfoResult := varsFromSystemInfo(m)
+	portsResult := varsFromMatchedPorts(m, r)
+	proxyResult := varsFromProxy(re.Proxy)
 
 	profileResult, err := varsFromProfile(licenseKey)
 	if err != nil {
@@ -57,12 +79,14 @@ func (re *GoTaskRecipeExecutor) Prepare(ctx context.Context, m types.DiscoveryMa
 		return types.RecipeVars{}, err
 	}
 
-	inputVarsResult, err := varsFromInput(f.InputVars, assumeYes)
+	inputVarsResult, err := varsFromInput(r.Name, f.InputVars, assumeYes, re.Answers)
 	if err != nil {
 		return types.RecipeVars{}, err
 	}
 
 	results = append(results, systemInfoResult)
+	results = append(results, portsResult)
+	results = append(results, proxyResult)
 	results = append(results, profileResult)
 	results = append(results, recipeResult)
 	results = append(results, inputVarsResult)
@@ -84,47 +108,84 @@ func (re *GoTaskRecipeExecutor) Execute(ctx context.Context, m types.DiscoveryMa
 		return fmt.Errorf("could not convert recipe to recipe file: %s", err)
 	}
 
-	out, err := yaml.Marshal(f.Install)
+	return re.runTasks(ctx, r.Name, "install", f.Install, recipeVars)
+}
+
+// Uninstall runs the uninstall section of a recipe through the same go-task
+// pipeline used for installation.
+func (re *GoTaskRecipeExecutor) Uninstall(ctx context.Context, m types.DiscoveryManifest, r types.Recipe, recipeVars types.RecipeVars) error {
+	log.Debugf("uninstalling recipe %s", r.Name)
+
+	f, err := recipes.RecipeToRecipeFile(r)
 	if err != nil {
-		return fmt.Errorf("could not marshal recipe file: %s", err)
+		return fmt.Errorf("could not convert recipe to recipe file: %s", err)
 	}
 
-	// Create a temporary task file.
-	file, err := ioutil.TempFile("", r.Name)
-	defer os.Remove(file.Name())
-	if err != nil {
-		return err
+	if !f.HasUninstall() {
+		return fmt.Errorf("recipe %s does not define an uninstall section", r.Name)
 	}
 
-	_, err = file.Write(out)
+	return re.runTasks(ctx, r.Name, "uninstall", f.Uninstall, recipeVars)
+}
+
+// Rollback runs the rollback section of a recipe, which undoes the changes of
+// an install that failed to execute or validate.
+func (re *GoTaskRecipeExecutor) Rollback(ctx context.Context, m types.DiscoveryManifest, r types.Recipe, recipeVars types.RecipeVars) error {
+	log.Debugf("rolling back recipe %s", r.Name)
+
+	f, err := recipes.RecipeToRecipeFile(r)
 	if err != nil {
-		return err
+		return fmt.Errorf("could not convert recipe to recipe file: %s", err)
 	}
 
-	e := task.Executor{
-		Entrypoint: file.Name(),
-		Stderr:     os.Stderr,
-		Stdout:     os.Stdout,
-		Stdin:      os.Stdin,
+	if !f.HasRollback() {
+		return fmt.Errorf("recipe %s does not define a rollback section", r.Name)
 	}
 
-	if err = e.Setup(); err != nil {
-		return fmt.Errorf("could not set up task executor: %s", err)
+	return re.runTasks(ctx, r.Name, "rollback", f.Rollback, recipeVars)
+}
+
+// runTasks runs a task section of a recipe.  The output of the tasks is logged
+// to the recipe's log file, and the last lines of it are returned in a
+// TaskError when the tasks fail.
+func (re *GoTaskRecipeExecutor) runTasks(ctx context.Context, name string, action string, tasks map[string]interface{}, recipeVars types.RecipeVars) error {
+	stdout, stderr := re.Stdout, re.Stderr
+	if stdout == nil {
+		stdout = os.Stdout
 	}
 
-	var tf taskfile.Taskfile
-	err = yaml.Unmarshal(out, &tf)
-	if err != nil {
-		return fmt.Errorf("could not unmarshal taskfile: %s", err)
+	if stderr == nil {
+		stderr = os.Stderr
 	}
 
-	calls, globals := taskargs.ParseV3()
-	e.Taskfile.Vars.Merge(globals)
+	tail := newTailWriter(RecipeLogTailLines)
+	outputs := []io.Writer{tail}
 
-	for k, val := range recipeVars {
-		e.Taskfile.Vars.Set(k, taskfile.Var{Static: val})
+	logFile := ""
+	if re.LogDir != "" {
+		if file := openRecipeLog(re.LogDir, name, action); file != nil {
+			defer file.Close()
+			logFile = file.Name()
+
+			// Tasks can print the secrets they are given, so the log is
+			// redacted like the CLI's own log.
+			w := config.NewRedactingWriter(file)
+			defer w.Flush()
+			outputs = append(outputs, w)
+		}
 	}
 
+	stdout = io.MultiWriter(append([]io.Writer{stdout}, outputs...)...)
+	stderr = io.MultiWriter(append([]io.Writer{stderr}, outputs...)...)
+
+	e, cleanup, err := newTaskExecutor(name, re.Dir, tasks, recipeVars, stdout, stderr)
+	defer cleanup()
+	if err != nil {
+		return err
+	}
+
+	calls, _ := taskargs.ParseV3()
+
 	if err := e.Run(ctx, calls...); err != nil {
 		log.WithFields(log.Fields{
 			"err": err,
@@ -141,12 +202,74 @@ func (re *GoTaskRecipeExecutor) Execute(ctx context.Context, m types.DiscoveryMa
 			return types.ErrInterrupt
 		}
 
-		return err
+		return &TaskError{
+			Err:     err,
+			Output:  config.Redact(tail.String()),
+			LogFile: logFile,
+		}
 	}
 
 	return nil
 }
 
+// newTaskExecutor writes a task section of a recipe to a temporary task file
+// and returns a go-task executor for it with the recipe vars applied.  When dir
+// is set, the task file is written to and tasks run in that directory.  The
+// returned cleanup func removes the temporary file and is always safe to call.
+func newTaskExecutor(name string, dir string, tasks map[string]interface{}, recipeVars types.RecipeVars, stdout io.Writer, stderr io.Writer) (*task.Executor, func(), error) {
+	cleanup := func() {}
+
+	out, err := yaml.Marshal(tasks)
+	if err != nil {
+		return nil, cleanup, fmt.Errorf("could not marshal recipe file: %s", err)
+	}
+
+	// Create a temporary task file.
+	file, err := ioutil.TempFile(dir, name)
+	if err != nil {
+		return nil, cleanup, err
+	}
+	cleanup = func() { os.Remove(file.Name()) }
+
+	_, err = file.Write(out)
+	if err != nil {
+		return nil, cleanup, err
+	}
+
+	// go-task reads the entrypoint relative to its directory.
+	entrypoint := file.Name()
+	if dir != "" {
+		entrypoint = filepath.Base(entrypoint)
+	}
+
+	e := task.Executor{
+		Entrypoint: entrypoint,
+		Dir:        dir,
+		Stderr:     stderr,
+		Stdout:     stdout,
+		Stdin:      os.Stdin,
+	}
+
+	if err = e.Setup(); err != nil {
+		return nil, cleanup, fmt.Errorf("could not set up task executor: %s", err)
+	}
+
+	var tf taskfile.Taskfile
+	err = yaml.Unmarshal(out, &tf)
+	if err != nil {
+		return nil, cleanup, fmt.Errorf("could not unmarshal taskfile: %s", err)
+	}
+
+	_, globals := taskargs.ParseV3()
+	e.Taskfile.Vars.Merge(globals)
+
+	for k, val := range recipeVars {
+		e.Taskfile.Vars.Set(k, taskfile.Var{Static: val})
+	}
+
+	return &e, cleanup, nil
+}
+
 func varsFromProfile(licenseKey string) (types.RecipeVars, error) {
 	defaultProfile := credentials.DefaultProfile()
 	if licenseKey == "" {
@@ -156,13 +279,36 @@ func varsFromProfile(licenseKey string) (types.RecipeVars, error) {
 	vars := make(types.RecipeVars)
 
 	vars["NEW_RELIC_LICENSE_KEY"] = licenseKey
-	vars["NEW_RELIC_ACCOUNT_ID"] = strconv.Itoa(defaultProfile.AccountID)
-	vars["NEW_RELIC_API_KEY"] = defaultProfile.APIKey
-	vars["NEW_RELIC_REGION"] = defaultProfile.Region
+
+	if defaultProfile != nil {
+		vars["NEW_RELIC_ACCOUNT_ID"] = strconv.Itoa(defaultProfile.AccountID)
+		vars["NEW_RELIC_API_KEY"] = defaultProfile.APIKey
+		vars["NEW_RELIC_REGION"] = defaultProfile.Region
+	}
 
 	return vars, nil
 }
 
+// varsFromProxy sets NEW_RELIC_PROXY, along with the HTTPS_PROXY, HTTP_PROXY
+// and NO_PROXY conventions, so that recipes configure the agents and
+// integrations they install to use the same proxy as the CLI.
+func varsFromProxy(proxy string) types.RecipeVars {
+	vars := make(types.RecipeVars)
+
+	if proxy == "" {
+		return vars
+	}
+
+	config.RegisterProxySecrets(proxy)
+
+	vars["NEW_RELIC_PROXY"] = proxy
+	vars["HTTPS_PROXY"] = proxy
+	vars["HTTP_PROXY"] = proxy
+	vars["NO_PROXY"] = utils.NoProxy()
+
+	return vars
+}
+
 func varsFromSystemInfo(m types.DiscoveryManifest) types.RecipeVars {
 	vars := make(types.RecipeVars)
 
@@ -174,6 +320,30 @@ func varsFromSystemInfo(m types.DiscoveryManifest) types.RecipeVars {
 	vars["KERNEL_ARCH"] = m.KernelArch
 	vars["KERNEL_VERSION"] = m.KernelVersion
 
+	if m.Kubernetes != nil {
+		vars["KUBERNETES_CLUSTER_NAME"] = m.Kubernetes.Name
+		vars["KUBERNETES_VERSION"] = m.Kubernetes.Version
+	}
+
+	return vars
+}
+
+// varsFromMatchedPorts passes the ports a recipe's processes listen on to the
+// recipe, so that integration configs can be pre-filled.  MATCHED_PORT is the
+// first port and MATCHED_PORTS is a comma-separated list of all of them.
+func varsFromMatchedPorts(m types.DiscoveryManifest, r types.Recipe) types.RecipeVars {
+	vars := make(types.RecipeVars)
+
+	ports := []string{}
+	for _, p := range m.MatchedPorts(r) {
+		ports = append(ports, strconv.Itoa(p))
+	}
+
+	if len(ports) > 0 {
+		vars["MATCHED_PORT"] = ports[0]
+		vars["MATCHED_PORTS"] = strings.Join(ports, ",")
+	}
+
 	return vars
 }
 
@@ -192,21 +362,35 @@ func varsFromRecipe(r types.Recipe) (types.RecipeVars, error) {
 	return vars, nil
 }
 
-func varsFromInput(inputVars []recipes.VariableConfig, assumeYes bool) (types.RecipeVars, error) {
+// varsFromInput resolves the input vars of a recipe.  Values from an answers
+// file are used first, followed by environment variables.  When an answers file
+// is provided, or assumeYes is set, defaults are used instead of prompting.
+func varsFromInput(recipeName string, inputVars []recipes.VariableConfig, assumeYes bool, answers *RecipeAnswers) (types.RecipeVars, error) {
 	vars := make(types.RecipeVars)
 
 	vars["NEW_RELIC_ASSUME_YES"] = fmt.Sprintf("%t", assumeYes)
 
 	for _, envConfig := range inputVars {
-		var err error
+		answer, ok, err := answers.Lookup(recipeName, envConfig.Name)
+		if err != nil {
+			return types.RecipeVars{}, err
+		}
+
+		if ok {
+			vars[envConfig.Name] = answer
+			registerSecretVar(envConfig, answer)
+			continue
+		}
+
 		envValue := os.Getenv(envConfig.Name)
 
 		if envValue != "" {
 			vars[envConfig.Name] = envValue
+			registerSecretVar(envConfig, envValue)
 			continue
 		}
 
-		if assumeYes {
+		if assumeYes || answers != nil {
 			if envConfig.Default == "" {
 				return types.RecipeVars{}, fmt.Errorf("no default value for environment variable %s and none provided", envConfig.Name)
 			}
@@ -233,11 +417,19 @@ func varsFromInput(inputVars []recipes.VariableConfig, assumeYes bool) (types.Re
 		}
 
 		vars[envConfig.Name] = envValue
+		registerSecretVar(envConfig, envValue)
 	}
 
 	return vars, nil
 }
 
+// registerSecretVar keeps the value of a secret input var out of log output.
+func registerSecretVar(envConfig recipes.VariableConfig, value string) {
+	if envConfig.Secret || config.IsSecretName(envConfig.Name) {
+		config.RegisterSecrets(value)
+	}
+}
+
 func varFromPrompt(envConfig recipes.VariableConfig) (string, error) {
 	msg := fmt.Sprintf("value for %s required", envConfig.Name)
 
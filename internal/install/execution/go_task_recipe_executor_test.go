@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -18,6 +19,7 @@ import (
 	"github.com/newrelic/newrelic-cli/internal/credentials"
 	"github.com/newrelic/newrelic-cli/internal/install/recipes"
 	"github.com/newrelic/newrelic-cli/internal/install/types"
+	"github.com/newrelic/newrelic-cli/internal/utils"
 )
 
 func TestExecute_SystemVariableInterpolation(t *testing.T) {
@@ -106,3 +108,139 @@ func TestExecute_SystemVariableInterpolation(t *testing.T) {
 	require.Equal(t, m.KernelArch, actual.KernelArch)
 	require.Equal(t, m.KernelVersion, actual.KernelVersion)
 }
+
+func TestPrepare_ProxyVariables(t *testing.T) {
+	credentials.SetDefaultProfile(credentials.Profile{})
+
+	r := types.Recipe{
+		File: "name: test\n",
+	}
+
+	e := NewGoTaskRecipeExecutor()
+	v, err := e.Prepare(context.Background(), types.DiscoveryManifest{}, r, false, "testLicenseKey")
+	require.NoError(t, err)
+	require.NotContains(t, v, "NEW_RELIC_PROXY")
+
+	e.Proxy = "http://proxy.example.com:3128"
+	v, err = e.Prepare(context.Background(), types.DiscoveryManifest{}, r, false, "testLicenseKey")
+	require.NoError(t, err)
+	require.Equal(t, "http://proxy.example.com:3128", v["NEW_RELIC_PROXY"])
+	require.Equal(t, "http://proxy.example.com:3128", v["HTTPS_PROXY"])
+	require.Equal(t, "http://proxy.example.com:3128", v["HTTP_PROXY"])
+	require.Equal(t, utils.NoProxy(), v["NO_PROXY"])
+}
+
+func TestVarsFromMatchedPorts(t *testing.T) {
+	m := types.DiscoveryManifest{
+		Processes: []types.MatchedProcess{
+			{
+				Command:         "redis-server *:6380",
+				MatchingPattern: "redis-server",
+				Ports:           []types.ListeningPort{{Port: 6380, Protocol: "tcp"}},
+			},
+		},
+	}
+
+	r := types.Recipe{
+		Name:         "redis-open-source-integration",
+		ProcessMatch: []string{"redis-server"},
+		PortMatch:    []int{6379},
+	}
+
+	v := varsFromMatchedPorts(m, r)
+	require.Equal(t, "6380", v["MATCHED_PORT"])
+	require.Equal(t, "6380", v["MATCHED_PORTS"])
+
+	require.Empty(t, varsFromMatchedPorts(m, types.Recipe{ProcessMatch: []string{"mysqld"}}))
+}
+
+func TestRollback_RunsRollbackTasks(t *testing.T) {
+	credentials.SetDefaultProfile(credentials.Profile{})
+
+	marker := filepath.Join(t.TempDir(), "rolled-back")
+
+	f := recipes.RecipeFile{
+		Name: "test",
+		Rollback: map[string]interface{}{
+			"version": "3",
+			"tasks": taskfile.Tasks{
+				"default": &taskfile.Task{
+					Cmds: []*taskfile.Cmd{
+						{Cmd: fmt.Sprintf("touch %s", marker)},
+					},
+					Silent: true,
+				},
+			},
+		},
+	}
+
+	fs, err := yaml.Marshal(f)
+	require.NoError(t, err)
+
+	r := types.Recipe{
+		Name: "test",
+		File: string(fs),
+	}
+
+	e := NewGoTaskRecipeExecutor()
+	v, err := e.Prepare(context.Background(), types.DiscoveryManifest{}, r, false, "testLicenseKey")
+	require.NoError(t, err)
+
+	err = e.Rollback(context.Background(), types.DiscoveryManifest{}, r, v)
+	require.NoError(t, err)
+	require.FileExists(t, marker)
+
+	err = e.Rollback(context.Background(), types.DiscoveryManifest{}, types.Recipe{Name: "test", File: "name: test\n"}, v)
+	require.Error(t, err)
+}
+
+func TestExecute_LogsTaskOutput(t *testing.T) {
+	credentials.SetDefaultProfile(credentials.Profile{})
+
+	f := recipes.RecipeFile{
+		Name: "test",
+		Install: map[string]interface{}{
+			"version": "3",
+			"tasks": taskfile.Tasks{
+				"default": &taskfile.Task{
+					Cmds: []*taskfile.Cmd{
+						{Cmd: "echo installing"},
+						{Cmd: "echo DB_PASSWORD=hunter2"},
+						{Cmd: "echo could not write config >&2 && exit 1"},
+					},
+					Silent: true,
+				},
+			},
+		},
+	}
+
+	fs, err := yaml.Marshal(f)
+	require.NoError(t, err)
+
+	r := types.Recipe{
+		Name: "test",
+		File: string(fs),
+	}
+
+	e := NewGoTaskRecipeExecutor()
+	e.LogDir = t.TempDir()
+	e.Stdout = ioutil.Discard
+	e.Stderr = ioutil.Discard
+
+	v, err := e.Prepare(context.Background(), types.DiscoveryManifest{}, r, false, "testLicenseKey")
+	require.NoError(t, err)
+
+	err = e.Execute(context.Background(), types.DiscoveryManifest{}, r, v)
+	require.Error(t, err)
+
+	te, ok := err.(*TaskError)
+	require.True(t, ok)
+	require.Equal(t, "installing\nDB_PASSWORD=[REDACTED]\ncould not write config", te.Output)
+	require.Equal(t, RecipeLogFilePath(e.LogDir, "test"), te.LogFile)
+
+	b, err := ioutil.ReadFile(te.LogFile)
+	require.NoError(t, err)
+	require.Contains(t, string(b), "==> install test at ")
+	require.Contains(t, string(b), "installing\nDB_PASSWORD=[REDACTED]\ncould not write config\n")
+	require.NotContains(t, string(b), "hunter2")
+}
@@ -0,0 +1,125 @@
+package execution
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/newrelic/newrelic-cli/internal/config"
+)
+
+const (
+	// InstallHistoryVersion is the version of the install history file format.
+	InstallHistoryVersion = 1
+
+	installHistoryDirName = "installs"
+	installHistoryFileExt = ".json"
+)
+
+// InstallHistoryRecord is the versioned envelope an InstallStatus is persisted
+// in on the local filesystem.
+type InstallHistoryRecord struct {
+	Version   int            `json:"version"`
+	StartedAt int64          `json:"startedAt"`
+	UpdatedAt int64          `json:"updatedAt"`
+	Status    *InstallStatus `json:"status"`
+}
+
+// DefaultInstallHistoryDirectory returns the directory install history is
+// kept in by default.
+func DefaultInstallHistoryDirectory() string {
+	return filepath.Join(config.DefaultConfigDirectory, installHistoryDirName)
+}
+
+// installHistoryFilePath returns the path of the history file for an install.
+// The document ID comes from the command line when an install is resumed or
+// shown, so anything that would resolve outside of dir is rejected.
+func installHistoryFilePath(dir string, documentID string) (string, error) {
+	if documentID == "" || documentID == "." || documentID == ".." || strings.ContainsAny(documentID, `/\`) {
+		return "", fmt.Errorf("invalid install document ID %q", documentID)
+	}
+
+	return filepath.Join(dir, documentID+installHistoryFileExt), nil
+}
+
+// LoadInstallHistory reads every install history record found in dir, most
+// recent first.  Files that cannot be read are logged and skipped.
+func LoadInstallHistory(dir string) ([]InstallHistoryRecord, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []InstallHistoryRecord{}, nil
+		}
+
+		return nil, err
+	}
+
+	records := []InstallHistoryRecord{}
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), installHistoryFileExt) {
+			continue
+		}
+
+		record, err := readInstallHistoryRecord(filepath.Join(dir, f.Name()))
+		if err != nil {
+			log.Warnf("could not read install history file %s: %s", f.Name(), err)
+			continue
+		}
+
+		records = append(records, *record)
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].StartedAt > records[j].StartedAt
+	})
+
+	return records, nil
+}
+
+// LoadInstallHistoryRecord reads the install history record for a single
+// install from dir.
+func LoadInstallHistoryRecord(dir string, documentID string) (*InstallHistoryRecord, error) {
+	path, err := installHistoryFilePath(dir, documentID)
+	if err != nil {
+		return nil, err
+	}
+
+	record, err := readInstallHistoryRecord(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("no install history found for %s", documentID)
+		}
+
+		return nil, err
+	}
+
+	return record, nil
+}
+
+func readInstallHistoryRecord(path string) (*InstallHistoryRecord, error) {
+	out, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var record InstallHistoryRecord
+	if err = json.Unmarshal(out, &record); err != nil {
+		return nil, err
+	}
+
+	if record.Version > InstallHistoryVersion {
+		return nil, fmt.Errorf("unsupported install history version %d", record.Version)
+	}
+
+	if record.Status == nil {
+		return nil, fmt.Errorf("install history file %s has no status", path)
+	}
+
+	return &record, nil
+}
@@ -14,26 +14,29 @@ import (
 
 // nolint: maligned
 type InstallStatus struct {
-	Complete            bool                    `json:"complete"`
-	DiscoveryManifest   types.DiscoveryManifest `json:"discoveryManifest"`
-	EntityGUIDs         []string                `json:"entityGuids"`
-	Error               StatusError             `json:"error"`
-	LogFilePath         string                  `json:"logFilePath"`
-	Statuses            []*RecipeStatus         `json:"recipes"`
-	Timestamp           int64                   `json:"timestamp"`
-	CLIVersion          string                  `json:"cliVersion"`
-	HasInstalledRecipes bool                    `json:"hasInstalledRecipes"`
-	HasCanceledRecipes  bool                    `json:"hasCanceledRecipes"`
-	HasSkippedRecipes   bool                    `json:"hasSkippedRecipes"`
-	HasFailedRecipes    bool                    `json:"hasFailedRecipes"`
-	RecipesSkipped      []*RecipeStatus         `json:"recipesSkipped"`
-	RecipesCanceled     []*RecipeStatus         `json:"recipesCanceled"`
-	RecipesFailed       []*RecipeStatus         `json:"recipesFailed"`
-	RecipesInstalled    []*RecipeStatus         `json:"recipesInstalled"`
-	DocumentID          string
-	targetedInstall     bool
-	statusSubscriber    []StatusSubscriber
-	successLinkConfig   types.OpenInstallationSuccessLinkConfig
+	Complete              bool                    `json:"complete"`
+	DiscoveryManifest     types.DiscoveryManifest `json:"discoveryManifest"`
+	EntityGUIDs           []string                `json:"entityGuids"`
+	Error                 StatusError             `json:"error"`
+	LogFilePath           string                  `json:"logFilePath"`
+	Statuses              []*RecipeStatus         `json:"recipes"`
+	Timestamp             int64                   `json:"timestamp"`
+	CLIVersion            string                  `json:"cliVersion"`
+	HasInstalledRecipes   bool                    `json:"hasInstalledRecipes"`
+	HasCanceledRecipes    bool                    `json:"hasCanceledRecipes"`
+	HasSkippedRecipes     bool                    `json:"hasSkippedRecipes"`
+	HasFailedRecipes      bool                    `json:"hasFailedRecipes"`
+	HasUninstalledRecipes bool                    `json:"hasUninstalledRecipes"`
+	RecipesSkipped        []*RecipeStatus         `json:"recipesSkipped"`
+	RecipesCanceled       []*RecipeStatus         `json:"recipesCanceled"`
+	RecipesFailed         []*RecipeStatus         `json:"recipesFailed"`
+	RecipesInstalled      []*RecipeStatus         `json:"recipesInstalled"`
+	RecipesUninstalled    []*RecipeStatus         `json:"recipesUninstalled"`
+	DocumentID            string
+	TargetedInstall       bool `json:"targetedInstall"`
+	uninstall             bool
+	statusSubscriber      []StatusSubscriber
+	successLinkConfig     types.OpenInstallationSuccessLinkConfig
 }
 
 type RecipeStatus struct {
@@ -44,6 +47,24 @@ type RecipeStatus struct {
 	EntityGUID  string           `json:"entityGuid,omitempty"`
 	// ValidationDurationMilliseconds is duration in Milliseconds that a recipe took to validate data was flowing.
 	ValidationDurationMilliseconds int64 `json:"validationDurationMilliseconds,omitempty"`
+	// Rollback is the outcome of the rollback tasks run after the recipe failed.
+	Rollback *RollbackStatus `json:"rollback,omitempty"`
+}
+
+// RollbackStatus is the outcome of a recipe's rollback tasks.
+type RollbackStatus struct {
+	Status RollbackStatusType `json:"status"`
+	Error  StatusError        `json:"error"`
+}
+
+type RollbackStatusType string
+
+var RollbackStatusTypes = struct {
+	SUCCEEDED RollbackStatusType
+	FAILED    RollbackStatusType
+}{
+	SUCCEEDED: "SUCCEEDED",
+	FAILED:    "FAILED",
 }
 
 type RecipeStatusType string
@@ -56,6 +77,7 @@ var RecipeStatusTypes = struct {
 	INSTALLED   RecipeStatusType
 	SKIPPED     RecipeStatusType
 	RECOMMENDED RecipeStatusType
+	UNINSTALLED RecipeStatusType
 }{
 	AVAILABLE:   "AVAILABLE",
 	CANCELED:    "CANCELED",
@@ -64,6 +86,7 @@ var RecipeStatusTypes = struct {
 	INSTALLED:   "INSTALLED",
 	SKIPPED:     "SKIPPED",
 	RECOMMENDED: "RECOMMENDED",
+	UNINSTALLED: "UNINSTALLED",
 }
 
 type StatusError struct {
@@ -175,6 +198,31 @@ func (s *InstallStatus) RecipeSkipped(event RecipeStatusEvent) {
 	}
 }
 
+// RecipeUninstalled records that a recipe's uninstall tasks completed
+// successfully.
+func (s *InstallStatus) RecipeUninstalled(event RecipeStatusEvent) {
+	s.withRecipeEvent(event, RecipeStatusTypes.UNINSTALLED)
+
+	for _, r := range s.statusSubscriber {
+		if err := r.RecipeUninstalled(s, event); err != nil {
+			log.Errorf("Error writing recipe status for recipe %s: %s", event.Recipe.Name, err)
+		}
+	}
+}
+
+// RecipeRolledBack records the outcome of the rollback tasks of a failed
+// recipe.  The recipe remains failed, and a message in the event means the
+// rollback failed as well.
+func (s *InstallStatus) RecipeRolledBack(event RecipeStatusEvent) {
+	s.withRollbackEvent(event)
+
+	for _, r := range s.statusSubscriber {
+		if err := r.RecipeRolledBack(s, event); err != nil {
+			log.Errorf("Error writing recipe status for recipe %s: %s", event.Recipe.Name, err)
+		}
+	}
+}
+
 func (s *InstallStatus) InstallComplete(err error) {
 	s.completed(err)
 
@@ -217,12 +265,66 @@ func (s *InstallStatus) hasAnyRecipeStatus(status RecipeStatusType) bool {
 	return false
 }
 
+// ResumeFrom continues the status of a previous install.  The document ID and
+// entity GUIDs are kept, along with the statuses of recipes that will not be
+// run again, so that reporters see a single continuous install.
+func (s *InstallStatus) ResumeFrom(prior *InstallStatus) {
+	s.DocumentID = prior.DocumentID
+	s.EntityGUIDs = append([]string{}, prior.EntityGUIDs...)
+
+	for _, ps := range prior.Statuses {
+		if ps.Status == RecipeStatusTypes.FAILED || ps.Status == RecipeStatusTypes.CANCELED {
+			continue
+		}
+
+		st := *ps
+		s.Statuses = append(s.Statuses, &st)
+	}
+}
+
+// ResumableRecipeNames returns the names of the recipes that failed or were
+// canceled.
+func (s *InstallStatus) ResumableRecipeNames() []string {
+	names := []string{}
+
+	for _, st := range s.Statuses {
+		if st.Status == RecipeStatusTypes.FAILED || st.Status == RecipeStatusTypes.CANCELED {
+			names = append(names, st.Name)
+		}
+	}
+
+	return names
+}
+
+// IsRecipeInstalled returns true when the named recipe has been installed as
+// part of this install.
+func (s *InstallStatus) IsRecipeInstalled(name string) bool {
+	for _, st := range s.Statuses {
+		if st.Name == name && st.Status == RecipeStatusTypes.INSTALLED {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SetTargetedInstall marks the status as tracking a targeted install.  It is
+// recorded so that resuming the install takes the same path.
 func (s *InstallStatus) SetTargetedInstall() {
-	s.targetedInstall = true
+	s.TargetedInstall = true
 }
 
 func (s *InstallStatus) IsTargetedInstall() bool {
-	return s.targetedInstall
+	return s.TargetedInstall
+}
+
+// SetUninstall marks the status as tracking an uninstall rather than an install.
+func (s *InstallStatus) SetUninstall() {
+	s.uninstall = true
+}
+
+func (s *InstallStatus) IsUninstall() bool {
+	return s.uninstall
 }
 
 func (s *InstallStatus) HostEntityGUID() string {
@@ -288,6 +390,7 @@ func (s *InstallStatus) withRecipeEvent(e RecipeStatusEvent, rs RecipeStatusType
 
 	statusError := StatusError{
 		Message: e.Msg,
+		Details: e.Details,
 	}
 
 	s.Error = statusError
@@ -304,6 +407,7 @@ func (s *InstallStatus) withRecipeEvent(e RecipeStatusEvent, rs RecipeStatusType
 
 	if found != nil {
 		found.Status = rs
+		found.Error = statusError
 
 		if e.EntityGUID != "" {
 			found.EntityGUID = e.EntityGUID
@@ -334,6 +438,40 @@ func (s *InstallStatus) withRecipeEvent(e RecipeStatusEvent, rs RecipeStatusType
 	s.Timestamp = utils.GetTimestamp()
 }
 
+func (s *InstallStatus) withRollbackEvent(e RecipeStatusEvent) {
+	rollback := &RollbackStatus{
+		Status: RollbackStatusTypes.SUCCEEDED,
+	}
+
+	if e.Msg != "" {
+		rollback.Status = RollbackStatusTypes.FAILED
+		rollback.Error = StatusError{
+			Message: e.Msg,
+			Details: e.Details,
+		}
+	}
+
+	log.WithFields(log.Fields{
+		"recipe_name": e.Recipe.Name,
+		"status":      rollback.Status,
+		"error":       rollback.Error.Message,
+	}).Debug("recipe rollback")
+
+	found := s.getStatus(e.Recipe)
+	if found == nil {
+		found = &RecipeStatus{
+			Name:        e.Recipe.Name,
+			DisplayName: e.Recipe.DisplayName,
+			Status:      RecipeStatusTypes.FAILED,
+		}
+
+		s.Statuses = append(s.Statuses, found)
+	}
+
+	found.Rollback = rollback
+	s.Timestamp = utils.GetTimestamp()
+}
+
 func (s *InstallStatus) completed(err error) {
 	s.Complete = true
 	s.Timestamp = utils.GetTimestamp()
@@ -418,12 +556,19 @@ func (s *InstallStatus) updateFinalInstallationStatuses(installCanceled bool) {
 			s.RecipesFailed = append(s.RecipesFailed, ss)
 			s.HasFailedRecipes = true
 		}
+
+		// Uninstalled
+		if ss.Status == RecipeStatusTypes.UNINSTALLED {
+			s.RecipesUninstalled = append(s.RecipesUninstalled, ss)
+			s.HasUninstalledRecipes = true
+		}
 	}
 
 	log.WithFields(log.Fields{
-		"hasInstalledRecipes": s.HasInstalledRecipes,
-		"hasSkippedRecipes":   s.HasSkippedRecipes,
-		"hasCanceledRecipes":  s.HasCanceledRecipes,
-		"hasFailedRecipes":    s.HasFailedRecipes,
+		"hasInstalledRecipes":   s.HasInstalledRecipes,
+		"hasSkippedRecipes":     s.HasSkippedRecipes,
+		"hasCanceledRecipes":    s.HasCanceledRecipes,
+		"hasFailedRecipes":      s.HasFailedRecipes,
+		"hasUninstalledRecipes": s.HasUninstalledRecipes,
 	}).Debug("final installation statuses updated")
 }
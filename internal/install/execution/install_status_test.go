@@ -3,6 +3,7 @@
 package execution
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -202,3 +203,90 @@ func TestInstallStatus_multipleRecipeStatuses(t *testing.T) {
 	require.True(t, s.HasCanceledRecipes)
 	require.True(t, s.HasFailedRecipes)
 }
+
+func TestInstallStatus_recipeUninstalled(t *testing.T) {
+	reporter := NewMockStatusReporter()
+	s := NewInstallStatus([]StatusSubscriber{reporter})
+	r := types.Recipe{Name: "testRecipe"}
+
+	s.SetUninstall()
+	s.RecipeAvailable(r)
+	s.RecipeUninstalled(RecipeStatusEvent{Recipe: r})
+	s.InstallComplete(nil)
+
+	require.True(t, s.IsUninstall())
+	require.Equal(t, RecipeStatusTypes.UNINSTALLED, s.Statuses[0].Status)
+	require.True(t, s.HasUninstalledRecipes)
+	require.Len(t, s.RecipesUninstalled, 1)
+	require.False(t, s.HasFailedRecipes)
+	require.Equal(t, 1, reporter.RecipeUninstalledCallCount)
+}
+
+func TestInstallStatus_ResumeFrom(t *testing.T) {
+	prior := NewInstallStatus([]StatusSubscriber{})
+	installed := types.Recipe{Name: "installed"}
+	failed := types.Recipe{Name: "failed"}
+	canceled := types.Recipe{Name: "canceled"}
+
+	prior.RecipesAvailable([]types.Recipe{installed, failed, canceled})
+	prior.RecipeInstalled(RecipeStatusEvent{Recipe: installed, EntityGUID: "installedGUID"})
+	prior.RecipeFailed(RecipeStatusEvent{Recipe: failed})
+	prior.InstallCanceled()
+
+	require.Equal(t, []string{"failed", "canceled"}, prior.ResumableRecipeNames())
+
+	s := NewInstallStatus([]StatusSubscriber{})
+	s.ResumeFrom(prior)
+
+	require.Equal(t, prior.DocumentID, s.DocumentID)
+	require.Equal(t, []string{"installedGUID"}, s.EntityGUIDs)
+	require.Len(t, s.Statuses, 1)
+	require.True(t, s.IsRecipeInstalled("installed"))
+	require.False(t, s.IsRecipeInstalled("failed"))
+	require.Empty(t, s.ResumableRecipeNames())
+}
+
+func TestInstallStatus_TargetedInstallIsRecorded(t *testing.T) {
+	s := NewInstallStatus([]StatusSubscriber{})
+	s.SetTargetedInstall()
+
+	b, err := json.Marshal(s)
+	require.NoError(t, err)
+
+	var recorded InstallStatus
+	require.NoError(t, json.Unmarshal(b, &recorded))
+	require.True(t, recorded.IsTargetedInstall())
+}
+
+func TestInstallStatus_recipeRolledBack(t *testing.T) {
+	reporter := NewMockStatusReporter()
+	s := NewInstallStatus([]StatusSubscriber{reporter})
+	rolledBack := types.Recipe{Name: "rolledBack"}
+	rollbackFailed := types.Recipe{Name: "rollbackFailed"}
+
+	s.RecipesAvailable([]types.Recipe{rolledBack, rollbackFailed})
+	s.RecipeFailed(RecipeStatusEvent{Recipe: rolledBack, Msg: "install failed"})
+	s.RecipeRolledBack(RecipeStatusEvent{Recipe: rolledBack})
+	s.RecipeFailed(RecipeStatusEvent{Recipe: rollbackFailed, Msg: "install failed"})
+	s.RecipeRolledBack(RecipeStatusEvent{Recipe: rollbackFailed, Msg: "rollback failed"})
+	s.InstallComplete(nil)
+
+	require.Equal(t, RecipeStatusTypes.FAILED, s.Statuses[0].Status)
+	require.Equal(t, "install failed", s.Statuses[0].Error.Message)
+	require.Equal(t, RollbackStatusTypes.SUCCEEDED, s.Statuses[0].Rollback.Status)
+	require.Equal(t, RecipeStatusTypes.FAILED, s.Statuses[1].Status)
+	require.Equal(t, RollbackStatusTypes.FAILED, s.Statuses[1].Rollback.Status)
+	require.Equal(t, "rollback failed", s.Statuses[1].Rollback.Error.Message)
+	require.Len(t, s.RecipesFailed, 2)
+	require.Equal(t, 2, reporter.RecipeRolledBackCallCount)
+}
+
+func TestInstallStatus_recipeFailedDetails(t *testing.T) {
+	s := NewInstallStatus([]StatusSubscriber{})
+	r := types.Recipe{Name: "testRecipe"}
+
+	s.RecipeFailed(RecipeStatusEvent{Recipe: r, Msg: "install failed", Details: "could not write config"})
+
+	require.Equal(t, "could not write config", s.Statuses[0].Error.Details)
+	require.Equal(t, "could not write config", s.Error.Details)
+}
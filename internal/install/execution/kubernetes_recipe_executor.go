@@ -0,0 +1,148 @@
+package execution
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"text/template"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/newrelic/newrelic-cli/internal/install/types"
+)
+
+const (
+	// DefaultKubernetesOutputDirectory is where Kubernetes manifests and Helm
+	// values are rendered to by default.
+	DefaultKubernetesOutputDirectory = "newrelic-kubernetes"
+
+	kubernetesManifestFile   = "manifest.yaml"
+	kubernetesHelmValuesFile = "values.yaml"
+)
+
+// KubernetesRecipeExecutor is an implementation of the RecipeExecutor interface
+// that renders the Kubernetes manifest and Helm values of recipes targeting a
+// Kubernetes cluster to files instead of running host tasks.  Recipes without
+// Kubernetes output are executed by an underlying executor.
+type KubernetesRecipeExecutor struct {
+	executor  RecipeExecutor
+	OutputDir string
+	writer    io.Writer
+}
+
+// NewKubernetesRecipeExecutor returns a new instance of
+// KubernetesRecipeExecutor.  Output is rendered to the default directory when
+// none is given.
+func NewKubernetesRecipeExecutor(executor RecipeExecutor, outputDir string) *KubernetesRecipeExecutor {
+	if outputDir == "" {
+		outputDir = DefaultKubernetesOutputDirectory
+	}
+
+	re := KubernetesRecipeExecutor{
+		executor:  executor,
+		OutputDir: outputDir,
+		writer:    os.Stdout,
+	}
+
+	return &re
+}
+
+func (re *KubernetesRecipeExecutor) Prepare(ctx context.Context, m types.DiscoveryManifest, r types.Recipe, assumeYes bool, licenseKey string) (types.RecipeVars, error) {
+	return re.executor.Prepare(ctx, m, r, assumeYes, licenseKey)
+}
+
+func (re *KubernetesRecipeExecutor) Execute(ctx context.Context, m types.DiscoveryManifest, r types.Recipe, recipeVars types.RecipeVars) error {
+	if !r.RendersKubernetesOutput() {
+		return re.executor.Execute(ctx, m, r, recipeVars)
+	}
+
+	log.Debugf("rendering Kubernetes output for recipe %s", r.Name)
+
+	dir := re.recipeOutputDir(r)
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return fmt.Errorf("could not create Kubernetes output directory %s: %s", dir, err)
+	}
+
+	outputs := []struct {
+		file    string
+		content string
+	}{
+		{kubernetesManifestFile, r.Kubernetes.Manifest},
+		{kubernetesHelmValuesFile, r.Kubernetes.HelmValues},
+	}
+
+	for _, o := range outputs {
+		if o.content == "" {
+			continue
+		}
+
+		rendered, err := renderKubernetesOutput(r.Name+"/"+o.file, o.content, recipeVars)
+		if err != nil {
+			return err
+		}
+
+		path := filepath.Join(dir, o.file)
+		if err := ioutil.WriteFile(path, rendered, 0600); err != nil {
+			return fmt.Errorf("could not write %s: %s", path, err)
+		}
+
+		fmt.Fprintf(re.writer, "  Rendered %s for %s to %s\n", o.file, r.DisplayName, path)
+	}
+
+	return nil
+}
+
+// Uninstall does nothing for recipes with Kubernetes output, since their
+// install tasks never ran on the host and the rendered output is applied to the
+// cluster outside of the CLI.  Other recipes are uninstalled by the underlying
+// executor.
+func (re *KubernetesRecipeExecutor) Uninstall(ctx context.Context, m types.DiscoveryManifest, r types.Recipe, recipeVars types.RecipeVars) error {
+	if !r.RendersKubernetesOutput() {
+		return re.executor.Uninstall(ctx, m, r, recipeVars)
+	}
+
+	log.Infof("Skipping uninstall of %s, its Kubernetes output is applied to the cluster outside of the CLI.", r.Name)
+	return nil
+}
+
+// Rollback removes the output rendered for recipes with Kubernetes output,
+// rather than running host tasks for an install that never ran on the host.
+// Other recipes are rolled back by the underlying executor.
+func (re *KubernetesRecipeExecutor) Rollback(ctx context.Context, m types.DiscoveryManifest, r types.Recipe, recipeVars types.RecipeVars) error {
+	if !r.RendersKubernetesOutput() {
+		return re.executor.Rollback(ctx, m, r, recipeVars)
+	}
+
+	dir := re.recipeOutputDir(r)
+	log.Debugf("removing Kubernetes output %s for recipe %s", dir, r.Name)
+
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("could not remove Kubernetes output directory %s: %s", dir, err)
+	}
+
+	return nil
+}
+
+func (re *KubernetesRecipeExecutor) recipeOutputDir(r types.Recipe) string {
+	return filepath.Join(re.OutputDir, r.Name)
+}
+
+// renderKubernetesOutput templates recipe vars into a manifest or Helm values.
+// Referencing a var that is not defined is an error.
+func renderKubernetesOutput(name string, content string, recipeVars types.RecipeVars) ([]byte, error) {
+	t, err := template.New(name).Option("missingkey=error").Parse(content)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse %s: %s", name, err)
+	}
+
+	var b bytes.Buffer
+	if err := t.Execute(&b, recipeVars); err != nil {
+		return nil, fmt.Errorf("could not render %s: %s", name, err)
+	}
+
+	return b.Bytes(), nil
+}
@@ -0,0 +1,106 @@
+// +build unit
+
+package execution
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/newrelic/newrelic-cli/internal/install/types"
+)
+
+var kubernetesTestRecipe = types.Recipe{
+	Name:        "kubernetes-test",
+	DisplayName: "Kubernetes Test",
+	InstallTargets: []types.OpenInstallationRecipeInstallTarget{
+		{Type: types.OpenInstallationTargetTypeTypes.KUBERNETES},
+	},
+	Kubernetes: types.KubernetesOutput{
+		Manifest:   "metadata:\n  name: {{.KUBERNETES_CLUSTER_NAME}}\n",
+		HelmValues: "licenseKey: {{.NEW_RELIC_LICENSE_KEY}}\n",
+	},
+}
+
+func TestKubernetesRecipeExecutor_Execute(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "newrelic-kubernetes")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmp)
+
+	var out bytes.Buffer
+	re := NewKubernetesRecipeExecutor(NewMockFailingRecipeExecutor(), tmp)
+	re.writer = &out
+
+	vars := types.RecipeVars{
+		"KUBERNETES_CLUSTER_NAME": "staging-cluster",
+		"NEW_RELIC_LICENSE_KEY":   "abc123",
+	}
+
+	err = re.Execute(context.Background(), types.DiscoveryManifest{}, kubernetesTestRecipe, vars)
+	require.NoError(t, err)
+
+	manifest, err := ioutil.ReadFile(filepath.Join(tmp, "kubernetes-test", "manifest.yaml"))
+	require.NoError(t, err)
+	require.Equal(t, "metadata:\n  name: staging-cluster\n", string(manifest))
+
+	values, err := ioutil.ReadFile(filepath.Join(tmp, "kubernetes-test", "values.yaml"))
+	require.NoError(t, err)
+	require.Equal(t, "licenseKey: abc123\n", string(values))
+
+	require.Contains(t, out.String(), "manifest.yaml")
+}
+
+func TestKubernetesRecipeExecutor_Execute_MissingVar(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "newrelic-kubernetes")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmp)
+
+	re := NewKubernetesRecipeExecutor(NewMockRecipeExecutor(), tmp)
+	re.writer = ioutil.Discard
+
+	err = re.Execute(context.Background(), types.DiscoveryManifest{}, kubernetesTestRecipe, types.RecipeVars{})
+	require.Error(t, err)
+}
+
+func TestKubernetesRecipeExecutor_Execute_HostRecipe(t *testing.T) {
+	re := NewKubernetesRecipeExecutor(NewMockFailingRecipeExecutor(), "")
+
+	err := re.Execute(context.Background(), types.DiscoveryManifest{}, types.Recipe{Name: "host"}, types.RecipeVars{})
+	require.Error(t, err)
+}
+
+func TestKubernetesRecipeExecutor_Rollback(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "newrelic-kubernetes")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmp)
+
+	re := NewKubernetesRecipeExecutor(NewMockFailingRecipeExecutor(), tmp)
+	re.writer = ioutil.Discard
+
+	vars := types.RecipeVars{"KUBERNETES_CLUSTER_NAME": "test-cluster"}
+	err = re.Execute(context.Background(), types.DiscoveryManifest{}, kubernetesTestRecipe, vars)
+	require.Error(t, err)
+	require.FileExists(t, filepath.Join(tmp, kubernetesTestRecipe.Name, kubernetesManifestFile))
+
+	err = re.Rollback(context.Background(), types.DiscoveryManifest{}, kubernetesTestRecipe, vars)
+	require.NoError(t, err)
+	require.NoDirExists(t, filepath.Join(tmp, kubernetesTestRecipe.Name))
+
+	err = re.Rollback(context.Background(), types.DiscoveryManifest{}, types.Recipe{Name: "host"}, vars)
+	require.Error(t, err)
+}
+
+func TestKubernetesRecipeExecutor_Uninstall(t *testing.T) {
+	re := NewKubernetesRecipeExecutor(NewMockFailingRecipeExecutor(), "")
+
+	err := re.Uninstall(context.Background(), types.DiscoveryManifest{}, kubernetesTestRecipe, types.RecipeVars{})
+	require.NoError(t, err)
+
+	err = re.Uninstall(context.Background(), types.DiscoveryManifest{}, types.Recipe{Name: "host"}, types.RecipeVars{})
+	require.Error(t, err)
+}
@@ -0,0 +1,132 @@
+package execution
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+
+	"github.com/newrelic/newrelic-cli/internal/install/types"
+	"github.com/newrelic/newrelic-cli/internal/utils"
+)
+
+// LocalHistoryStatusReporter is an implementation of the StatusSubscriber
+// interface that keeps a versioned copy of each install's status on the local
+// filesystem, one file per install.
+type LocalHistoryStatusReporter struct {
+	dir       string
+	startedAt int64
+	written   bool
+}
+
+// NewLocalHistoryStatusReporter returns a new instance of LocalHistoryStatusReporter.
+func NewLocalHistoryStatusReporter(dir string) *LocalHistoryStatusReporter {
+	r := LocalHistoryStatusReporter{
+		dir:       dir,
+		startedAt: utils.GetTimestamp(),
+	}
+
+	return &r
+}
+
+func (r *LocalHistoryStatusReporter) RecipesAvailable(status *InstallStatus, recipes []types.Recipe) error {
+	return r.writeStatus(status)
+}
+
+func (r *LocalHistoryStatusReporter) RecipesSelected(status *InstallStatus, recipes []types.Recipe) error {
+	return nil
+}
+
+func (r *LocalHistoryStatusReporter) RecipeAvailable(status *InstallStatus, recipe types.Recipe) error {
+	return r.writeStatus(status)
+}
+
+func (r *LocalHistoryStatusReporter) RecipeFailed(status *InstallStatus, event RecipeStatusEvent) error {
+	return r.writeStatus(status)
+}
+
+func (r *LocalHistoryStatusReporter) RecipeInstalling(status *InstallStatus, event RecipeStatusEvent) error {
+	return r.writeStatus(status)
+}
+
+func (r *LocalHistoryStatusReporter) RecipeInstalled(status *InstallStatus, event RecipeStatusEvent) error {
+	return r.writeStatus(status)
+}
+
+func (r *LocalHistoryStatusReporter) RecipeRecommended(status *InstallStatus, event RecipeStatusEvent) error {
+	return r.writeStatus(status)
+}
+
+func (r *LocalHistoryStatusReporter) RecipeSkipped(status *InstallStatus, event RecipeStatusEvent) error {
+	return r.writeStatus(status)
+}
+
+func (r *LocalHistoryStatusReporter) RecipeUninstalled(status *InstallStatus, event RecipeStatusEvent) error {
+	return r.writeStatus(status)
+}
+
+func (r *LocalHistoryStatusReporter) RecipeRolledBack(status *InstallStatus, event RecipeStatusEvent) error {
+	return r.writeStatus(status)
+}
+
+func (r *LocalHistoryStatusReporter) InstallComplete(status *InstallStatus) error {
+	return r.writeStatus(status)
+}
+
+func (r *LocalHistoryStatusReporter) InstallCanceled(status *InstallStatus) error {
+	return r.writeStatus(status)
+}
+
+func (r *LocalHistoryStatusReporter) DiscoveryComplete(status *InstallStatus, dm types.DiscoveryManifest) error {
+	return r.writeStatus(status)
+}
+
+// writeStatus replaces the history file for the install.  The file is written
+// to a temporary location first so an interrupted write never leaves a
+// truncated record behind.
+func (r *LocalHistoryStatusReporter) writeStatus(status *InstallStatus) error {
+	if err := os.MkdirAll(r.dir, 0750); err != nil {
+		return err
+	}
+
+	path, err := installHistoryFilePath(r.dir, status.DocumentID)
+	if err != nil {
+		return err
+	}
+
+	// A resumed install keeps the start time of the install it continues.
+	if !r.written {
+		if prior, err := readInstallHistoryRecord(path); err == nil {
+			r.startedAt = prior.StartedAt
+		}
+		r.written = true
+	}
+
+	record := InstallHistoryRecord{
+		Version:   InstallHistoryVersion,
+		StartedAt: r.startedAt,
+		UpdatedAt: utils.GetTimestamp(),
+		Status:    status,
+	}
+
+	out, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	file, err := ioutil.TempFile(r.dir, status.DocumentID)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err = file.Write(out); err != nil {
+		file.Close()
+		return err
+	}
+
+	if err = file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), path)
+}
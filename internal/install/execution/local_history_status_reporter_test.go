@@ -0,0 +1,120 @@
+// +build unit
+
+package execution
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/newrelic/newrelic-cli/internal/install/types"
+)
+
+func TestLocalHistoryStatusReporter_interface(t *testing.T) {
+	var r StatusSubscriber = NewLocalHistoryStatusReporter("")
+	require.NotNil(t, r)
+}
+
+func TestLocalHistoryStatusReporter_WritesHistory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "installs")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	r := NewLocalHistoryStatusReporter(dir)
+	s := NewInstallStatus([]StatusSubscriber{r})
+	recipe := types.Recipe{Name: "testRecipe", DisplayName: "Test Recipe"}
+
+	s.DiscoveryComplete(types.DiscoveryManifest{
+		Hostname: "test-host",
+		Processes: []types.MatchedProcess{
+			{Command: "mysqld", MatchingPattern: "mysql", Process: mockProcess{}},
+		},
+	})
+	s.RecipeAvailable(recipe)
+	s.RecipeInstalled(RecipeStatusEvent{Recipe: recipe, EntityGUID: "testGUID"})
+	s.InstallComplete(nil)
+
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	require.Equal(t, s.DocumentID+".json", files[0].Name())
+
+	record, err := LoadInstallHistoryRecord(dir, s.DocumentID)
+	require.NoError(t, err)
+	require.Equal(t, InstallHistoryVersion, record.Version)
+	require.NotZero(t, record.StartedAt)
+	require.Equal(t, s.DocumentID, record.Status.DocumentID)
+	require.True(t, record.Status.Complete)
+	require.Equal(t, []string{"testGUID"}, record.Status.EntityGUIDs)
+	require.Len(t, record.Status.RecipesInstalled, 1)
+	require.Equal(t, "test-host", record.Status.DiscoveryManifest.Hostname)
+	require.Equal(t, "mysqld", record.Status.DiscoveryManifest.Processes[0].Command)
+}
+
+func TestLoadInstallHistory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "installs")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	older := NewLocalHistoryStatusReporter(dir)
+	older.startedAt = 1
+	err = older.writeStatus(&InstallStatus{DocumentID: "older"})
+	require.NoError(t, err)
+
+	newer := NewLocalHistoryStatusReporter(dir)
+	newer.startedAt = 2
+	err = newer.writeStatus(&InstallStatus{DocumentID: "newer"})
+	require.NoError(t, err)
+
+	err = ioutil.WriteFile(filepath.Join(dir, "broken.json"), []byte("{"), 0600)
+	require.NoError(t, err)
+
+	records, err := LoadInstallHistory(dir)
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	require.Equal(t, "newer", records[0].Status.DocumentID)
+	require.Equal(t, "older", records[1].Status.DocumentID)
+}
+
+func TestLoadInstallHistory_MissingDirectory(t *testing.T) {
+	records, err := LoadInstallHistory(filepath.Join(os.TempDir(), "does-not-exist-installs"))
+	require.NoError(t, err)
+	require.Empty(t, records)
+}
+
+func TestLoadInstallHistoryRecord_NotFound(t *testing.T) {
+	dir, err := ioutil.TempDir("", "installs")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	_, err = LoadInstallHistoryRecord(dir, "missing")
+	require.Error(t, err)
+}
+
+func TestLoadInstallHistoryRecord_InvalidDocumentID(t *testing.T) {
+	dir, err := ioutil.TempDir("", "installs")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	outside := filepath.Join(filepath.Dir(dir), "outside.json")
+	require.NoError(t, ioutil.WriteFile(outside, []byte(`{"version":1,"status":{}}`), 0600))
+	defer os.Remove(outside)
+
+	for _, id := range []string{"", "..", "../outside", `..\outside`, "a/b"} {
+		_, err = LoadInstallHistoryRecord(dir, id)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid install document ID")
+	}
+}
+
+type mockProcess struct{}
+
+func (p mockProcess) Name() (string, error)    { return "mysqld", nil }
+func (p mockProcess) Cmdline() (string, error) { return "mysqld", nil }
+func (p mockProcess) PID() int32               { return 1 }
+func (p mockProcess) ListeningPorts() ([]types.ListeningPort, error) {
+	return nil, nil
+}
@@ -24,3 +24,11 @@ func (m *MockFailingRecipeExecutor) Prepare(ctx context.Context, dm types.Discov
 func (m *MockFailingRecipeExecutor) Execute(ctx context.Context, dm types.DiscoveryManifest, r types.Recipe, v types.RecipeVars) error {
 	return fmt.Errorf("something went wrong")
 }
+
+func (m *MockFailingRecipeExecutor) Uninstall(ctx context.Context, dm types.DiscoveryManifest, r types.Recipe, v types.RecipeVars) error {
+	return fmt.Errorf("something went wrong")
+}
+
+func (m *MockFailingRecipeExecutor) Rollback(ctx context.Context, dm types.DiscoveryManifest, r types.Recipe, v types.RecipeVars) error {
+	return fmt.Errorf("something went wrong")
+}
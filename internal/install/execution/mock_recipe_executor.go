@@ -23,3 +23,11 @@ func (m *MockRecipeExecutor) Prepare(ctx context.Context, dm types.DiscoveryMani
 func (m *MockRecipeExecutor) Execute(ctx context.Context, dm types.DiscoveryManifest, r types.Recipe, v types.RecipeVars) error {
 	return nil
 }
+
+func (m *MockRecipeExecutor) Uninstall(ctx context.Context, dm types.DiscoveryManifest, r types.Recipe, v types.RecipeVars) error {
+	return nil
+}
+
+func (m *MockRecipeExecutor) Rollback(ctx context.Context, dm types.DiscoveryManifest, r types.Recipe, v types.RecipeVars) error {
+	return nil
+}
@@ -15,6 +15,8 @@ type MockStatusReporter struct {
 	RecipeInstallingErr        error
 	RecipeRecommendedErr       error
 	RecipeSkippedErr           error
+	RecipeUninstalledErr       error
+	RecipeRolledBackErr        error
 	InstallCompleteErr         error
 	InstallCanceledErr         error
 	DiscoveryCompleteErr       error
@@ -26,6 +28,8 @@ type MockStatusReporter struct {
 	RecipeInstallingCallCount  int
 	RecipeRecommendedCallCount int
 	RecipeSkippedCallCount     int
+	RecipeUninstalledCallCount int
+	RecipeRolledBackCallCount  int
 	InstallCompleteCallCount   int
 	InstallCanceledCallCount   int
 	DiscoveryCompleteCallCount int
@@ -35,6 +39,8 @@ type MockStatusReporter struct {
 	ReportInstalling  map[string]int
 	ReportRecommended map[string]int
 	ReportFailed      map[string]int
+	ReportUninstalled map[string]int
+	ReportRolledBack  map[string]int
 	ReportAvailable   map[string]int
 
 	GUIDs      []string
@@ -107,6 +113,24 @@ func (r *MockStatusReporter) RecipeSkipped(status *InstallStatus, event RecipeSt
 	return r.RecipeSkippedErr
 }
 
+func (r *MockStatusReporter) RecipeUninstalled(status *InstallStatus, event RecipeStatusEvent) error {
+	r.RecipeUninstalledCallCount++
+	if len(r.ReportUninstalled) == 0 {
+		r.ReportUninstalled = make(map[string]int)
+	}
+	r.ReportUninstalled[event.Recipe.Name]++
+	return r.RecipeUninstalledErr
+}
+
+func (r *MockStatusReporter) RecipeRolledBack(status *InstallStatus, event RecipeStatusEvent) error {
+	r.RecipeRolledBackCallCount++
+	if len(r.ReportRolledBack) == 0 {
+		r.ReportRolledBack = make(map[string]int)
+	}
+	r.ReportRolledBack[event.Recipe.Name]++
+	return r.RecipeRolledBackErr
+}
+
 func (r *MockStatusReporter) RecipeAvailable(status *InstallStatus, recipe types.Recipe) error {
 	r.RecipeAvailableCallCount++
 	if len(r.ReportAvailable) == 0 {
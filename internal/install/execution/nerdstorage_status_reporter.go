@@ -63,6 +63,14 @@ func (r NerdstorageStatusReporter) RecipeSkipped(status *InstallStatus, event Re
 	return r.writeStatus(status)
 }
 
+func (r NerdstorageStatusReporter) RecipeUninstalled(status *InstallStatus, event RecipeStatusEvent) error {
+	return r.writeStatus(status)
+}
+
+func (r NerdstorageStatusReporter) RecipeRolledBack(status *InstallStatus, event RecipeStatusEvent) error {
+	return r.writeStatus(status)
+}
+
 func (r NerdstorageStatusReporter) InstallComplete(status *InstallStatus) error {
 	return r.writeStatus(status)
 }
@@ -0,0 +1,223 @@
+package execution
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/newrelic/newrelic-cli/internal/install/types"
+)
+
+// PlanFormat is the format an install plan is rendered in.
+type PlanFormat string
+
+var PlanFormats = struct {
+	TEXT PlanFormat
+	JSON PlanFormat
+}{
+	TEXT: "text",
+	JSON: "json",
+}
+
+// InstallPlan describes what an install would do, without having done it.
+type InstallPlan struct {
+	DiscoveryManifest types.DiscoveryManifest `json:"discoveryManifest"`
+	Recipes           []PlannedRecipe         `json:"recipes"`
+}
+
+// PlanStatusReporter is an implementation of the StatusSubscriber interface
+// that renders the plan recorded by a RecordingRecipeExecutor once the install
+// completes.
+type PlanStatusReporter struct {
+	recorder *RecordingRecipeExecutor
+	format   PlanFormat
+	writer   io.Writer
+}
+
+// NewPlanStatusReporter returns a new instance of PlanStatusReporter.
+func NewPlanStatusReporter(recorder *RecordingRecipeExecutor, format PlanFormat) *PlanStatusReporter {
+	r := PlanStatusReporter{
+		recorder: recorder,
+		format:   format,
+		writer:   os.Stdout,
+	}
+
+	return &r
+}
+
+func (r PlanStatusReporter) RecipeFailed(status *InstallStatus, event RecipeStatusEvent) error {
+	return nil
+}
+
+func (r PlanStatusReporter) RecipeInstalling(status *InstallStatus, event RecipeStatusEvent) error {
+	return nil
+}
+
+func (r PlanStatusReporter) RecipeInstalled(status *InstallStatus, event RecipeStatusEvent) error {
+	return nil
+}
+
+func (r PlanStatusReporter) RecipeSkipped(status *InstallStatus, event RecipeStatusEvent) error {
+	return nil
+}
+
+func (r PlanStatusReporter) RecipeUninstalled(status *InstallStatus, event RecipeStatusEvent) error {
+	return nil
+}
+
+func (r PlanStatusReporter) RecipeRolledBack(status *InstallStatus, event RecipeStatusEvent) error {
+	return nil
+}
+
+func (r PlanStatusReporter) RecipeRecommended(status *InstallStatus, event RecipeStatusEvent) error {
+	return nil
+}
+
+func (r PlanStatusReporter) RecipesAvailable(status *InstallStatus, recipes []types.Recipe) error {
+	return nil
+}
+
+func (r PlanStatusReporter) RecipesSelected(status *InstallStatus, recipes []types.Recipe) error {
+	return nil
+}
+
+func (r PlanStatusReporter) RecipeAvailable(status *InstallStatus, recipe types.Recipe) error {
+	return nil
+}
+
+func (r PlanStatusReporter) InstallComplete(status *InstallStatus) error {
+	plan := InstallPlan{
+		DiscoveryManifest: status.DiscoveryManifest,
+		Recipes:           r.recorder.Recipes,
+	}
+
+	if status.Error.Message != "" {
+		fmt.Fprintf(r.writer, "  The install plan is incomplete: %s\n\n", status.Error.Message)
+	}
+
+	return RenderInstallPlan(r.writer, plan, r.format)
+}
+
+func (r PlanStatusReporter) InstallCanceled(status *InstallStatus) error {
+	return nil
+}
+
+func (r PlanStatusReporter) DiscoveryComplete(status *InstallStatus, dm types.DiscoveryManifest) error {
+	return nil
+}
+
+// RenderInstallPlan writes the plan to w in the given format.
+func RenderInstallPlan(w io.Writer, plan InstallPlan, format PlanFormat) error {
+	switch PlanFormat(strings.ToLower(string(format))) {
+	case PlanFormats.JSON:
+		return renderInstallPlanJSON(w, plan)
+	case PlanFormats.TEXT, "":
+		return renderInstallPlanText(w, plan)
+	}
+
+	return fmt.Errorf("unknown plan format %s", format)
+}
+
+func renderInstallPlanJSON(w io.Writer, plan InstallPlan) error {
+	if plan.Recipes == nil {
+		plan.Recipes = []PlannedRecipe{}
+	}
+
+	out, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(w, string(out))
+	return err
+}
+
+func renderInstallPlanText(w io.Writer, plan InstallPlan) error {
+	m := plan.DiscoveryManifest
+
+	fmt.Fprintln(w, "Install plan (dry run, nothing has been executed)")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Discovery")
+	fmt.Fprintf(w, "  Hostname:         %s\n", m.Hostname)
+	fmt.Fprintf(w, "  OS:               %s\n", m.OS)
+	fmt.Fprintf(w, "  Platform:         %s %s (%s)\n", m.Platform, m.PlatformVersion, m.PlatformFamily)
+	fmt.Fprintf(w, "  Kernel:           %s %s\n", m.KernelArch, m.KernelVersion)
+
+	if len(m.Processes) > 0 {
+		fmt.Fprintln(w, "  Matched processes:")
+		for _, p := range m.Processes {
+			fmt.Fprintf(w, "    - %s (matched %s)%s\n", p.Command, p.MatchingPattern, formatListeningPorts(p.Ports))
+		}
+	}
+
+	if len(m.Containers) > 0 {
+		fmt.Fprintln(w, "  Containers:")
+		for _, c := range m.Containers {
+			fmt.Fprintf(w, "    - %s %s (%s)\n", c.Runtime, c.Name, c.Image)
+		}
+	}
+
+	fmt.Fprintln(w)
+
+	if len(plan.Recipes) == 0 {
+		fmt.Fprintln(w, "No recipes would be installed.")
+		return nil
+	}
+
+	fmt.Fprintln(w, "Recipes")
+	for n, r := range plan.Recipes {
+		name := r.Name
+		if r.DisplayName != "" {
+			name = fmt.Sprintf("%s (%s)", r.DisplayName, r.Name)
+		}
+
+		fmt.Fprintf(w, "  %d. %s\n", n+1, name)
+
+		fmt.Fprintln(w, "     Vars:")
+		keys := make([]string, 0, len(r.Vars))
+		for k := range r.Vars {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			fmt.Fprintf(w, "       %s=%s\n", k, indentContinuation(r.Vars[k], "         "))
+		}
+
+		fmt.Fprintln(w, "     Commands:")
+		if len(r.Commands) == 0 {
+			fmt.Fprintln(w, "       (none)")
+		}
+
+		for _, c := range r.Commands {
+			fmt.Fprintf(w, "       $ %s\n", indentContinuation(c, "         "))
+		}
+
+		fmt.Fprintln(w)
+	}
+
+	return nil
+}
+
+// indentContinuation indents every line of a multi-line value after the first.
+func indentContinuation(s string, indent string) string {
+	return strings.ReplaceAll(strings.TrimRight(s, "\n"), "\n", "\n"+indent)
+}
+
+// formatListeningPorts describes the ports a process listens on, such as
+// " listening on 6379/tcp".
+func formatListeningPorts(ports []types.ListeningPort) string {
+	if len(ports) == 0 {
+		return ""
+	}
+
+	p := []string{}
+	for _, lp := range ports {
+		p = append(p, fmt.Sprintf("%d/%s", lp.Port, lp.Protocol))
+	}
+
+	return " listening on " + strings.Join(p, ", ")
+}
@@ -0,0 +1,143 @@
+package execution
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/newrelic/newrelic-cli/internal/install/recipes"
+)
+
+// RecipeAnswers holds values for recipe input vars, loaded from an answers
+// file so that installs can run unattended.  Values under recipes are
+// namespaced by recipe name and take precedence over global values.
+//
+//	global:
+//	  NR_CLI_DB_HOSTNAME: localhost
+//	recipes:
+//	  mysql-open-source-integration:
+//	    NR_CLI_DB_USERNAME: newrelic
+//	    NR_CLI_DB_PASSWORD:
+//	      env: MYSQL_PASSWORD
+//	  nginx-open-source-integration:
+//	    NR_CLI_STATUS_URL:
+//	      file: /etc/newrelic/nginx-status-url
+type RecipeAnswers struct {
+	Global  map[string]AnswerValue            `yaml:"global"`
+	Recipes map[string]map[string]AnswerValue `yaml:"recipes"`
+}
+
+// AnswerValue is a single answer.  It is either a literal value, or a
+// reference to an environment variable or a file holding the value.
+type AnswerValue struct {
+	Value string `yaml:"value"`
+	Env   string `yaml:"env"`
+	File  string `yaml:"file"`
+}
+
+// UnmarshalYAML allows an answer to be given as a plain scalar value.
+func (v *AnswerValue) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var literal string
+	if err := unmarshal(&literal); err == nil {
+		v.Value = literal
+		return nil
+	}
+
+	type answerValue AnswerValue
+	var ref answerValue
+	if err := unmarshal(&ref); err != nil {
+		return err
+	}
+
+	set := 0
+	for _, s := range []string{ref.Value, ref.Env, ref.File} {
+		if s != "" {
+			set++
+		}
+	}
+
+	if set != 1 {
+		return errors.New("an answer must set exactly one of value, env or file")
+	}
+
+	*v = AnswerValue(ref)
+
+	return nil
+}
+
+// resolve returns the value of the answer, and false if a referenced
+// environment variable is not set.
+func (v AnswerValue) resolve() (string, bool, error) {
+	switch {
+	case v.Env != "":
+		val := os.Getenv(v.Env)
+		return val, val != "", nil
+	case v.File != "":
+		out, err := ioutil.ReadFile(v.File)
+		if err != nil {
+			return "", false, fmt.Errorf("could not read answer file %s: %s", v.File, err)
+		}
+
+		return strings.TrimRight(string(out), "\r\n"), true, nil
+	}
+
+	return v.Value, true, nil
+}
+
+// LoadRecipeAnswers reads an answers file.
+func LoadRecipeAnswers(path string) (*RecipeAnswers, error) {
+	out, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read vars file %s: %s", path, err)
+	}
+
+	var a RecipeAnswers
+	if err = yaml.UnmarshalStrict(out, &a); err != nil {
+		return nil, fmt.Errorf("could not parse vars file %s: %s", path, err)
+	}
+
+	return &a, nil
+}
+
+// Lookup returns the answer for a recipe's input var, preferring the value
+// namespaced by the recipe name over the global one.
+func (a *RecipeAnswers) Lookup(recipeName string, varName string) (string, bool, error) {
+	if a == nil {
+		return "", false, nil
+	}
+
+	if v, ok := a.Recipes[recipeName][varName]; ok {
+		return v.resolve()
+	}
+
+	if v, ok := a.Global[varName]; ok {
+		return v.resolve()
+	}
+
+	return "", false, nil
+}
+
+// MissingInputVars returns the names of the input vars of a recipe that have
+// no value in the answers, the environment, or a default.
+func (a *RecipeAnswers) MissingInputVars(recipeName string, inputVars []recipes.VariableConfig) ([]string, error) {
+	missing := []string{}
+
+	for _, v := range inputVars {
+		_, ok, err := a.Lookup(recipeName, v.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		if ok || os.Getenv(v.Name) != "" || v.Default != "" {
+			continue
+		}
+
+		missing = append(missing, v.Name)
+	}
+
+	return missing, nil
+}
@@ -0,0 +1,139 @@
+// +build unit
+
+package execution
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/newrelic/newrelic-cli/internal/install/recipes"
+)
+
+func writeTestAnswersFile(t *testing.T, dir string, content string) string {
+	path := filepath.Join(dir, "answers.yaml")
+	err := ioutil.WriteFile(path, []byte(content), 0600)
+	require.NoError(t, err)
+
+	return path
+}
+
+func TestLoadRecipeAnswers(t *testing.T) {
+	dir, err := ioutil.TempDir("", "answers")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	secretPath := filepath.Join(dir, "secret")
+	err = ioutil.WriteFile(secretPath, []byte("fromfile\n"), 0600)
+	require.NoError(t, err)
+
+	os.Setenv("TEST_ANSWERS_PASSWORD", "fromenv")
+	defer os.Unsetenv("TEST_ANSWERS_PASSWORD")
+
+	path := writeTestAnswersFile(t, dir, `
+global:
+  HOST: localhost
+  PORT: 3306
+recipes:
+  mysql:
+    HOST: db.local
+    PASSWORD:
+      env: TEST_ANSWERS_PASSWORD
+    KEY:
+      file: `+secretPath+`
+`)
+
+	a, err := LoadRecipeAnswers(path)
+	require.NoError(t, err)
+
+	v, ok, err := a.Lookup("mysql", "HOST")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "db.local", v)
+
+	v, ok, err = a.Lookup("nginx", "HOST")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "localhost", v)
+
+	v, ok, err = a.Lookup("mysql", "PORT")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "3306", v)
+
+	v, ok, err = a.Lookup("mysql", "PASSWORD")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "fromenv", v)
+
+	v, ok, err = a.Lookup("mysql", "KEY")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "fromfile", v)
+
+	_, ok, err = a.Lookup("mysql", "UNKNOWN")
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestLoadRecipeAnswers_InvalidReference(t *testing.T) {
+	dir, err := ioutil.TempDir("", "answers")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := writeTestAnswersFile(t, dir, `
+recipes:
+  mysql:
+    PASSWORD:
+      env: A
+      file: /b
+`)
+
+	_, err = LoadRecipeAnswers(path)
+	require.Error(t, err)
+}
+
+func TestRecipeAnswers_MissingInputVars(t *testing.T) {
+	os.Unsetenv("TEST_ANSWERS_UNSET")
+
+	a := &RecipeAnswers{
+		Recipes: map[string]map[string]AnswerValue{
+			"mysql": {
+				"USER":     {Value: "newrelic"},
+				"PASSWORD": {Env: "TEST_ANSWERS_UNSET"},
+			},
+		},
+	}
+	inputVars := []recipes.VariableConfig{
+		{Name: "USER"},
+		{Name: "PASSWORD"},
+		{Name: "PORT", Default: "3306"},
+		{Name: "HOST"},
+	}
+
+	missing, err := a.MissingInputVars("mysql", inputVars)
+	require.NoError(t, err)
+	require.Equal(t, []string{"PASSWORD", "HOST"}, missing)
+}
+
+func TestVarsFromInput_Answers(t *testing.T) {
+	a := &RecipeAnswers{
+		Recipes: map[string]map[string]AnswerValue{
+			"mysql": {
+				"USER": {Value: "newrelic"},
+			},
+		},
+	}
+	inputVars := []recipes.VariableConfig{
+		{Name: "USER"},
+		{Name: "PORT", Default: "3306"},
+	}
+
+	vars, err := varsFromInput("mysql", inputVars, false, a)
+	require.NoError(t, err)
+	require.Equal(t, "newrelic", vars["USER"])
+	require.Equal(t, "3306", vars["PORT"])
+}
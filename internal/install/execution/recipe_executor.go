@@ -11,4 +11,6 @@ import (
 type RecipeExecutor interface {
 	Prepare(context.Context, types.DiscoveryManifest, types.Recipe, bool, string) (types.RecipeVars, error)
 	Execute(context.Context, types.DiscoveryManifest, types.Recipe, types.RecipeVars) error
+	Uninstall(context.Context, types.DiscoveryManifest, types.Recipe, types.RecipeVars) error
+	Rollback(context.Context, types.DiscoveryManifest, types.Recipe, types.RecipeVars) error
 }
@@ -0,0 +1,157 @@
+package execution
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/newrelic/newrelic-cli/internal/config"
+)
+
+const (
+	// RecipeLogTailLines is how many of the last lines printed by failed recipe
+	// tasks are kept in the recipe's status.
+	RecipeLogTailLines = 20
+
+	recipeLogDirName = "recipe-logs"
+	recipeLogFileExt = ".log"
+)
+
+var unsafeRecipeLogChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// DefaultRecipeLogDirectory returns the directory the output of recipe tasks
+// is logged to by default.
+func DefaultRecipeLogDirectory() string {
+	return filepath.Join(config.DefaultConfigDirectory, recipeLogDirName)
+}
+
+// RecipeLogFilePath returns the file the output of a recipe's tasks is logged
+// to.  Every run of the recipe is appended to the same file.
+func RecipeLogFilePath(dir string, recipeName string) string {
+	return filepath.Join(dir, unsafeRecipeLogChars.ReplaceAllString(recipeName, "-")+recipeLogFileExt)
+}
+
+// TaskError is returned when the tasks of a recipe fail.  It carries the last
+// lines the tasks printed and the log file their full output was written to.
+type TaskError struct {
+	Err     error
+	Output  string
+	LogFile string
+}
+
+func (e *TaskError) Error() string {
+	return e.Err.Error()
+}
+
+// TaskErrorDetails returns what failed recipe tasks printed, for the details of
+// a status error.  Errors other than a TaskError have no details.
+func TaskErrorDetails(err error) string {
+	te, ok := err.(*TaskError)
+	if !ok {
+		return ""
+	}
+
+	details := []string{}
+	if te.Output != "" {
+		details = append(details, te.Output)
+	}
+
+	if te.LogFile != "" {
+		details = append(details, fmt.Sprintf("See %s for the full output.", te.LogFile))
+	}
+
+	return strings.Join(details, "\n")
+}
+
+// openRecipeLog opens the log file of a recipe for appending, and writes a
+// header for this run of its tasks.  The returned file is nil when the log
+// cannot be opened, in which case the tasks run without one.
+func openRecipeLog(dir string, recipeName string, action string) *os.File {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		log.Debugf("not logging the output of %s: %s", recipeName, err)
+		return nil
+	}
+
+	path := RecipeLogFilePath(dir, recipeName)
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		log.Debugf("not logging the output of %s: %s", recipeName, err)
+		return nil
+	}
+
+	fmt.Fprintf(file, "==> %s %s at %s\n", action, recipeName, time.Now().Format(time.RFC3339))
+
+	return file
+}
+
+// tailWriter keeps the last lines written to it.  It is safe to write to from
+// the stdout and stderr of tasks at once.
+type tailWriter struct {
+	mu      sync.Mutex
+	max     int
+	lines   []string
+	partial bytes.Buffer
+}
+
+func newTailWriter(max int) *tailWriter {
+	w := tailWriter{
+		max: max,
+	}
+
+	return &w
+}
+
+func (w *tailWriter) Write(b []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.partial.Write(b)
+
+	for {
+		i := bytes.IndexByte(w.partial.Bytes(), '\n')
+		if i < 0 {
+			break
+		}
+
+		w.add(string(w.partial.Next(i + 1)))
+	}
+
+	return len(b), nil
+}
+
+func (w *tailWriter) add(line string) {
+	line = strings.TrimRight(line, "\r\n")
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+
+	w.lines = append(w.lines, line)
+	if len(w.lines) > w.max {
+		w.lines = w.lines[len(w.lines)-w.max:]
+	}
+}
+
+// String returns the last lines written, including a final line that did not
+// end with a newline.
+func (w *tailWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	lines := append([]string{}, w.lines...)
+	if last := strings.TrimRight(w.partial.String(), "\r"); strings.TrimSpace(last) != "" {
+		lines = append(lines, last)
+	}
+
+	if len(lines) > w.max {
+		lines = lines[len(lines)-w.max:]
+	}
+
+	return strings.Join(lines, "\n")
+}
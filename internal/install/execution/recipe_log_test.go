@@ -0,0 +1,54 @@
+// +build unit
+
+package execution
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTailWriter_KeepsLastLines(t *testing.T) {
+	w := newTailWriter(3)
+
+	for i := 1; i <= 5; i++ {
+		fmt.Fprintf(w, "line %d\n", i)
+	}
+
+	require.Equal(t, "line 3\nline 4\nline 5", w.String())
+}
+
+func TestTailWriter_PartialLines(t *testing.T) {
+	w := newTailWriter(2)
+
+	_, err := w.Write([]byte("first\r\n\nsec"))
+	require.NoError(t, err)
+	require.Equal(t, "first\nsec", w.String())
+
+	_, err = w.Write([]byte("ond\nthird"))
+	require.NoError(t, err)
+	require.Equal(t, "second\nthird", w.String())
+}
+
+func TestRecipeLogFilePath(t *testing.T) {
+	require.Equal(t, filepath.Join("logs", "infrastructure-agent-installer.log"), RecipeLogFilePath("logs", "infrastructure-agent-installer"))
+	require.Equal(t, filepath.Join("logs", "Test-Recipe.log"), RecipeLogFilePath("logs", "Test Recipe"))
+	require.Equal(t, filepath.Join("logs", "..-etc-passwd.log"), RecipeLogFilePath("logs", "../etc/passwd"))
+}
+
+func TestTaskErrorDetails(t *testing.T) {
+	require.Equal(t, "", TaskErrorDetails(errors.New("failed")))
+
+	err := &TaskError{
+		Err:     errors.New("exit status 1"),
+		Output:  "could not write config",
+		LogFile: "/tmp/test.log",
+	}
+
+	require.Equal(t, "exit status 1", err.Error())
+	require.Equal(t, "could not write config\nSee /tmp/test.log for the full output.", TaskErrorDetails(err))
+	require.Equal(t, "output", TaskErrorDetails(&TaskError{Err: err, Output: "output"}))
+}
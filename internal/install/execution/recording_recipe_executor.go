@@ -0,0 +1,212 @@
+package execution
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/go-task/task/v3"
+	taskargs "github.com/go-task/task/v3/args"
+	"github.com/go-task/task/v3/taskfile"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/newrelic/newrelic-cli/internal/install/recipes"
+	"github.com/newrelic/newrelic-cli/internal/install/types"
+)
+
+const (
+	redactedValue = "********"
+
+	// maxTaskDepth guards against recipes whose tasks call each other in a loop.
+	maxTaskDepth = 20
+)
+
+// secretVarNames are the recipe vars that always contain credentials.
+var secretVarNames = []string{
+	"NEW_RELIC_LICENSE_KEY",
+	"NEW_RELIC_API_KEY",
+}
+
+// PlannedRecipe represents a recipe that would have been executed, along with
+// its resolved vars and the commands its install tasks would run.
+type PlannedRecipe struct {
+	Name        string           `json:"name"`
+	DisplayName string           `json:"displayName"`
+	Vars        types.RecipeVars `json:"vars"`
+	Commands    []string         `json:"commands"`
+}
+
+// RecordingRecipeExecutor is an implementation of the RecipeExecutor interface
+// that prepares recipes with an underlying executor, but only records what
+// would be executed instead of running anything.  It is used to build the plan
+// for a dry run.
+type RecordingRecipeExecutor struct {
+	executor RecipeExecutor
+	Recipes  []PlannedRecipe
+}
+
+// NewRecordingRecipeExecutor returns a new instance of RecordingRecipeExecutor.
+func NewRecordingRecipeExecutor(executor RecipeExecutor) *RecordingRecipeExecutor {
+	return &RecordingRecipeExecutor{
+		executor: executor,
+	}
+}
+
+func (re *RecordingRecipeExecutor) Prepare(ctx context.Context, m types.DiscoveryManifest, r types.Recipe, assumeYes bool, licenseKey string) (types.RecipeVars, error) {
+	return re.executor.Prepare(ctx, m, r, assumeYes, licenseKey)
+}
+
+func (re *RecordingRecipeExecutor) Execute(ctx context.Context, m types.DiscoveryManifest, r types.Recipe, recipeVars types.RecipeVars) error {
+	log.Debugf("recording recipe %s", r.Name)
+
+	f, err := recipes.RecipeToRecipeFile(r)
+	if err != nil {
+		return fmt.Errorf("could not convert recipe to recipe file: %s", err)
+	}
+
+	vars := redactRecipeVars(recipeVars, f.InputVars)
+
+	commands, err := renderRecipeCommands(r.Name, f.Install, vars)
+	if err != nil {
+		return fmt.Errorf("could not render commands for recipe %s: %s", r.Name, err)
+	}
+
+	re.Recipes = append(re.Recipes, PlannedRecipe{
+		Name:        r.Name,
+		DisplayName: r.DisplayName,
+		Vars:        vars,
+		Commands:    commands,
+	})
+
+	return nil
+}
+
+// Uninstall records the uninstall section of a recipe in the same way Execute
+// records the install section.
+func (re *RecordingRecipeExecutor) Uninstall(ctx context.Context, m types.DiscoveryManifest, r types.Recipe, recipeVars types.RecipeVars) error {
+	log.Debugf("recording uninstall of recipe %s", r.Name)
+
+	f, err := recipes.RecipeToRecipeFile(r)
+	if err != nil {
+		return fmt.Errorf("could not convert recipe to recipe file: %s", err)
+	}
+
+	vars := redactRecipeVars(recipeVars, f.InputVars)
+
+	commands, err := renderRecipeCommands(r.Name, f.Uninstall, vars)
+	if err != nil {
+		return fmt.Errorf("could not render uninstall commands for recipe %s: %s", r.Name, err)
+	}
+
+	re.Recipes = append(re.Recipes, PlannedRecipe{
+		Name:        r.Name,
+		DisplayName: r.DisplayName,
+		Vars:        vars,
+		Commands:    commands,
+	})
+
+	return nil
+}
+
+// Rollback does nothing, since a dry run makes no changes to undo.
+func (re *RecordingRecipeExecutor) Rollback(ctx context.Context, m types.DiscoveryManifest, r types.Recipe, recipeVars types.RecipeVars) error {
+	return nil
+}
+
+// redactRecipeVars returns a copy of the given vars with credentials and
+// secret input vars replaced.
+func redactRecipeVars(recipeVars types.RecipeVars, inputVars []recipes.VariableConfig) types.RecipeVars {
+	secrets := map[string]bool{}
+	for _, n := range secretVarNames {
+		secrets[n] = true
+	}
+
+	for _, v := range inputVars {
+		if v.Secret {
+			secrets[v.Name] = true
+		}
+	}
+
+	vars := types.RecipeVars{}
+	for k, v := range recipeVars {
+		if secrets[k] && v != "" {
+			vars[k] = redactedValue
+			continue
+		}
+
+		vars[k] = v
+	}
+
+	return vars
+}
+
+// renderRecipeCommands returns the commands a task section of a recipe would
+// run, in order, with the given vars templated in.  Dynamic variables are not
+// evaluated, so no shell commands are run while rendering.
+func renderRecipeCommands(name string, tasks map[string]interface{}, recipeVars types.RecipeVars) ([]string, error) {
+	e, cleanup, err := newTaskExecutor(name, "", tasks, recipeVars, ioutil.Discard, ioutil.Discard)
+	defer cleanup()
+	if err != nil {
+		return nil, err
+	}
+
+	commands := []string{}
+	calls, _ := taskargs.ParseV3()
+
+	for _, call := range calls {
+		c, err := renderTaskCommands(e, call, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		commands = append(commands, c...)
+	}
+
+	return commands, nil
+}
+
+func renderTaskCommands(e *task.Executor, call taskfile.Call, depth int) ([]string, error) {
+	if depth > maxTaskDepth {
+		return nil, fmt.Errorf("task %s exceeds the maximum call depth of %d", call.Task, maxTaskDepth)
+	}
+
+	// Empty tasks have nothing to render.
+	if t, ok := e.Taskfile.Tasks[call.Task]; ok && t == nil {
+		return nil, nil
+	}
+
+	t, err := e.FastCompiledTask(call)
+	if err != nil {
+		return nil, err
+	}
+
+	commands := []string{}
+
+	for _, d := range t.Deps {
+		c, err := renderTaskCommands(e, taskfile.Call{Task: d.Task, Vars: d.Vars}, depth+1)
+		if err != nil {
+			return nil, err
+		}
+
+		commands = append(commands, c...)
+	}
+
+	for _, cmd := range t.Cmds {
+		if cmd.Task != "" {
+			c, err := renderTaskCommands(e, taskfile.Call{Task: cmd.Task, Vars: cmd.Vars}, depth+1)
+			if err != nil {
+				return nil, err
+			}
+
+			commands = append(commands, c...)
+			continue
+		}
+
+		if strings.TrimSpace(cmd.Cmd) != "" {
+			commands = append(commands, strings.TrimSpace(cmd.Cmd))
+		}
+	}
+
+	return commands, nil
+}
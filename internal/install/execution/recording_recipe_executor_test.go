@@ -0,0 +1,138 @@
+// +build unit
+
+package execution
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/newrelic/newrelic-cli/internal/install/types"
+)
+
+var planTestRecipe = types.Recipe{
+	Name:        "test-recipe",
+	DisplayName: "Test Recipe",
+	File: `
+---
+name: test-recipe
+inputVars:
+  - name: DB_PASSWORD
+    secret: true
+  - name: DB_USER
+install:
+  version: "3"
+  tasks:
+    default:
+      deps: [setup]
+      cmds:
+        - task: configure
+          vars:
+            TARGET: /etc/test
+    setup:
+      cmds:
+        - echo "setup"
+    configure:
+      cmds:
+        - echo "{{.DB_USER}}:{{.DB_PASSWORD}} > {{.TARGET}}"
+        - curl -H "Api-Key:{{.NEW_RELIC_LICENSE_KEY}}" https://example.com
+`,
+}
+
+func TestRecordingRecipeExecutor_interface(t *testing.T) {
+	var r RecipeExecutor = NewRecordingRecipeExecutor(NewMockRecipeExecutor())
+	require.NotNil(t, r)
+}
+
+func TestRecordingRecipeExecutor_RecordsRedactedPlan(t *testing.T) {
+	re := NewRecordingRecipeExecutor(NewMockRecipeExecutor())
+	vars := types.RecipeVars{
+		"DB_USER":               "admin",
+		"DB_PASSWORD":           "hunter2",
+		"NEW_RELIC_LICENSE_KEY": "abc123",
+	}
+
+	err := re.Execute(context.Background(), types.DiscoveryManifest{}, planTestRecipe, vars)
+	require.NoError(t, err)
+	require.Len(t, re.Recipes, 1)
+
+	planned := re.Recipes[0]
+	require.Equal(t, "test-recipe", planned.Name)
+	require.Equal(t, "admin", planned.Vars["DB_USER"])
+	require.Equal(t, redactedValue, planned.Vars["DB_PASSWORD"])
+	require.Equal(t, redactedValue, planned.Vars["NEW_RELIC_LICENSE_KEY"])
+	require.Equal(t, []string{
+		`echo "setup"`,
+		`echo "admin:******** > /etc/test"`,
+		`curl -H "Api-Key:********" https://example.com`,
+	}, planned.Commands)
+
+	// The vars handed to the executor must not be modified.
+	require.Equal(t, "hunter2", vars["DB_PASSWORD"])
+}
+
+func TestRecordingRecipeExecutor_EmptyTask(t *testing.T) {
+	re := NewRecordingRecipeExecutor(NewMockRecipeExecutor())
+	r := types.Recipe{
+		Name: "empty",
+		File: `
+---
+name: empty
+install:
+  version: "3"
+  tasks:
+    default:
+`,
+	}
+
+	err := re.Execute(context.Background(), types.DiscoveryManifest{}, r, types.RecipeVars{})
+	require.NoError(t, err)
+	require.Len(t, re.Recipes, 1)
+	require.Empty(t, re.Recipes[0].Commands)
+}
+
+func TestPlanStatusReporter_RendersJSON(t *testing.T) {
+	re := NewRecordingRecipeExecutor(NewMockRecipeExecutor())
+	err := re.Execute(context.Background(), types.DiscoveryManifest{}, planTestRecipe, types.RecipeVars{"DB_USER": "admin"})
+	require.NoError(t, err)
+
+	var b bytes.Buffer
+	r := NewPlanStatusReporter(re, PlanFormats.JSON)
+	r.writer = &b
+
+	status := &InstallStatus{DiscoveryManifest: types.DiscoveryManifest{Hostname: "test-host"}}
+	err = r.InstallComplete(status)
+	require.NoError(t, err)
+
+	var plan InstallPlan
+	err = json.Unmarshal(b.Bytes(), &plan)
+	require.NoError(t, err)
+	require.Equal(t, "test-host", plan.DiscoveryManifest.Hostname)
+	require.Len(t, plan.Recipes, 1)
+	require.Equal(t, "test-recipe", plan.Recipes[0].Name)
+}
+
+func TestPlanStatusReporter_RendersText(t *testing.T) {
+	re := NewRecordingRecipeExecutor(NewMockRecipeExecutor())
+	err := re.Execute(context.Background(), types.DiscoveryManifest{}, planTestRecipe, types.RecipeVars{"DB_USER": "admin"})
+	require.NoError(t, err)
+
+	var b bytes.Buffer
+	r := NewPlanStatusReporter(re, PlanFormats.TEXT)
+	r.writer = &b
+
+	err = r.InstallComplete(&InstallStatus{})
+	require.NoError(t, err)
+	require.Contains(t, b.String(), "1. Test Recipe (test-recipe)")
+	require.Contains(t, b.String(), "DB_USER=admin")
+	require.Contains(t, b.String(), `$ echo "setup"`)
+}
+
+func TestRenderInstallPlan_UnknownFormat(t *testing.T) {
+	var b bytes.Buffer
+	err := RenderInstallPlan(&b, InstallPlan{}, PlanFormat("xml"))
+	require.Error(t, err)
+}
@@ -0,0 +1,254 @@
+package execution
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/newrelic/newrelic-cli/internal/install/types"
+)
+
+// ReportFormat is the format an install report is written in.
+type ReportFormat string
+
+var ReportFormats = struct {
+	JSON  ReportFormat
+	JUNIT ReportFormat
+}{
+	JSON:  "json",
+	JUNIT: "junit",
+}
+
+const (
+	junitTestSuiteName   = "newrelic-install"
+	junitInstallTestName = "install"
+)
+
+// ReportStatusReporter is an implementation of the StatusSubscriber interface
+// that writes the final install status to a file, for tools that gate on the
+// outcome of an install.
+type ReportStatusReporter struct {
+	path   string
+	format ReportFormat
+}
+
+// NewReportStatusReporter returns a new instance of ReportStatusReporter.
+func NewReportStatusReporter(path string, format ReportFormat) *ReportStatusReporter {
+	r := ReportStatusReporter{
+		path:   path,
+		format: format,
+	}
+
+	return &r
+}
+
+func (r ReportStatusReporter) RecipeFailed(status *InstallStatus, event RecipeStatusEvent) error {
+	return nil
+}
+
+func (r ReportStatusReporter) RecipeInstalling(status *InstallStatus, event RecipeStatusEvent) error {
+	return nil
+}
+
+func (r ReportStatusReporter) RecipeInstalled(status *InstallStatus, event RecipeStatusEvent) error {
+	return nil
+}
+
+func (r ReportStatusReporter) RecipeSkipped(status *InstallStatus, event RecipeStatusEvent) error {
+	return nil
+}
+
+func (r ReportStatusReporter) RecipeUninstalled(status *InstallStatus, event RecipeStatusEvent) error {
+	return nil
+}
+
+func (r ReportStatusReporter) RecipeRolledBack(status *InstallStatus, event RecipeStatusEvent) error {
+	return nil
+}
+
+func (r ReportStatusReporter) RecipeRecommended(status *InstallStatus, event RecipeStatusEvent) error {
+	return nil
+}
+
+func (r ReportStatusReporter) RecipesAvailable(status *InstallStatus, recipes []types.Recipe) error {
+	return nil
+}
+
+func (r ReportStatusReporter) RecipesSelected(status *InstallStatus, recipes []types.Recipe) error {
+	return nil
+}
+
+func (r ReportStatusReporter) RecipeAvailable(status *InstallStatus, recipe types.Recipe) error {
+	return nil
+}
+
+func (r ReportStatusReporter) InstallComplete(status *InstallStatus) error {
+	return r.writeReport(status)
+}
+
+func (r ReportStatusReporter) InstallCanceled(status *InstallStatus) error {
+	return r.writeReport(status)
+}
+
+func (r ReportStatusReporter) DiscoveryComplete(status *InstallStatus, dm types.DiscoveryManifest) error {
+	return nil
+}
+
+func (r ReportStatusReporter) writeReport(status *InstallStatus) error {
+	var out []byte
+	var err error
+
+	switch r.format {
+	case ReportFormats.JUNIT:
+		out, err = RenderJUnitReport(status)
+	default:
+		out, err = json.MarshalIndent(status, "", "  ")
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if dir := filepath.Dir(r.path); dir != "" {
+		if err = os.MkdirAll(dir, 0750); err != nil {
+			return err
+		}
+	}
+
+	return ioutil.WriteFile(r.path, out, 0640)
+}
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Skipped    int             `xml:"skipped,attr"`
+	Time       string          `xml:"time,attr"`
+	Properties []junitProperty `xml:"properties>property"`
+	TestCases  []junitTestCase `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Details string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr,omitempty"`
+}
+
+// JUnitReportSuite is an install rendered as a JUnit test suite, such as the
+// install on one host of a fleet.
+type JUnitReportSuite struct {
+	Name string
+	// Status is the final status of the install, if one was reported.
+	Status *InstallStatus
+	// Err is the error the install ended with, reported as a failed test case.
+	Err error
+}
+
+// RenderJUnitReport renders an install status as JUnit XML, with one test case
+// per recipe.  Failed recipes are failures; recipes that were not installed,
+// such as skipped, canceled or recommended recipes, are skipped.
+func RenderJUnitReport(status *InstallStatus) ([]byte, error) {
+	return RenderJUnitReportSuites([]JUnitReportSuite{{Name: junitTestSuiteName, Status: status}})
+}
+
+// RenderJUnitReportSuites renders installs as JUnit XML, with one test suite
+// per install, as RenderJUnitReport does.
+func RenderJUnitReportSuites(suites []JUnitReportSuite) ([]byte, error) {
+	report := junitTestSuites{}
+	for _, s := range suites {
+		report.Suites = append(report.Suites, newJUnitTestSuite(s))
+	}
+
+	out, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), out...), nil
+}
+
+func newJUnitTestSuite(rs JUnitReportSuite) junitTestSuite {
+	status := rs.Status
+	if status == nil {
+		status = &InstallStatus{}
+	}
+
+	suite := junitTestSuite{
+		Name: rs.Name,
+		Properties: []junitProperty{
+			{Name: "documentID", Value: status.DocumentID},
+			{Name: "cliVersion", Value: status.CLIVersion},
+			{Name: "hostname", Value: status.DiscoveryManifest.Hostname},
+		},
+	}
+
+	var total int64
+	for _, s := range status.Statuses {
+		tc := junitTestCase{
+			Name:      s.Name,
+			ClassName: rs.Name,
+			Time:      junitSeconds(s.ValidationDurationMilliseconds),
+		}
+		total += s.ValidationDurationMilliseconds
+
+		switch s.Status {
+		case RecipeStatusTypes.INSTALLED, RecipeStatusTypes.UNINSTALLED:
+		case RecipeStatusTypes.FAILED:
+			suite.Failures++
+			tc.Failure = &junitFailure{
+				Message: s.Error.Message,
+				Details: s.Error.Details,
+			}
+		default:
+			suite.Skipped++
+			tc.Skipped = &junitSkipped{
+				Message: string(s.Status),
+			}
+		}
+
+		suite.TestCases = append(suite.TestCases, tc)
+	}
+
+	if rs.Err != nil {
+		suite.Failures++
+		suite.TestCases = append(suite.TestCases, junitTestCase{
+			Name:      junitInstallTestName,
+			ClassName: rs.Name,
+			Time:      junitSeconds(0),
+			Failure:   &junitFailure{Message: rs.Err.Error()},
+		})
+	}
+
+	suite.Tests = len(suite.TestCases)
+	suite.Time = junitSeconds(total)
+
+	return suite
+}
+
+func junitSeconds(milliseconds int64) string {
+	return fmt.Sprintf("%.3f", float64(milliseconds)/1000)
+}
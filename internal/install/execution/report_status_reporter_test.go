@@ -0,0 +1,105 @@
+// +build unit
+
+package execution
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/newrelic/newrelic-cli/internal/install/types"
+)
+
+func TestReportStatusReporter_interface(t *testing.T) {
+	var r StatusSubscriber = NewReportStatusReporter("", ReportFormats.JSON)
+	require.NotNil(t, r)
+}
+
+func newReportTestStatus(r StatusSubscriber) *InstallStatus {
+	s := NewInstallStatus([]StatusSubscriber{r})
+
+	installed := types.Recipe{Name: "installed-recipe"}
+	failed := types.Recipe{Name: "failed-recipe"}
+	skipped := types.Recipe{Name: "skipped-recipe"}
+
+	s.RecipesAvailable([]types.Recipe{installed, failed, skipped})
+	s.RecipeInstalled(RecipeStatusEvent{Recipe: installed, EntityGUID: "testGUID", ValidationDurationMilliseconds: 1500})
+	s.RecipeFailed(RecipeStatusEvent{Recipe: failed, Msg: "validation failed"})
+	s.RecipeSkipped(RecipeStatusEvent{Recipe: skipped})
+
+	return s
+}
+
+func TestReportStatusReporter_JSON(t *testing.T) {
+	dir, err := ioutil.TempDir("", "report")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "reports", "install.json")
+	s := newReportTestStatus(NewReportStatusReporter(path, ReportFormats.JSON))
+	s.InstallComplete(errors.New("one or more recipes failed"))
+
+	out, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+
+	var report InstallStatus
+	require.NoError(t, json.Unmarshal(out, &report))
+	require.True(t, report.Complete)
+	require.Equal(t, s.DocumentID, report.DocumentID)
+	require.Len(t, report.RecipesInstalled, 1)
+	require.Len(t, report.RecipesFailed, 1)
+	require.Len(t, report.RecipesSkipped, 1)
+}
+
+func TestReportStatusReporter_JUnit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "report")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "install.xml")
+	s := newReportTestStatus(NewReportStatusReporter(path, ReportFormats.JUNIT))
+	s.InstallComplete(nil)
+
+	out, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+
+	var report junitTestSuites
+	require.NoError(t, xml.Unmarshal(out, &report))
+	require.Len(t, report.Suites, 1)
+
+	suite := report.Suites[0]
+	require.Equal(t, 3, suite.Tests)
+	require.Equal(t, 1, suite.Failures)
+	require.Equal(t, 1, suite.Skipped)
+
+	cases := map[string]junitTestCase{}
+	for _, tc := range suite.TestCases {
+		cases[tc.Name] = tc
+	}
+
+	require.Nil(t, cases["installed-recipe"].Failure)
+	require.Nil(t, cases["installed-recipe"].Skipped)
+	require.Equal(t, "1.500", cases["installed-recipe"].Time)
+	require.NotNil(t, cases["failed-recipe"].Failure)
+	require.Equal(t, "validation failed", cases["failed-recipe"].Failure.Message)
+	require.NotNil(t, cases["skipped-recipe"].Skipped)
+}
+
+func TestReportStatusReporter_Canceled(t *testing.T) {
+	dir, err := ioutil.TempDir("", "report")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "install.json")
+	s := newReportTestStatus(NewReportStatusReporter(path, ReportFormats.JSON))
+	s.InstallCanceled()
+
+	_, err = os.Stat(path)
+	require.NoError(t, err)
+}
@@ -12,7 +12,9 @@ type StatusSubscriber interface {
 	RecipeInstalled(status *InstallStatus, event RecipeStatusEvent) error
 	RecipeInstalling(status *InstallStatus, event RecipeStatusEvent) error
 	RecipeRecommended(status *InstallStatus, event RecipeStatusEvent) error
+	RecipeRolledBack(status *InstallStatus, event RecipeStatusEvent) error
 	RecipeSkipped(status *InstallStatus, event RecipeStatusEvent) error
+	RecipeUninstalled(status *InstallStatus, event RecipeStatusEvent) error
 	RecipesAvailable(status *InstallStatus, recipes []types.Recipe) error
 	RecipesSelected(status *InstallStatus, recipes []types.Recipe) error
 }
@@ -21,6 +23,7 @@ type StatusSubscriber interface {
 type RecipeStatusEvent struct {
 	Recipe                         types.Recipe
 	Msg                            string
+	Details                        string
 	EntityGUID                     string
 	ValidationDurationMilliseconds int64
 }
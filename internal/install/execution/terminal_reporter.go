@@ -3,6 +3,7 @@ package execution
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 
@@ -38,6 +39,14 @@ func (r TerminalStatusReporter) RecipeSkipped(status *InstallStatus, event Recip
 	return nil
 }
 
+func (r TerminalStatusReporter) RecipeUninstalled(status *InstallStatus, event RecipeStatusEvent) error {
+	return nil
+}
+
+func (r TerminalStatusReporter) RecipeRolledBack(status *InstallStatus, event RecipeStatusEvent) error {
+	return nil
+}
+
 func (r TerminalStatusReporter) RecipeRecommended(status *InstallStatus, event RecipeStatusEvent) error {
 	return nil
 }
@@ -77,10 +86,18 @@ func (r TerminalStatusReporter) InstallComplete(status *InstallStatus) error {
 		return nil
 	}
 
+	if status.IsUninstall() {
+		return r.uninstallComplete(status)
+	}
+
 	if status.hasAnyRecipeStatus(RecipeStatusTypes.FAILED) {
 		fmt.Printf("  One or more installations failed.  Check the install log for more details: %s\n", status.LogFilePath)
+		r.printFailureDetails(status)
 	}
 
+	r.printRollbackSummary(status)
+	r.printValidationSummary(status)
+
 	recs := status.recommendations()
 
 	if len(recs) > 0 {
@@ -109,6 +126,94 @@ func (r TerminalStatusReporter) InstallComplete(status *InstallStatus) error {
 	return nil
 }
 
+// printFailureDetails prints the output of the failed tasks of each recipe.
+func (r TerminalStatusReporter) printFailureDetails(status *InstallStatus) {
+	for _, s := range status.Statuses {
+		if s.Status != RecipeStatusTypes.FAILED || s.Error.Details == "" {
+			continue
+		}
+
+		name := s.DisplayName
+		if name == "" {
+			name = s.Name
+		}
+
+		fmt.Printf("  %s failed:\n", name)
+		for _, l := range strings.Split(s.Error.Details, "\n") {
+			fmt.Printf("    %s\n", l)
+		}
+	}
+}
+
+// printRollbackSummary lists the outcome of each failed recipe's rollback.
+func (r TerminalStatusReporter) printRollbackSummary(status *InstallStatus) {
+	for _, s := range status.Statuses {
+		if s.Rollback == nil {
+			continue
+		}
+
+		name := s.DisplayName
+		if name == "" {
+			name = s.Name
+		}
+
+		if s.Rollback.Status == RollbackStatusTypes.SUCCEEDED {
+			fmt.Printf("  %s was rolled back\n", name)
+		} else {
+			fmt.Printf("  %s could not be rolled back: %s\n", name, s.Rollback.Error.Message)
+		}
+	}
+}
+
+// printValidationSummary lists the outcome of each recipe whose data was
+// validated.
+func (r TerminalStatusReporter) printValidationSummary(status *InstallStatus) {
+	validated := []*RecipeStatus{}
+	for _, s := range status.Statuses {
+		if s.ValidationDurationMilliseconds > 0 && (s.Status == RecipeStatusTypes.INSTALLED || s.Status == RecipeStatusTypes.FAILED) {
+			validated = append(validated, s)
+		}
+	}
+
+	if len(validated) == 0 {
+		return
+	}
+
+	fmt.Println("  Data validation:")
+	for _, s := range validated {
+		name := s.DisplayName
+		if name == "" {
+			name = s.Name
+		}
+
+		d := (time.Duration(s.ValidationDurationMilliseconds) * time.Millisecond).Round(time.Second)
+
+		if s.Status == RecipeStatusTypes.INSTALLED {
+			fmt.Printf("    %s: data received after %s\n", name, d)
+		} else {
+			fmt.Printf("    %s: no data received after %s\n", name, d)
+		}
+	}
+}
+
+func (r TerminalStatusReporter) uninstallComplete(status *InstallStatus) error {
+	if status.hasAnyRecipeStatus(RecipeStatusTypes.FAILED) {
+		fmt.Printf("  One or more uninstalls failed.  Check the install log for more details: %s\n", status.LogFilePath)
+		r.printFailureDetails(status)
+	}
+
+	for _, s := range status.Statuses {
+		if s.Status == RecipeStatusTypes.UNINSTALLED {
+			fmt.Printf("  %s uninstalled\n", s.DisplayName)
+		}
+	}
+
+	fmt.Println("  New Relic uninstall complete!")
+	fmt.Println()
+
+	return nil
+}
+
 func (r *TerminalStatusReporter) getSuccessLink(status *InstallStatus) string {
 	if status.hasAnyRecipeStatus(RecipeStatusTypes.INSTALLED) {
 		switch t := status.successLinkConfig.Type; {
@@ -0,0 +1,180 @@
+package fleet
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/newrelic/newrelic-cli/internal/install/execution"
+)
+
+// DefaultConcurrency is how many hosts are installed at once.
+const DefaultConcurrency = 5
+
+// HostResult is the outcome of the install on a host.
+type HostResult struct {
+	Host     Host
+	Status   *execution.InstallStatus
+	Err      error
+	Duration time.Duration
+}
+
+// Failed returns true when the install on the host failed, or any of its
+// recipes failed.
+func (r HostResult) Failed() bool {
+	return r.Err != nil || (r.Status != nil && r.Status.HasFailedRecipes)
+}
+
+// Install runs an install on each host, with at most concurrency hosts at
+// once.  The output of each host is streamed to out, one line at a time and
+// prefixed with the host's name.  Results are returned in the order of hosts.
+func Install(ctx context.Context, hosts []Host, runner HostRunner, install RemoteInstall, concurrency int, out io.Writer) []HostResult {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	results := make([]HostResult, len(hosts))
+	sem := make(chan struct{}, concurrency)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for i, h := range hosts {
+		wg.Add(1)
+		go func(i int, h Host) {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			w := newPrefixWriter(out, &mu, fmt.Sprintf("[%s] ", h.Name))
+			start := time.Now()
+
+			status, err := runner.Run(ctx, h, install, w)
+			w.Flush()
+
+			results[i] = HostResult{
+				Host:     h,
+				Status:   status,
+				Err:      err,
+				Duration: time.Since(start),
+			}
+		}(i, h)
+	}
+
+	wg.Wait()
+
+	return results
+}
+
+// RenderSummary writes a table of the outcome of the install on each host.
+func RenderSummary(w io.Writer, results []HostResult) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "HOST\tRESULT\tINSTALLED\tFAILED\tSKIPPED\tDURATION\tDETAILS")
+
+	for _, r := range results {
+		result := "success"
+		if r.Failed() {
+			result = "failed"
+		}
+
+		var installed, failed, skipped []string
+		details := ""
+
+		if r.Status != nil {
+			for _, s := range r.Status.Statuses {
+				switch s.Status {
+				case execution.RecipeStatusTypes.INSTALLED:
+					installed = append(installed, s.Name)
+				case execution.RecipeStatusTypes.FAILED:
+					failed = append(failed, s.Name)
+				case execution.RecipeStatusTypes.SKIPPED, execution.RecipeStatusTypes.CANCELED:
+					skipped = append(skipped, s.Name)
+				}
+			}
+
+			details = r.Status.Error.Message
+		}
+
+		if r.Err != nil {
+			details = r.Err.Error()
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			r.Host.Name,
+			result,
+			listOrDash(installed),
+			listOrDash(failed),
+			listOrDash(skipped),
+			r.Duration.Round(time.Second),
+			details,
+		)
+	}
+
+	return tw.Flush()
+}
+
+func listOrDash(values []string) string {
+	if len(values) == 0 {
+		return "-"
+	}
+
+	return strings.Join(values, ",")
+}
+
+// prefixWriter writes complete lines to a shared writer, prefixing each line.
+// Output from several hosts is interleaved a line at a time.
+type prefixWriter struct {
+	w      io.Writer
+	mu     *sync.Mutex
+	prefix string
+	buf    bytes.Buffer
+}
+
+func newPrefixWriter(w io.Writer, mu *sync.Mutex, prefix string) *prefixWriter {
+	p := prefixWriter{
+		w:      w,
+		mu:     mu,
+		prefix: prefix,
+	}
+
+	return &p
+}
+
+func (p *prefixWriter) Write(b []byte) (int, error) {
+	p.buf.Write(b)
+
+	for {
+		i := bytes.IndexByte(p.buf.Bytes(), '\n')
+		if i < 0 {
+			break
+		}
+
+		line := p.buf.Next(i + 1)
+		if err := p.writeLine(line); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(b), nil
+}
+
+// Flush writes a final line that did not end with a newline.
+func (p *prefixWriter) Flush() {
+	if p.buf.Len() > 0 {
+		_ = p.writeLine(append(p.buf.Bytes(), '\n'))
+		p.buf.Reset()
+	}
+}
+
+func (p *prefixWriter) writeLine(line []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	_, err := fmt.Fprintf(p.w, "%s%s", p.prefix, line)
+	return err
+}
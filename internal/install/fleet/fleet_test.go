@@ -0,0 +1,92 @@
+// +build unit
+
+package fleet
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/newrelic/newrelic-cli/internal/install/execution"
+)
+
+type mockHostRunner struct {
+	mu      sync.Mutex
+	running int
+	max     int
+	results map[string]*execution.InstallStatus
+	errs    map[string]error
+}
+
+func (r *mockHostRunner) Run(ctx context.Context, h Host, install RemoteInstall, out io.Writer) (*execution.InstallStatus, error) {
+	r.mu.Lock()
+	r.running++
+	if r.running > r.max {
+		r.max = r.running
+	}
+	r.mu.Unlock()
+
+	fmt.Fprintf(out, "installing on %s\npartial", h.Address)
+
+	r.mu.Lock()
+	r.running--
+	r.mu.Unlock()
+
+	return r.results[h.Name], r.errs[h.Name]
+}
+
+func TestInstall(t *testing.T) {
+	hosts := []Host{}
+	for i := 1; i <= 6; i++ {
+		hosts = append(hosts, Host{Name: fmt.Sprintf("web-%d", i), Address: fmt.Sprintf("10.0.0.%d", i)})
+	}
+
+	installed := &execution.InstallStatus{
+		Statuses: []*execution.RecipeStatus{
+			{Name: "infrastructure-agent-installer", Status: execution.RecipeStatusTypes.INSTALLED},
+			{Name: "logs-integration", Status: execution.RecipeStatusTypes.SKIPPED},
+		},
+	}
+	failed := &execution.InstallStatus{
+		HasFailedRecipes: true,
+		Error:            execution.StatusError{Message: "validation timed out"},
+		Statuses: []*execution.RecipeStatus{
+			{Name: "infrastructure-agent-installer", Status: execution.RecipeStatusTypes.FAILED},
+		},
+	}
+
+	r := &mockHostRunner{
+		results: map[string]*execution.InstallStatus{"web-1": installed, "web-2": failed},
+		errs:    map[string]error{"web-3": errors.New("could not connect to 10.0.0.3:22")},
+	}
+
+	var out bytes.Buffer
+	results := Install(context.Background(), hosts, r, RemoteInstall{}, 2, &out)
+	require.Len(t, results, 6)
+	require.LessOrEqual(t, r.max, 2)
+
+	require.Equal(t, "web-1", results[0].Host.Name)
+	require.False(t, results[0].Failed())
+	require.True(t, results[1].Failed())
+	require.True(t, results[2].Failed())
+
+	require.Contains(t, out.String(), "[web-1] installing on 10.0.0.1\n[web-1] partial\n")
+	require.Equal(t, 12, strings.Count(out.String(), "\n"))
+
+	var summary bytes.Buffer
+	require.NoError(t, RenderSummary(&summary, results[:3]))
+
+	lines := strings.Split(strings.TrimSpace(summary.String()), "\n")
+	require.Len(t, lines, 4)
+	require.Regexp(t, `^HOST\s+RESULT\s+INSTALLED\s+FAILED\s+SKIPPED\s+DURATION\s+DETAILS$`, lines[0])
+	require.Regexp(t, `^web-1\s+success\s+infrastructure-agent-installer\s+-\s+logs-integration\s+0s\s*$`, lines[1])
+	require.Regexp(t, `^web-2\s+failed\s+-\s+infrastructure-agent-installer\s+-\s+0s\s+validation timed out$`, lines[2])
+	require.Regexp(t, `^web-3\s+failed\s+-\s+-\s+-\s+0s\s+could not connect to 10.0.0.3:22$`, lines[3])
+}
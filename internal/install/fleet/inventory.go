@@ -0,0 +1,155 @@
+package fleet
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"strconv"
+
+	"github.com/mitchellh/go-homedir"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	defaultSSHPort        = 22
+	defaultKnownHostsFile = "~/.ssh/known_hosts"
+	defaultCLIPath        = "newrelic"
+)
+
+// Inventory lists the hosts of a fleet install, for example:
+//
+//	defaults:
+//	  user: ubuntu
+//	  identityFile: ~/.ssh/fleet
+//	  sudo: true
+//	hosts:
+//	  - name: web-1
+//	    address: 10.0.0.11
+//	  - address: 10.0.0.12
+//	    port: 2222
+//
+// Fields a host leaves empty are taken from the defaults.
+type Inventory struct {
+	// KnownHostsFile holds the keys host keys are verified against.  It is
+	// ~/.ssh/known_hosts when empty.
+	KnownHostsFile string `yaml:"knownHostsFile"`
+	Defaults       Host   `yaml:"defaults"`
+	Hosts          []Host `yaml:"hosts"`
+}
+
+// Host is a host of a fleet install and how to reach it over SSH.
+type Host struct {
+	// Name identifies the host in output.  It is the address when empty.
+	Name    string `yaml:"name"`
+	Address string `yaml:"address"`
+	Port    int    `yaml:"port"`
+	User    string `yaml:"user"`
+	// IdentityFile is a private key to authenticate with, in addition to the
+	// keys of a running SSH agent.
+	IdentityFile string `yaml:"identityFile"`
+	// CLIPath is the New Relic CLI on the host.  It is looked up in the PATH of
+	// the SSH session when empty.
+	CLIPath string `yaml:"cliPath"`
+	// Sudo runs the install as root with sudo, which must not prompt for a
+	// password.  A host uses sudo when either it or the defaults set it.
+	Sudo bool `yaml:"sudo"`
+}
+
+// Addr returns the host and port to connect to.
+func (h Host) Addr() string {
+	return net.JoinHostPort(h.Address, strconv.Itoa(h.Port))
+}
+
+// LoadInventory reads and validates an inventory file.
+func LoadInventory(path string) (*Inventory, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read inventory %s: %s", path, err)
+	}
+
+	return ParseInventory(b)
+}
+
+// ParseInventory parses and validates an inventory, applying the defaults to
+// its hosts.
+func ParseInventory(b []byte) (*Inventory, error) {
+	var inv Inventory
+	if err := yaml.UnmarshalStrict(b, &inv); err != nil {
+		return nil, fmt.Errorf("could not parse inventory: %s", err)
+	}
+
+	if len(inv.Hosts) == 0 {
+		return nil, fmt.Errorf("inventory has no hosts")
+	}
+
+	if inv.KnownHostsFile == "" {
+		inv.KnownHostsFile = defaultKnownHostsFile
+	}
+
+	knownHostsFile, err := homedir.Expand(inv.KnownHostsFile)
+	if err != nil {
+		return nil, err
+	}
+	inv.KnownHostsFile = knownHostsFile
+
+	names := map[string]bool{}
+	for i := range inv.Hosts {
+		h := &inv.Hosts[i]
+		inv.applyDefaults(h)
+
+		if h.Address == "" {
+			return nil, fmt.Errorf("host %d in inventory has no address", i+1)
+		}
+
+		if h.User == "" {
+			return nil, fmt.Errorf("host %s in inventory has no user", h.Name)
+		}
+
+		if h.IdentityFile != "" {
+			if h.IdentityFile, err = homedir.Expand(h.IdentityFile); err != nil {
+				return nil, err
+			}
+		}
+
+		if names[h.Name] {
+			return nil, fmt.Errorf("host %s is listed more than once in inventory", h.Name)
+		}
+		names[h.Name] = true
+	}
+
+	return &inv, nil
+}
+
+func (inv *Inventory) applyDefaults(h *Host) {
+	d := inv.Defaults
+
+	if h.Port == 0 {
+		h.Port = d.Port
+	}
+
+	if h.Port == 0 {
+		h.Port = defaultSSHPort
+	}
+
+	if h.User == "" {
+		h.User = d.User
+	}
+
+	if h.IdentityFile == "" {
+		h.IdentityFile = d.IdentityFile
+	}
+
+	if h.CLIPath == "" {
+		h.CLIPath = d.CLIPath
+	}
+
+	if h.CLIPath == "" {
+		h.CLIPath = defaultCLIPath
+	}
+
+	h.Sudo = h.Sudo || d.Sudo
+
+	if h.Name == "" {
+		h.Name = h.Address
+	}
+}
@@ -0,0 +1,60 @@
+// +build unit
+
+package fleet
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseInventory(t *testing.T) {
+	inv, err := ParseInventory([]byte(`
+knownHostsFile: /etc/ssh/ssh_known_hosts
+defaults:
+  user: ubuntu
+  identityFile: /keys/fleet
+  sudo: true
+hosts:
+  - name: web-1
+    address: 10.0.0.11
+  - address: 10.0.0.12
+    port: 2222
+    user: admin
+    cliPath: /opt/newrelic/bin/newrelic
+`))
+	require.NoError(t, err)
+	require.Equal(t, "/etc/ssh/ssh_known_hosts", inv.KnownHostsFile)
+	require.Len(t, inv.Hosts, 2)
+
+	require.Equal(t, Host{
+		Name:         "web-1",
+		Address:      "10.0.0.11",
+		Port:         22,
+		User:         "ubuntu",
+		IdentityFile: "/keys/fleet",
+		CLIPath:      "newrelic",
+		Sudo:         true,
+	}, inv.Hosts[0])
+	require.Equal(t, "10.0.0.11:22", inv.Hosts[0].Addr())
+
+	require.Equal(t, "10.0.0.12", inv.Hosts[1].Name)
+	require.Equal(t, "admin", inv.Hosts[1].User)
+	require.Equal(t, "/opt/newrelic/bin/newrelic", inv.Hosts[1].CLIPath)
+	require.Equal(t, "10.0.0.12:2222", inv.Hosts[1].Addr())
+}
+
+func TestParseInventory_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"no hosts":      "defaults:\n  user: ubuntu\n",
+		"no address":    "hosts:\n  - name: web-1\n    user: ubuntu\n",
+		"no user":       "hosts:\n  - address: 10.0.0.11\n",
+		"duplicate":     "defaults:\n  user: ubuntu\nhosts:\n  - address: 10.0.0.11\n  - address: 10.0.0.11\n",
+		"unknown field": "hosts:\n  - address: 10.0.0.11\n    user: ubuntu\n    password: secret\n",
+	}
+
+	for name, inventory := range tests {
+		_, err := ParseInventory([]byte(inventory))
+		require.Error(t, err, name)
+	}
+}
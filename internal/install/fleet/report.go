@@ -0,0 +1,78 @@
+package fleet
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/newrelic/newrelic-cli/internal/install/execution"
+)
+
+// HostReport is the outcome of the install on a host, as written to the
+// report of a fleet install.
+type HostReport struct {
+	Host                 string                   `json:"host"`
+	Address              string                   `json:"address"`
+	Failed               bool                     `json:"failed"`
+	Error                string                   `json:"error,omitempty"`
+	DurationMilliseconds int64                    `json:"durationMilliseconds"`
+	Status               *execution.InstallStatus `json:"status,omitempty"`
+}
+
+// WriteReport writes the outcome of the install on each host to a file, for
+// provisioning pipelines to gate on.  JSON reports are a list of HostReport;
+// JUnit reports have one test suite per host.
+func WriteReport(path string, format execution.ReportFormat, results []HostResult) error {
+	var out []byte
+	var err error
+
+	switch format {
+	case execution.ReportFormats.JUNIT:
+		suites := []execution.JUnitReportSuite{}
+		for _, r := range results {
+			suites = append(suites, execution.JUnitReportSuite{
+				Name:   r.Host.Name,
+				Status: r.Status,
+				Err:    r.Err,
+			})
+		}
+
+		out, err = execution.RenderJUnitReportSuites(suites)
+	default:
+		reports := []HostReport{}
+		for _, r := range results {
+			reports = append(reports, newHostReport(r))
+		}
+
+		out, err = json.MarshalIndent(reports, "", "  ")
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if dir := filepath.Dir(path); dir != "" {
+		if err = os.MkdirAll(dir, 0750); err != nil {
+			return err
+		}
+	}
+
+	return ioutil.WriteFile(path, out, 0640)
+}
+
+func newHostReport(r HostResult) HostReport {
+	hr := HostReport{
+		Host:                 r.Host.Name,
+		Address:              r.Host.Address,
+		Failed:               r.Failed(),
+		DurationMilliseconds: r.Duration.Milliseconds(),
+		Status:               r.Status,
+	}
+
+	if r.Err != nil {
+		hr.Error = r.Err.Error()
+	}
+
+	return hr
+}
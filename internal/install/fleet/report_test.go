@@ -0,0 +1,76 @@
+// +build unit
+
+package fleet
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/newrelic/newrelic-cli/internal/install/execution"
+)
+
+func reportTestResults() []HostResult {
+	return []HostResult{
+		{
+			Host: Host{Name: "web-1", Address: "10.0.0.1"},
+			Status: &execution.InstallStatus{
+				Statuses: []*execution.RecipeStatus{
+					{Name: "infrastructure-agent-installer", Status: execution.RecipeStatusTypes.INSTALLED},
+				},
+			},
+			Duration: 2 * time.Second,
+		},
+		{
+			Host:     Host{Name: "web-2", Address: "10.0.0.2"},
+			Err:      errors.New("could not connect"),
+			Duration: time.Second,
+		},
+	}
+}
+
+func TestWriteReport_JSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reports", "fleet.json")
+
+	err := WriteReport(path, execution.ReportFormats.JSON, reportTestResults())
+	require.NoError(t, err)
+
+	out, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+
+	var reports []HostReport
+	require.NoError(t, json.Unmarshal(out, &reports))
+	require.Len(t, reports, 2)
+
+	require.Equal(t, "web-1", reports[0].Host)
+	require.False(t, reports[0].Failed)
+	require.Equal(t, int64(2000), reports[0].DurationMilliseconds)
+	require.Len(t, reports[0].Status.Statuses, 1)
+
+	require.Equal(t, "web-2", reports[1].Host)
+	require.True(t, reports[1].Failed)
+	require.Equal(t, "could not connect", reports[1].Error)
+	require.Nil(t, reports[1].Status)
+}
+
+func TestWriteReport_JUnit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fleet.xml")
+
+	err := WriteReport(path, execution.ReportFormats.JUNIT, reportTestResults())
+	require.NoError(t, err)
+
+	out, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+
+	report := string(out)
+	require.Equal(t, 2, strings.Count(report, "<testsuite "))
+	require.Contains(t, report, `<testsuite name="web-1" tests="1" failures="0"`)
+	require.Contains(t, report, `<testsuite name="web-2" tests="1" failures="1"`)
+	require.Contains(t, report, `<failure message="could not connect">`)
+}
@@ -0,0 +1,300 @@
+package fleet
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	"github.com/newrelic/newrelic-cli/internal/install/execution"
+)
+
+const (
+	defaultDialTimeout = 30 * time.Second
+
+	remoteEnvFile    = "env"
+	remoteVarsFile   = "vars.yml"
+	remoteReportFile = "report.json"
+)
+
+// RemoteInstall is the install run on each host of a fleet.
+type RemoteInstall struct {
+	// Args are the flags passed to the install command.
+	Args []string
+	// Env is the environment of the install, such as the credentials the CLI
+	// initializes its default profile from.  It is written to a file only the
+	// SSH user can read, rather than passed on the command line.
+	Env map[string]string
+	// Answers is the content of a vars file of recipe input var values shared
+	// by every host.
+	Answers []byte
+}
+
+// HostRunner runs an install on a host and returns its final install status.
+// The output of the install is written to out.
+type HostRunner interface {
+	Run(ctx context.Context, h Host, install RemoteInstall, out io.Writer) (*execution.InstallStatus, error)
+}
+
+// SSHHostRunner is an implementation of the HostRunner interface that runs the
+// New Relic CLI installed on a host over SSH.  The install runs with the
+// --report flag, and the report is read back as the host's install status.
+type SSHHostRunner struct {
+	DialTimeout     time.Duration
+	hostKeyCallback ssh.HostKeyCallback
+	agent           agent.Agent
+	agentConn       net.Conn
+}
+
+// NewSSHHostRunner returns a new instance of SSHHostRunner that verifies host
+// keys against a known_hosts file.  The connection to a running SSH agent is
+// shared by every host, and is closed by Close.
+func NewSSHHostRunner(knownHostsFile string) (*SSHHostRunner, error) {
+	callback, err := knownhosts.New(knownHostsFile)
+	if err != nil {
+		return nil, fmt.Errorf("could not read known hosts: %s", err)
+	}
+
+	r := SSHHostRunner{
+		DialTimeout:     defaultDialTimeout,
+		hostKeyCallback: callback,
+	}
+
+	if sock := os.Getenv("SSH_AUTH_SOCK"); sock != "" {
+		conn, err := net.Dial("unix", sock)
+		if err != nil {
+			log.Debugf("not using the SSH agent: %s", err)
+		} else {
+			r.agent = agent.NewClient(conn)
+			r.agentConn = conn
+		}
+	}
+
+	return &r, nil
+}
+
+// Close closes the connection to the SSH agent.
+func (r *SSHHostRunner) Close() error {
+	if r.agentConn == nil {
+		return nil
+	}
+
+	err := r.agentConn.Close()
+	r.agent = nil
+	r.agentConn = nil
+
+	return err
+}
+
+func (r *SSHHostRunner) Run(ctx context.Context, h Host, install RemoteInstall, out io.Writer) (*execution.InstallStatus, error) {
+	client, err := r.dial(ctx, h)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	// Closing the connection ends any command still running when the install
+	// is canceled.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			client.Close()
+		case <-done:
+		}
+	}()
+
+	var stdout bytes.Buffer
+	if err = runCommand(client, "mktemp -d", nil, &stdout, ioutil.Discard); err != nil {
+		return nil, fmt.Errorf("could not create a temporary directory: %s", err)
+	}
+
+	dir := strings.TrimSpace(stdout.String())
+	defer func() {
+		if err := runCommand(client, h.command("rm -rf "+shellQuote(dir)), nil, ioutil.Discard, ioutil.Discard); err != nil {
+			log.Debugf("could not remove %s on %s: %s", dir, h.Name, err)
+		}
+	}()
+
+	if err = upload(client, dir+"/"+remoteEnvFile, envFile(install.Env)); err != nil {
+		return nil, fmt.Errorf("could not upload the install environment: %s", err)
+	}
+
+	args := append([]string{"install", "--assumeYes", "--report", dir + "/" + remoteReportFile}, install.Args...)
+	if len(install.Answers) > 0 {
+		if err = upload(client, dir+"/"+remoteVarsFile, install.Answers); err != nil {
+			return nil, fmt.Errorf("could not upload the vars file: %s", err)
+		}
+
+		args = append(args, "--varsFile", dir+"/"+remoteVarsFile)
+	}
+
+	quoted := []string{shellQuote(h.CLIPath)}
+	for _, a := range args {
+		quoted = append(quoted, shellQuote(a))
+	}
+
+	cmd := fmt.Sprintf(". %s && %s", shellQuote(dir+"/"+remoteEnvFile), strings.Join(quoted, " "))
+	// The session copies stdout and stderr concurrently.
+	w := &lockedWriter{w: out}
+	installErr := runCommand(client, h.command(cmd), nil, w, w)
+
+	// A failed install still writes its report.
+	stdout.Reset()
+	if err = runCommand(client, h.command("cat "+shellQuote(dir+"/"+remoteReportFile)), nil, &stdout, ioutil.Discard); err != nil {
+		if installErr != nil {
+			return nil, fmt.Errorf("install failed: %s", installErr)
+		}
+
+		return nil, fmt.Errorf("could not read the install report: %s", err)
+	}
+
+	var status execution.InstallStatus
+	if err = json.Unmarshal(stdout.Bytes(), &status); err != nil {
+		return nil, fmt.Errorf("could not parse the install report: %s", err)
+	}
+
+	if installErr != nil {
+		return &status, fmt.Errorf("install failed: %s", installErr)
+	}
+
+	return &status, nil
+}
+
+func (r *SSHHostRunner) dial(ctx context.Context, h Host) (*ssh.Client, error) {
+	auth, err := r.authMethods(h)
+	if err != nil {
+		return nil, err
+	}
+
+	config := ssh.ClientConfig{
+		User:            h.User,
+		Auth:            auth,
+		HostKeyCallback: r.hostKeyCallback,
+		Timeout:         r.DialTimeout,
+	}
+
+	dialer := net.Dialer{Timeout: r.DialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", h.Addr())
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to %s: %s", h.Addr(), err)
+	}
+
+	c, chans, reqs, err := ssh.NewClientConn(conn, h.Addr(), &config)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("could not connect to %s: %s", h.Addr(), err)
+	}
+
+	return ssh.NewClient(c, chans, reqs), nil
+}
+
+// authMethods authenticates with the host's identity file and the keys of a
+// running SSH agent.
+func (r *SSHHostRunner) authMethods(h Host) ([]ssh.AuthMethod, error) {
+	signers := []ssh.Signer{}
+
+	if h.IdentityFile != "" {
+		b, err := ioutil.ReadFile(h.IdentityFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not read identity file: %s", err)
+		}
+
+		signer, err := ssh.ParsePrivateKey(b)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse identity file %s: %s", h.IdentityFile, err)
+		}
+
+		signers = append(signers, signer)
+	}
+
+	if r.agent != nil {
+		agentSigners, err := r.agent.Signers()
+		if err != nil {
+			log.Debugf("not using the SSH agent: %s", err)
+		}
+
+		signers = append(signers, agentSigners...)
+	}
+
+	if len(signers) == 0 {
+		return nil, fmt.Errorf("no identity file or SSH agent keys to authenticate to %s with", h.Name)
+	}
+
+	return []ssh.AuthMethod{ssh.PublicKeys(signers...)}, nil
+}
+
+// command runs a command as root when the host uses sudo.
+func (h Host) command(cmd string) string {
+	if !h.Sudo {
+		return cmd
+	}
+
+	return "sudo -n sh -c " + shellQuote(cmd)
+}
+
+func runCommand(client *ssh.Client, cmd string, stdin io.Reader, stdout io.Writer, stderr io.Writer) error {
+	session, err := client.NewSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	session.Stdin = stdin
+	session.Stdout = stdout
+	session.Stderr = stderr
+
+	return session.Run(cmd)
+}
+
+// upload writes a file only the SSH user can read.
+func upload(client *ssh.Client, path string, content []byte) error {
+	return runCommand(client, "umask 077 && cat > "+shellQuote(path), bytes.NewReader(content), ioutil.Discard, ioutil.Discard)
+}
+
+func envFile(env map[string]string) []byte {
+	keys := []string{}
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b bytes.Buffer
+	for _, k := range keys {
+		fmt.Fprintf(&b, "export %s=%s\n", k, shellQuote(env[k]))
+	}
+
+	return b.Bytes()
+}
+
+// lockedWriter serializes writes to an underlying writer.
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (l *lockedWriter) Write(b []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.w.Write(b)
+}
+
+// shellQuote quotes a string as a single POSIX shell word.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'"'"'`) + "'"
+}
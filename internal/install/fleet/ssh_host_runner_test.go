@@ -0,0 +1,327 @@
+// +build unit
+
+package fleet
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	"github.com/newrelic/newrelic-cli/internal/install/execution"
+)
+
+// fakeCLI stands in for the New Relic CLI on a host.  It checks the environment
+// and vars file were uploaded, and writes a report to the --report path.
+const fakeCLI = `#!/bin/sh
+[ "$NEW_RELIC_API_KEY" = "test-api-key" ] || { echo "missing api key" >&2; exit 3; }
+report=$4
+eval vars=\${$#}
+grep -q mysql "$vars" || { echo "missing vars file" >&2; exit 4; }
+echo "installing $6"
+cat > "$report" <<JSON
+{"recipes": [{"name": "$6", "status": "INSTALLED"}], "hasInstalledRecipes": true}
+JSON
+`
+
+// testSSHServer is an in-process SSH server that runs exec requests with sh.
+type testSSHServer struct {
+	listener net.Listener
+	config   *ssh.ServerConfig
+	hostKey  ssh.PublicKey
+}
+
+func newTestSSHServer(t *testing.T, authorizedKey ssh.PublicKey) *testSSHServer {
+	_, hostPriv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	hostSigner, err := ssh.NewSignerFromKey(hostPriv)
+	require.NoError(t, err)
+
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if bytes.Equal(key.Marshal(), authorizedKey.Marshal()) {
+				return nil, nil
+			}
+
+			return nil, errors.New("unauthorized key")
+		},
+	}
+	config.AddHostKey(hostSigner)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	s := &testSSHServer{listener: l, config: config, hostKey: hostSigner.PublicKey()}
+	go s.serve()
+	t.Cleanup(func() { l.Close() })
+
+	return s
+}
+
+func (s *testSSHServer) host(user string, identityFile string) Host {
+	addr := s.listener.Addr().(*net.TCPAddr)
+
+	return Host{
+		Name:         "test",
+		Address:      addr.IP.String(),
+		Port:         addr.Port,
+		User:         user,
+		IdentityFile: identityFile,
+	}
+}
+
+func (s *testSSHServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		go func() {
+			_, chans, reqs, err := ssh.NewServerConn(conn, s.config)
+			if err != nil {
+				return
+			}
+			go ssh.DiscardRequests(reqs)
+
+			for nc := range chans {
+				if nc.ChannelType() != "session" {
+					_ = nc.Reject(ssh.UnknownChannelType, "unsupported channel type")
+					continue
+				}
+
+				ch, chReqs, err := nc.Accept()
+				if err != nil {
+					continue
+				}
+
+				go handleSession(ch, chReqs)
+			}
+		}()
+	}
+}
+
+func handleSession(ch ssh.Channel, reqs <-chan *ssh.Request) {
+	defer ch.Close()
+
+	for req := range reqs {
+		if req.Type != "exec" {
+			_ = req.Reply(false, nil)
+			continue
+		}
+
+		var payload struct{ Command string }
+		if err := ssh.Unmarshal(req.Payload, &payload); err != nil {
+			_ = req.Reply(false, nil)
+			continue
+		}
+		_ = req.Reply(true, nil)
+
+		cmd := exec.Command("sh", "-c", payload.Command)
+		cmd.Stdin = ch
+		cmd.Stdout = ch
+		cmd.Stderr = ch.Stderr()
+
+		status := 0
+		if err := cmd.Run(); err != nil {
+			status = 1
+			if exitErr, ok := err.(*exec.ExitError); ok {
+				status = exitErr.ExitCode()
+			}
+		}
+
+		_, _ = ch.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{uint32(status)}))
+		return
+	}
+}
+
+func writeClientKey(t *testing.T, dir string) (string, ssh.PublicKey) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	require.NoError(t, err)
+
+	path := filepath.Join(dir, "id_ed25519")
+	require.NoError(t, ioutil.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600))
+
+	sshPub, err := ssh.NewPublicKey(pub)
+	require.NoError(t, err)
+
+	return path, sshPub
+}
+
+func newTestRunner(t *testing.T, dir string, s *testSSHServer) *SSHHostRunner {
+	knownHosts := filepath.Join(dir, "known_hosts")
+	line := knownhosts.Line([]string{s.listener.Addr().String()}, s.hostKey)
+	require.NoError(t, ioutil.WriteFile(knownHosts, []byte(line+"\n"), 0600))
+
+	r, err := NewSSHHostRunner(knownHosts)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+
+	return r
+}
+
+func TestSSHHostRunner_Run(t *testing.T) {
+	dir := t.TempDir()
+	identityFile, pub := writeClientKey(t, dir)
+	s := newTestSSHServer(t, pub)
+
+	cli := filepath.Join(dir, "newrelic")
+	require.NoError(t, ioutil.WriteFile(cli, []byte(fakeCLI), 0700))
+
+	h := s.host("tester", identityFile)
+	h.CLIPath = cli
+
+	install := RemoteInstall{
+		Args:    []string{"--recipe", "mysql-open-source-integration"},
+		Env:     map[string]string{"NEW_RELIC_API_KEY": "test-api-key"},
+		Answers: []byte("mysql-open-source-integration:\n  NR_CLI_DB_PORT: \"3306\"\n"),
+	}
+
+	var out bytes.Buffer
+	status, err := newTestRunner(t, dir, s).Run(context.Background(), h, install, &out)
+	require.NoError(t, err)
+	require.Equal(t, "installing mysql-open-source-integration\n", out.String())
+	require.True(t, status.HasInstalledRecipes)
+	require.Len(t, status.Statuses, 1)
+	require.Equal(t, "mysql-open-source-integration", status.Statuses[0].Name)
+	require.Equal(t, execution.RecipeStatusTypes.INSTALLED, status.Statuses[0].Status)
+}
+
+func TestSSHHostRunner_RunFailed(t *testing.T) {
+	dir := t.TempDir()
+	identityFile, pub := writeClientKey(t, dir)
+	s := newTestSSHServer(t, pub)
+
+	cli := filepath.Join(dir, "newrelic")
+	require.NoError(t, ioutil.WriteFile(cli, []byte(fakeCLI), 0700))
+
+	h := s.host("tester", identityFile)
+	h.CLIPath = cli
+
+	install := RemoteInstall{
+		Args: []string{"--recipe", "mysql-open-source-integration"},
+		Env:  map[string]string{"NEW_RELIC_API_KEY": "wrong-key"},
+	}
+
+	var out bytes.Buffer
+	status, err := newTestRunner(t, dir, s).Run(context.Background(), h, install, &out)
+	require.Error(t, err)
+	require.Nil(t, status)
+	require.Contains(t, err.Error(), "exited with status 3")
+	require.Equal(t, "missing api key\n", out.String())
+}
+
+func TestSSHHostRunner_UnknownHostKey(t *testing.T) {
+	dir := t.TempDir()
+	identityFile, pub := writeClientKey(t, dir)
+	s := newTestSSHServer(t, pub)
+
+	knownHosts := filepath.Join(dir, "known_hosts")
+	require.NoError(t, ioutil.WriteFile(knownHosts, nil, 0600))
+
+	r, err := NewSSHHostRunner(knownHosts)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+
+	_, err = r.Run(context.Background(), s.host("tester", identityFile), RemoteInstall{}, ioutil.Discard)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "knownhosts")
+}
+
+func TestSSHHostRunner_Agent(t *testing.T) {
+	dir := t.TempDir()
+
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	keyring := agent.NewKeyring()
+	require.NoError(t, keyring.Add(agent.AddedKey{PrivateKey: priv}))
+
+	sock := filepath.Join(dir, "agent.sock")
+	l, err := net.Listen("unix", sock)
+	require.NoError(t, err)
+	defer l.Close()
+
+	served := make(chan error, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			served <- err
+			return
+		}
+
+		served <- agent.ServeAgent(keyring, conn)
+	}()
+
+	prior, ok := os.LookupEnv("SSH_AUTH_SOCK")
+	require.NoError(t, os.Setenv("SSH_AUTH_SOCK", sock))
+	defer func() {
+		if ok {
+			os.Setenv("SSH_AUTH_SOCK", prior)
+		} else {
+			os.Unsetenv("SSH_AUTH_SOCK")
+		}
+	}()
+
+	knownHosts := filepath.Join(dir, "known_hosts")
+	require.NoError(t, ioutil.WriteFile(knownHosts, nil, 0600))
+
+	r, err := NewSSHHostRunner(knownHosts)
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		methods, err := r.authMethods(Host{Name: "web-1"})
+		require.NoError(t, err)
+		require.Len(t, methods, 1)
+	}
+
+	require.NoError(t, r.Close())
+	require.Error(t, <-served)
+
+	_, err = r.authMethods(Host{Name: "web-1"})
+	require.Error(t, err)
+}
+
+func TestSSHHostRunner_NoKeys(t *testing.T) {
+	r := &SSHHostRunner{}
+
+	_, err := r.authMethods(Host{Name: "web-1"})
+	require.Error(t, err)
+}
+
+func TestHostCommand(t *testing.T) {
+	require.Equal(t, "cat report.json", Host{}.command("cat report.json"))
+	require.Equal(t, `sudo -n sh -c 'cat '"'"'my report.json'"'"''`, Host{Sudo: true}.command("cat "+shellQuote("my report.json")))
+}
+
+func TestEnvFile(t *testing.T) {
+	env := envFile(map[string]string{
+		"NEW_RELIC_REGION":  "US",
+		"NEW_RELIC_API_KEY": "key'with quote",
+	})
+
+	require.Equal(t, "export NEW_RELIC_API_KEY='key'\"'\"'with quote'\nexport NEW_RELIC_REGION='US'\n", string(env))
+
+	out, err := exec.Command("sh", "-c", string(env)+"printf %s \"$NEW_RELIC_API_KEY\"").Output()
+	require.NoError(t, err)
+	require.Equal(t, "key'with quote", string(out))
+}
+
@@ -0,0 +1,189 @@
+package install
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/newrelic/newrelic-cli/internal/credentials"
+	"github.com/newrelic/newrelic-cli/internal/install/execution"
+	"github.com/newrelic/newrelic-cli/internal/install/fleet"
+	"github.com/newrelic/newrelic-cli/internal/install/recipes"
+	"github.com/newrelic/newrelic-cli/internal/utils"
+)
+
+// runFleetInstall runs the install on each host of an inventory over SSH and
+// prints a summary of the outcome on each host.  Each host runs its own
+// discovery, and the vars file is shared by every host.
+func runFleetInstall(ic InstallerContext, inventoryPath string, concurrency int) error {
+	if err := assertFleetInstallIsValid(ic); err != nil {
+		return err
+	}
+
+	if concurrency < 1 {
+		return errors.New("--concurrency must be at least 1")
+	}
+
+	inv, err := fleet.LoadInventory(inventoryPath)
+	if err != nil {
+		return err
+	}
+
+	profile := credentials.DefaultProfile()
+	if err = assertProfileIsValid(profile); err != nil {
+		return err
+	}
+
+	ri := fleet.RemoteInstall{
+		Args: remoteInstallArgs(ic),
+		Env:  remoteInstallEnv(profile, ic.Proxy),
+	}
+
+	if varsFile != "" {
+		if ri.Answers, err = ioutil.ReadFile(varsFile); err != nil {
+			return err
+		}
+	}
+
+	runner, err := fleet.NewSSHHostRunner(inv.KnownHostsFile)
+	if err != nil {
+		return err
+	}
+	defer runner.Close()
+
+	log.Infof("Installing on %d hosts, %d at a time", len(inv.Hosts), concurrency)
+
+	results := fleet.Install(utils.SignalCtx, inv.Hosts, runner, ri, concurrency, os.Stdout)
+
+	fmt.Println()
+	if err = fleet.RenderSummary(os.Stdout, results); err != nil {
+		return err
+	}
+
+	if ic.ReportPath != "" {
+		if err = fleet.WriteReport(ic.ReportPath, execution.ReportFormat(ic.ReportFormat), results); err != nil {
+			return fmt.Errorf("could not write the install report: %s", err)
+		}
+	}
+
+	failed := 0
+	for _, r := range results {
+		if r.Failed() {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("install failed on %d of %d hosts", failed, len(results))
+	}
+
+	return nil
+}
+
+// assertFleetInstallIsValid rejects the flags that refer to files or state on
+// the local host.
+func assertFleetInstallIsValid(ic InstallerContext) error {
+	switch {
+	case ic.RecipePathsProvided():
+		return errors.New("--recipePath cannot be used with --inventory, use --recipe")
+	case ic.LocalRecipes != "":
+		return errors.New("--localRecipes cannot be used with --inventory")
+	case ic.DryRun:
+		return errors.New("--dryRun cannot be used with --inventory")
+	case ic.ResumeDocumentID != "":
+		return errors.New("--resume cannot be used with --inventory")
+	}
+
+	for _, spec := range ic.RecipeRepositories {
+		f, err := recipes.NewRecipeRepositoryFetcher(spec, recipes.RecipeRepositoryOptions{AllowInsecure: ic.InsecureRepositories})
+		if err != nil {
+			return err
+		}
+
+		if _, ok := f.(*recipes.LocalRecipeFetcher); ok {
+			return fmt.Errorf("recipe repository %s is a local directory, which cannot be used with --inventory", spec)
+		}
+	}
+
+	return nil
+}
+
+// remoteInstallArgs returns the install flags passed on to each host.  The
+// report of each host is read back by the runner, and the proxy is passed in
+// the environment since it can carry a password.
+func remoteInstallArgs(ic InstallerContext) []string {
+	args := []string{}
+
+	for _, n := range ic.RecipeNames {
+		args = append(args, "--recipe", n)
+	}
+
+	for _, spec := range ic.RecipeRepositories {
+		args = append(args, "--recipeRepository", spec)
+	}
+
+	flags := []struct {
+		name string
+		set  bool
+	}{
+		{"--skipDiscovery", ic.SkipDiscovery},
+		{"--skipIntegrations", ic.SkipIntegrations},
+		{"--skipLoggingInstall", ic.SkipLoggingInstall},
+		{"--skipApm", ic.SkipApm},
+		{"--skipInfra", ic.SkipInfra},
+		{"--requireSignedRecipes", ic.RequireSignedRecipes},
+		{"--offline", ic.Offline},
+		{"--noRollback", ic.NoRollback},
+		{"--allowInsecureRecipeRepositories", ic.InsecureRepositories},
+		{"--debug", ic.Debug},
+		{"--trace", ic.Trace},
+	}
+
+	for _, f := range flags {
+		if f.set {
+			args = append(args, f.name)
+		}
+	}
+
+	args = append(args,
+		"--validationTimeout", ic.ValidationTimeout.String(),
+		"--validationInterval", ic.ValidationInterval.String(),
+		"--recipeCacheTTL", ic.RecipeCacheTTL.String(),
+	)
+
+	if ic.KubernetesOutputDir != "" {
+		args = append(args, "--kubernetesOutput", ic.KubernetesOutputDir)
+	}
+
+	return args
+}
+
+// remoteInstallEnv returns the environment the CLI on each host initializes its
+// default profile and proxy from.
+func remoteInstallEnv(p *credentials.Profile, proxy string) map[string]string {
+	env := map[string]string{
+		"NEW_RELIC_API_KEY": p.APIKey,
+	}
+
+	if p.AccountID != 0 {
+		env["NEW_RELIC_ACCOUNT_ID"] = strconv.Itoa(p.AccountID)
+	}
+
+	if p.Region != "" {
+		env["NEW_RELIC_REGION"] = p.Region
+	}
+
+	if p.LicenseKey != "" {
+		env["NEW_RELIC_LICENSE_KEY"] = p.LicenseKey
+	}
+
+	if proxy != "" {
+		env["NEW_RELIC_CLI_PROXY"] = proxy
+	}
+
+	return env
+}
@@ -1,17 +1,67 @@
 package install
 
+import (
+	"time"
+
+	"github.com/newrelic/newrelic-cli/internal/install/execution"
+	"github.com/newrelic/newrelic-cli/internal/install/types"
+)
+
 // nolint: maligned
 type InstallerContext struct {
 	AssumeYes   bool
 	RecipeNames []string
 	RecipePaths []string
 	// LocalRecipes is the path to a local recipe directory from which to load recipes.
-	LocalRecipes       string
-	SkipDiscovery      bool
-	SkipIntegrations   bool
-	SkipLoggingInstall bool
-	SkipApm            bool
-	SkipInfra          bool
+	LocalRecipes string
+	// RecipeRepositories are layered over the recipe service, or over
+	// LocalRecipes, from highest to lowest precedence.
+	RecipeRepositories []string
+	// InsecureRepositories allows HTTP recipe indexes served over plain http.
+	InsecureRepositories bool
+	SkipDiscovery        bool
+	SkipIntegrations     bool
+	SkipLoggingInstall   bool
+	SkipApm              bool
+	SkipInfra            bool
+	// DryRun prepares the install and prints a plan instead of executing recipes.
+	DryRun bool
+	// PlanFormat is the format the dry run plan is printed in.
+	PlanFormat string
+	// ResumeDocumentID is the document ID of a previous install to resume.
+	ResumeDocumentID string
+	// ResumedRecipeNames limits a resumed guided install to the recipes that
+	// failed or were canceled.  A resumed targeted install sets RecipeNames.
+	ResumedRecipeNames []string
+	// Answers provides values for recipe input vars, loaded from a vars file.
+	Answers *execution.RecipeAnswers
+	// ValidationTimeout is how long to wait for a recipe's data to be reported.
+	ValidationTimeout time.Duration
+	// ValidationInterval is how often to check whether a recipe's data is reported.
+	ValidationInterval time.Duration
+	// ReportPath is the file the final install status is written to.
+	ReportPath string
+	// ReportFormat is the format the install report is written in.
+	ReportFormat string
+	// Offline serves recipes from the recipe cache without querying the recipe service,
+	// and skips the validation of installed recipes.
+	Offline bool
+	// RecipeCacheTTL is how long cached recipe service responses are used.
+	RecipeCacheTTL time.Duration
+	// RequireSignedRecipes refuses recipe files and HTTP recipe repositories fetched by URL
+	// that are not signed by a trusted key.
+	RequireSignedRecipes bool
+	// KubernetesOutputDir is where recipes targeting a Kubernetes cluster render their manifests and Helm values.
+	KubernetesOutputDir string
+	// NoRollback leaves a recipe that failed to execute or validate as it is,
+	// instead of running its rollback tasks.
+	NoRollback bool
+	// Proxy is the URL of the proxy requests to New Relic, downloads and the
+	// installed agents and integrations send data through.
+	Proxy string
+	// Debug and Trace raise the log level of the install.
+	Debug bool
+	Trace bool
 }
 
 func (i *InstallerContext) ShouldRunDiscovery() bool {
@@ -23,7 +73,7 @@ func (i *InstallerContext) ShouldInstallInfraAgent() bool {
 }
 
 func (i *InstallerContext) ShouldInstallLogging() bool {
-	return !i.RecipesProvided() && !i.SkipLoggingInstall
+	return !i.RecipesProvided() && !i.SkipLoggingInstall && i.ShouldResumeRecipe(types.LoggingRecipeName)
 }
 
 func (i *InstallerContext) ShouldInstallIntegrations() bool {
@@ -34,6 +84,22 @@ func (i *InstallerContext) ShouldInstallApm() bool {
 	return i.RecipesProvided() || !i.SkipApm
 }
 
+// ShouldResumeRecipe returns true when a resumed guided install runs the named
+// recipe again, which is every recipe when not resuming.
+func (i *InstallerContext) ShouldResumeRecipe(name string) bool {
+	if len(i.ResumedRecipeNames) == 0 {
+		return true
+	}
+
+	for _, n := range i.ResumedRecipeNames {
+		if n == name {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (i *InstallerContext) RecipePathsProvided() bool {
 	return len(i.RecipePaths) > 0
 }
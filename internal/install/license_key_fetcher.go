@@ -2,10 +2,12 @@ package install
 
 import (
 	"context"
+	"errors"
 	"strconv"
 
 	log "github.com/sirupsen/logrus"
 
+	"github.com/newrelic/newrelic-cli/internal/config"
 	"github.com/newrelic/newrelic-cli/internal/credentials"
 	"github.com/newrelic/newrelic-cli/internal/install/recipes"
 )
@@ -48,10 +50,30 @@ func (f *ServiceLicenseKeyFetcher) FetchLicenseKey(ctx context.Context) (string,
 	}
 
 	licenseKey := resp.Actor.Account.LicenseKey
-	log.Debugf("Found license key %s", licenseKey)
+	config.RegisterSecrets(licenseKey)
+	log.Debug("Found license key")
+
 	return licenseKey, nil
 }
 
+// ProfileLicenseKeyFetcher is an implementation of the LicenseKeyFetcher
+// interface that uses the license key of the default profile, for installs
+// that cannot reach NerdGraph.
+type ProfileLicenseKeyFetcher struct{}
+
+func NewProfileLicenseKeyFetcher() LicenseKeyFetcher {
+	return &ProfileLicenseKeyFetcher{}
+}
+
+func (f *ProfileLicenseKeyFetcher) FetchLicenseKey(ctx context.Context) (string, error) {
+	defaultProfile := credentials.DefaultProfile()
+	if defaultProfile == nil || defaultProfile.LicenseKey == "" {
+		return "", errors.New("the default profile has no license key; add one with `newrelic profile add --licenseKey` to install offline")
+	}
+
+	return defaultProfile.LicenseKey, nil
+}
+
 type licenseKeyDataQueryResult struct {
 	Actor licenseKeyActorQueryResult `json:"actor"`
 }
@@ -0,0 +1,355 @@
+package preflight
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/disk"
+	"github.com/shirou/gopsutil/host"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/newrelic/newrelic-cli/internal/install/discovery"
+	"github.com/newrelic/newrelic-cli/internal/install/types"
+	"github.com/newrelic/newrelic-cli/internal/utils"
+)
+
+const (
+	// DefaultMinFreeDiskSpace is the free disk space below which the disk space
+	// check fails.  The check warns below twice this amount.
+	DefaultMinFreeDiskSpace uint64 = 512 * 1024 * 1024
+
+	// MaxClockSkewWarning and MaxClockSkew bound how far the host's clock may
+	// drift from New Relic's before data is rejected or misplaced in time.
+	MaxClockSkewWarning = 30 * time.Second
+	MaxClockSkew        = 5 * time.Minute
+
+	endpointTimeout     = 10 * time.Second
+	systemdRuntimeDir   = "/run/systemd/system"
+	windowsSystemDrive  = "C:\\"
+	unixRootPath        = "/"
+	bytesPerMebibyte    = 1024 * 1024
+	sudoCommand         = "sudo"
+	unknownEffectiveUID = -1
+)
+
+// OperatingSystemCheck runs the validators the installer runs against the
+// discovery manifest, such as the supported operating systems and versions.
+type OperatingSystemCheck struct {
+	validator discovery.Validator
+	discover  func(context.Context) (*types.DiscoveryManifest, error)
+}
+
+// NewOperatingSystemCheck returns a new instance of OperatingSystemCheck.
+func NewOperatingSystemCheck() *OperatingSystemCheck {
+	c := OperatingSystemCheck{
+		validator: discovery.NewManifestValidator(),
+		discover: func(ctx context.Context) (*types.DiscoveryManifest, error) {
+			i, err := host.InfoWithContext(ctx)
+			if err != nil {
+				return nil, err
+			}
+
+			m := types.DiscoveryManifest{
+				OS:              i.OS,
+				Platform:        i.Platform,
+				PlatformFamily:  i.PlatformFamily,
+				PlatformVersion: i.PlatformVersion,
+			}
+
+			return &m, nil
+		},
+	}
+
+	return &c
+}
+
+func (c *OperatingSystemCheck) Name() string {
+	return "Operating system"
+}
+
+func (c *OperatingSystemCheck) Run(ctx context.Context) CheckResult {
+	m, err := c.discover(ctx)
+	if err != nil {
+		return newResult(c, CheckStatuses.FAIL, "could not discover the operating system: %s", err)
+	}
+
+	if err := c.validator.Execute(m); err != nil {
+		return newResult(c, CheckStatuses.FAIL, "%s", err)
+	}
+
+	return newResult(c, CheckStatuses.PASS, "%s %s %s", m.OS, m.Platform, m.PlatformVersion)
+}
+
+// PrivilegeCheck checks the CLI runs with the root privileges most recipes need,
+// or can gain them with sudo.
+type PrivilegeCheck struct {
+	geteuid  func() int
+	lookPath func(string) (string, error)
+}
+
+// NewPrivilegeCheck returns a new instance of PrivilegeCheck.
+func NewPrivilegeCheck() *PrivilegeCheck {
+	c := PrivilegeCheck{
+		geteuid:  os.Geteuid,
+		lookPath: exec.LookPath,
+	}
+
+	return &c
+}
+
+func (c *PrivilegeCheck) Name() string {
+	return "Root privileges"
+}
+
+func (c *PrivilegeCheck) Run(ctx context.Context) CheckResult {
+	euid := c.geteuid()
+
+	switch {
+	case euid == 0:
+		return newResult(c, CheckStatuses.PASS, "running as root")
+	case euid == unknownEffectiveUID:
+		return newResult(c, CheckStatuses.WARN, "could not determine privileges, run from an elevated shell")
+	}
+
+	if _, err := c.lookPath(sudoCommand); err == nil {
+		return newResult(c, CheckStatuses.WARN, "not running as root, recipes will prompt for sudo")
+	}
+
+	return newResult(c, CheckStatuses.FAIL, "not running as root and sudo is not installed")
+}
+
+// DiskSpaceCheck checks there is enough free disk space to download and
+// install agents and integrations.
+type DiskSpaceCheck struct {
+	Path    string
+	MinFree uint64
+	free    func(context.Context, string) (uint64, error)
+}
+
+// NewDiskSpaceCheck returns a new instance of DiskSpaceCheck.
+func NewDiskSpaceCheck(path string, minFree uint64) *DiskSpaceCheck {
+	c := DiskSpaceCheck{
+		Path:    path,
+		MinFree: minFree,
+		free: func(ctx context.Context, path string) (uint64, error) {
+			u, err := disk.UsageWithContext(ctx, path)
+			if err != nil {
+				return 0, err
+			}
+
+			return u.Free, nil
+		},
+	}
+
+	return &c
+}
+
+func (c *DiskSpaceCheck) Name() string {
+	return "Free disk space"
+}
+
+func (c *DiskSpaceCheck) Run(ctx context.Context) CheckResult {
+	free, err := c.free(ctx, c.Path)
+	if err != nil {
+		return newResult(c, CheckStatuses.WARN, "could not read the free space of %s: %s", c.Path, err)
+	}
+
+	switch {
+	case free < c.MinFree:
+		return newResult(c, CheckStatuses.FAIL, "%d MiB free on %s, at least %d MiB is required", free/bytesPerMebibyte, c.Path, c.MinFree/bytesPerMebibyte)
+	case free < 2*c.MinFree:
+		return newResult(c, CheckStatuses.WARN, "%d MiB free on %s", free/bytesPerMebibyte, c.Path)
+	}
+
+	return newResult(c, CheckStatuses.PASS, "%d MiB free on %s", free/bytesPerMebibyte, c.Path)
+}
+
+// SystemdCheck checks the host runs systemd, which recipes use to manage the
+// services they install.
+type SystemdCheck struct {
+	RuntimeDir string
+}
+
+// NewSystemdCheck returns a new instance of SystemdCheck.
+func NewSystemdCheck() *SystemdCheck {
+	c := SystemdCheck{
+		RuntimeDir: systemdRuntimeDir,
+	}
+
+	return &c
+}
+
+func (c *SystemdCheck) Name() string {
+	return "systemd"
+}
+
+func (c *SystemdCheck) Run(ctx context.Context) CheckResult {
+	if _, err := os.Stat(c.RuntimeDir); err != nil {
+		return newResult(c, CheckStatuses.WARN, "systemd is not running, recipes that manage services may fail")
+	}
+
+	return newResult(c, CheckStatuses.PASS, "systemd is running")
+}
+
+// BinariesCheck checks the commands recipes run are installed.
+type BinariesCheck struct {
+	Names    []string
+	lookPath func(string) (string, error)
+}
+
+// NewBinariesCheck returns a new instance of BinariesCheck.
+func NewBinariesCheck(names []string) *BinariesCheck {
+	c := BinariesCheck{
+		Names:    names,
+		lookPath: exec.LookPath,
+	}
+
+	return &c
+}
+
+func (c *BinariesCheck) Name() string {
+	return "Required commands"
+}
+
+func (c *BinariesCheck) Run(ctx context.Context) CheckResult {
+	missing := []string{}
+	for _, n := range c.Names {
+		if _, err := c.lookPath(n); err != nil {
+			missing = append(missing, n)
+		}
+	}
+
+	if len(missing) > 0 {
+		return newResult(c, CheckStatuses.FAIL, "not found: %s", strings.Join(missing, ", "))
+	}
+
+	return newResult(c, CheckStatuses.PASS, "found %s", strings.Join(c.Names, ", "))
+}
+
+// EndpointCheck checks a New Relic endpoint can be reached.  Any HTTP response
+// counts as reachable, since requests are not authenticated.
+type EndpointCheck struct {
+	CheckName string
+	URL       string
+	client    *http.Client
+}
+
+// NewEndpointCheck returns a new instance of EndpointCheck that sends requests
+// through the given proxy, if any.
+func NewEndpointCheck(name string, url string, proxy string) *EndpointCheck {
+	c := EndpointCheck{
+		CheckName: name,
+		URL:       url,
+		client:    newHTTPClient(proxy),
+	}
+
+	return &c
+}
+
+func (c *EndpointCheck) Name() string {
+	return c.CheckName
+}
+
+func (c *EndpointCheck) Run(ctx context.Context) CheckResult {
+	resp, err := head(ctx, c.client, c.URL)
+	if err != nil {
+		return newResult(c, CheckStatuses.FAIL, "could not reach %s: %s", c.URL, err)
+	}
+	resp.Body.Close()
+
+	return newResult(c, CheckStatuses.PASS, "reached %s", c.URL)
+}
+
+// ClockSkewCheck compares the host's clock with the Date header of a New Relic
+// endpoint.
+type ClockSkewCheck struct {
+	URL    string
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewClockSkewCheck returns a new instance of ClockSkewCheck that sends
+// requests through the given proxy, if any.
+func NewClockSkewCheck(url string, proxy string) *ClockSkewCheck {
+	c := ClockSkewCheck{
+		URL:    url,
+		client: newHTTPClient(proxy),
+		now:    time.Now,
+	}
+
+	return &c
+}
+
+func (c *ClockSkewCheck) Name() string {
+	return "Clock skew"
+}
+
+func (c *ClockSkewCheck) Run(ctx context.Context) CheckResult {
+	resp, err := head(ctx, c.client, c.URL)
+	if err != nil {
+		return newResult(c, CheckStatuses.WARN, "could not compare clocks with %s: %s", c.URL, err)
+	}
+	resp.Body.Close()
+
+	date, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		return newResult(c, CheckStatuses.WARN, "%s did not return a valid Date header", c.URL)
+	}
+
+	skew := c.now().Sub(date).Round(time.Second)
+	abs := skew
+	if abs < 0 {
+		abs = -abs
+	}
+
+	switch {
+	case abs > MaxClockSkew:
+		return newResult(c, CheckStatuses.FAIL, "host clock is off by %s, synchronize it with NTP", skew)
+	case abs > MaxClockSkewWarning:
+		return newResult(c, CheckStatuses.WARN, "host clock is off by %s", skew)
+	}
+
+	return newResult(c, CheckStatuses.PASS, "host clock is within %s", MaxClockSkewWarning)
+}
+
+// newHTTPClient returns a client for the endpoint checks.  An invalid proxy is
+// ignored, so that the checks report whether the endpoints can be reached
+// directly.
+func newHTTPClient(proxy string) *http.Client {
+	client, err := utils.NewHTTPClient(proxy)
+	if err != nil {
+		log.Warnf("checking endpoints without a proxy: %s", err)
+		client = &http.Client{}
+	}
+
+	client.Timeout = endpointTimeout
+
+	return client
+}
+
+func head(ctx context.Context, client *http.Client, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return client.Do(req)
+}
+
+func defaultDiskSpacePath() string {
+	if runtime.GOOS == "windows" {
+		if d := os.Getenv("SystemDrive"); d != "" {
+			return fmt.Sprintf("%s\\", d)
+		}
+
+		return windowsSystemDrive
+	}
+
+	return unixRootPath
+}
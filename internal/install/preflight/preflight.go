@@ -0,0 +1,134 @@
+package preflight
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/newrelic/newrelic-client-go/pkg/region"
+)
+
+// CheckStatus is the outcome of a preflight check.
+type CheckStatus string
+
+// CheckStatuses enumerates the outcomes of preflight checks.
+var CheckStatuses = struct {
+	PASS CheckStatus
+	WARN CheckStatus
+	FAIL CheckStatus
+}{
+	PASS: "pass",
+	WARN: "warn",
+	FAIL: "fail",
+}
+
+// Format is an output format for preflight results.
+type Format string
+
+// Formats enumerates the output formats for preflight results.
+var Formats = struct {
+	TEXT Format
+	JSON Format
+}{
+	TEXT: "text",
+	JSON: "json",
+}
+
+// CheckResult is the outcome of a single preflight check.
+type CheckResult struct {
+	Name    string      `json:"name"`
+	Status  CheckStatus `json:"status"`
+	Message string      `json:"message"`
+}
+
+// Check verifies one environmental requirement of an install, so that an
+// install can be expected to fail before any recipe runs.
+type Check interface {
+	Name() string
+	Run(context.Context) CheckResult
+}
+
+// DefaultChecks returns the checks run by the preflight command for a New
+// Relic region, reaching its endpoints through the given proxy, if any.
+func DefaultChecks(regionName region.Name, proxy string) []Check {
+	r, _ := region.Get(regionName)
+
+	checks := []Check{
+		NewOperatingSystemCheck(),
+		NewPrivilegeCheck(),
+		NewDiskSpaceCheck(defaultDiskSpacePath(), DefaultMinFreeDiskSpace),
+	}
+
+	if runtime.GOOS == "linux" {
+		checks = append(checks,
+			NewSystemdCheck(),
+			NewBinariesCheck([]string{"curl", "tar", "systemctl"}),
+		)
+	}
+
+	checks = append(checks,
+		NewEndpointCheck("NerdGraph endpoint", r.NerdGraphURL(), proxy),
+		NewEndpointCheck("Infrastructure collector endpoint", r.InfrastructureURL(), proxy),
+		NewEndpointCheck("Logs endpoint", r.LogsURL(), proxy),
+		NewClockSkewCheck(r.NerdGraphURL(), proxy),
+	)
+
+	return checks
+}
+
+// Run runs each check in order.
+func Run(ctx context.Context, checks []Check) []CheckResult {
+	results := []CheckResult{}
+	for _, c := range checks {
+		results = append(results, c.Run(ctx))
+	}
+
+	return results
+}
+
+// HasFailures returns true when any check failed.
+func HasFailures(results []CheckResult) bool {
+	for _, r := range results {
+		if r.Status == CheckStatuses.FAIL {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Render writes preflight results as a table or as JSON.
+func Render(w io.Writer, results []CheckResult, format Format) error {
+	switch format {
+	case Formats.JSON:
+		b, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintln(w, string(b))
+		return err
+	case Formats.TEXT, "":
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "STATUS\tCHECK\tDETAILS")
+		for _, r := range results {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", strings.ToUpper(string(r.Status)), r.Name, r.Message)
+		}
+
+		return tw.Flush()
+	}
+
+	return fmt.Errorf("unknown preflight format %s", format)
+}
+
+func newResult(c Check, status CheckStatus, format string, a ...interface{}) CheckResult {
+	return CheckResult{
+		Name:    c.Name(),
+		Status:  status,
+		Message: fmt.Sprintf(format, a...),
+	}
+}
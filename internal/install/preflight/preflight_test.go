@@ -0,0 +1,146 @@
+// +build unit
+
+package preflight
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrivilegeCheck(t *testing.T) {
+	c := NewPrivilegeCheck()
+	c.geteuid = func() int { return 0 }
+	require.Equal(t, CheckStatuses.PASS, c.Run(context.Background()).Status)
+
+	c.geteuid = func() int { return unknownEffectiveUID }
+	require.Equal(t, CheckStatuses.WARN, c.Run(context.Background()).Status)
+
+	c.geteuid = func() int { return 1000 }
+	c.lookPath = func(string) (string, error) { return "/usr/bin/sudo", nil }
+	require.Equal(t, CheckStatuses.WARN, c.Run(context.Background()).Status)
+
+	c.lookPath = func(string) (string, error) { return "", errors.New("not found") }
+	require.Equal(t, CheckStatuses.FAIL, c.Run(context.Background()).Status)
+}
+
+func TestDiskSpaceCheck(t *testing.T) {
+	c := NewDiskSpaceCheck("/", 100*bytesPerMebibyte)
+
+	var free uint64
+	c.free = func(context.Context, string) (uint64, error) { return free, nil }
+
+	free = 500 * bytesPerMebibyte
+	require.Equal(t, CheckStatuses.PASS, c.Run(context.Background()).Status)
+
+	free = 150 * bytesPerMebibyte
+	require.Equal(t, CheckStatuses.WARN, c.Run(context.Background()).Status)
+
+	free = 50 * bytesPerMebibyte
+	result := c.Run(context.Background())
+	require.Equal(t, CheckStatuses.FAIL, result.Status)
+	require.Contains(t, result.Message, "50 MiB free on /")
+
+	c.free = func(context.Context, string) (uint64, error) { return 0, errors.New("no such device") }
+	require.Equal(t, CheckStatuses.WARN, c.Run(context.Background()).Status)
+}
+
+func TestSystemdCheck(t *testing.T) {
+	c := NewSystemdCheck()
+	c.RuntimeDir = t.TempDir()
+	require.Equal(t, CheckStatuses.PASS, c.Run(context.Background()).Status)
+
+	c.RuntimeDir = "/nonexistent/systemd"
+	require.Equal(t, CheckStatuses.WARN, c.Run(context.Background()).Status)
+}
+
+func TestBinariesCheck(t *testing.T) {
+	c := NewBinariesCheck([]string{"curl", "tar"})
+	c.lookPath = func(name string) (string, error) { return "/usr/bin/" + name, nil }
+	require.Equal(t, CheckStatuses.PASS, c.Run(context.Background()).Status)
+
+	c.lookPath = func(name string) (string, error) {
+		if name == "tar" {
+			return "", errors.New("not found")
+		}
+		return "/usr/bin/" + name, nil
+	}
+	result := c.Run(context.Background())
+	require.Equal(t, CheckStatuses.FAIL, result.Status)
+	require.Equal(t, "not found: tar", result.Message)
+}
+
+func TestEndpointCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+
+	c := NewEndpointCheck("NerdGraph endpoint", server.URL, "")
+	require.Equal(t, CheckStatuses.PASS, c.Run(context.Background()).Status)
+	require.Equal(t, "NerdGraph endpoint", c.Name())
+
+	server.Close()
+	require.Equal(t, CheckStatuses.FAIL, c.Run(context.Background()).Status)
+}
+
+func TestClockSkewCheck(t *testing.T) {
+	date := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Date", date.Format(http.TimeFormat))
+	}))
+	defer server.Close()
+
+	c := NewClockSkewCheck(server.URL, "")
+
+	c.now = func() time.Time { return date.Add(5 * time.Second) }
+	require.Equal(t, CheckStatuses.PASS, c.Run(context.Background()).Status)
+
+	c.now = func() time.Time { return date.Add(-time.Minute) }
+	result := c.Run(context.Background())
+	require.Equal(t, CheckStatuses.WARN, result.Status)
+	require.Equal(t, "host clock is off by -1m0s", result.Message)
+
+	c.now = func() time.Time { return date.Add(time.Hour) }
+	require.Equal(t, CheckStatuses.FAIL, c.Run(context.Background()).Status)
+}
+
+func TestRun(t *testing.T) {
+	c := NewBinariesCheck([]string{"curl"})
+	c.lookPath = func(string) (string, error) { return "", errors.New("not found") }
+
+	results := Run(context.Background(), []Check{c})
+	require.Len(t, results, 1)
+	require.True(t, HasFailures(results))
+
+	results[0].Status = CheckStatuses.WARN
+	require.False(t, HasFailures(results))
+}
+
+func TestRender(t *testing.T) {
+	results := []CheckResult{
+		{Name: "Root privileges", Status: CheckStatuses.PASS, Message: "running as root"},
+		{Name: "Clock skew", Status: CheckStatuses.WARN, Message: "host clock is off by 1m0s"},
+	}
+
+	var text bytes.Buffer
+	require.NoError(t, Render(&text, results, Formats.TEXT))
+	require.Equal(t, "STATUS  CHECK            DETAILS\n"+
+		"PASS    Root privileges  running as root\n"+
+		"WARN    Clock skew       host clock is off by 1m0s\n", text.String())
+
+	var out bytes.Buffer
+	require.NoError(t, Render(&out, results, Formats.JSON))
+
+	var parsed []CheckResult
+	require.NoError(t, json.Unmarshal(out.Bytes(), &parsed))
+	require.Equal(t, results, parsed)
+
+	require.Error(t, Render(&out, results, Format("yaml")))
+}
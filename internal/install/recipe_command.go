@@ -0,0 +1,136 @@
+package install
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/newrelic/newrelic-cli/internal/install/recipes"
+	"github.com/newrelic/newrelic-cli/internal/utils"
+)
+
+var (
+	recipeTestVerbose bool
+)
+
+// RecipeCommand represents the recipe command, which groups tools for recipe
+// authors.
+var RecipeCommand = &cobra.Command{
+	Use:   "recipe",
+	Short: "Tools for authoring New Relic install recipes",
+}
+
+var cmdRecipeLint = &cobra.Command{
+	Use:   "lint <path>...",
+	Short: "Check recipe files for errors",
+	Long: `Check recipe files for errors
+
+The lint command statically checks recipe YAML files against the recipe file
+format: required fields, install targets, process match patterns, the
+validation NRQL template, input vars, and that the install and uninstall
+sections are valid go-task v3 Taskfiles.  Directories are searched for .yml and
+.yaml files.  The command exits with a non-zero code when any error is found.
+`,
+	Example: `newrelic recipe lint ./recipes
+newrelic recipe lint mysql.yml nginx.yml`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		diagnostics, err := recipes.LintRecipePaths(args)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		for _, d := range diagnostics {
+			fmt.Println(d)
+		}
+
+		if recipes.HasLintErrors(diagnostics) {
+			log.Fatal("recipe lint found errors")
+		}
+	},
+}
+
+var cmdRecipeTest = &cobra.Command{
+	Use:   "test <spec>...",
+	Short: "Run recipe tests",
+	Long: `Run recipe tests
+
+The test command runs the install tasks of a recipe in an isolated working
+directory and HOME, with a fake discovery manifest and a stubbed validator, then
+asserts the expectations of a test spec.  No New Relic account is required.
+Referenced vars are found by reading the install tasks, not by running them.
+
+A test spec is a YAML file naming the recipe to run, relative to the spec:
+
+  recipe: mysql.yml
+  manifest:
+    hostname: test-host
+    os: linux
+    platformFamily: debian
+  vars:
+    NR_CLI_DB_USERNAME: newrelic
+  env:
+    MYSQL_PORT: "3306"
+  validation:
+    entityGuid: TESTGUID
+  expect:
+    exitCode: 0
+    files:
+      - path: "{{.HOME}}/mysql-config.yml"
+        contains: "username: newrelic"
+      - path: leftover.tmp
+        absent: true
+    varsReferenced:
+      - NR_CLI_DB_USERNAME
+    outputContains:
+      - "mysql configured"
+
+Only the working directory and HOME are isolated.  Tasks that use absolute
+paths or elevated privileges still affect the host they run on.  The command
+exits with a non-zero code when any test fails.
+`,
+	Example: `newrelic recipe test mysql.test.yml
+newrelic recipe test --verbose tests/*.test.yml`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		w := ioutil.Discard
+		if recipeTestVerbose {
+			w = os.Stdout
+		}
+
+		t := NewRecipeTester(w)
+
+		failed := 0
+		for _, path := range args {
+			result, err := t.Run(utils.SignalCtx, path)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			if result.Passed() {
+				fmt.Printf("PASS %s (%s)\n", path, result.RecipeName)
+				continue
+			}
+
+			failed++
+			fmt.Printf("FAIL %s (%s)\n", path, result.RecipeName)
+			for _, f := range result.Failures {
+				fmt.Printf("  - %s\n", f)
+			}
+		}
+
+		if failed > 0 {
+			log.Fatalf("%d of %d recipe tests failed", failed, len(args))
+		}
+	},
+}
+
+func init() {
+	RecipeCommand.AddCommand(cmdRecipeLint)
+
+	RecipeCommand.AddCommand(cmdRecipeTest)
+	cmdRecipeTest.Flags().BoolVarP(&recipeTestVerbose, "verbose", "v", false, "print the output of recipe tasks")
+}
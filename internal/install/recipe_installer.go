@@ -4,10 +4,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
 	"time"
 
 	log "github.com/sirupsen/logrus"
 
+	"github.com/newrelic/newrelic-cli/internal/config"
 	"github.com/newrelic/newrelic-cli/internal/install/discovery"
 	"github.com/newrelic/newrelic-cli/internal/install/execution"
 	"github.com/newrelic/newrelic-cli/internal/install/recipes"
@@ -43,23 +47,74 @@ func NewRecipeInstaller(ic InstallerContext, nrClient *newrelic.NewRelic) *Recip
 		}
 
 	} else {
-		recipeFetcher = recipes.NewServiceRecipeFetcher(&nrClient.NerdGraph)
+		cache := recipes.NewRecipeCache(recipeCacheDirectory(), ic.RecipeCacheTTL, ic.Offline)
+		recipeFetcher = recipes.NewServiceRecipeFetcherWithCache(&nrClient.NerdGraph, cache)
+	}
+
+	hc, err := utils.NewHTTPClient(ic.Proxy)
+	if err != nil {
+		log.Warnf("not using the proxy to download recipe files: %s", err)
+		hc = http.DefaultClient
+	}
+
+	rv := recipes.NewRecipeVerifier(trustedRecipeKeysDirectory(), ic.RequireSignedRecipes)
+
+	if len(ic.RecipeRepositories) > 0 {
+		recipeFetcher = newRecipeRepositoriesFetcher(ic.RecipeRepositories, recipeFetcher, recipes.RecipeRepositoryOptions{
+			CacheDir:      recipeRepositoryCacheDirectory(),
+			HTTPClient:    hc,
+			Verifier:      rv,
+			AllowInsecure: ic.InsecureRepositories,
+		})
 	}
 
 	pf := discovery.NewRegexProcessFilterer(recipeFetcher)
 	mv := discovery.NewManifestValidator()
-	ff := recipes.NewRecipeFileFetcher()
+	ff := recipes.NewVerifyingRecipeFileFetcher(rv, hc)
 	ers := []execution.StatusSubscriber{
-		execution.NewNerdStorageStatusReporter(&nrClient.NerdStorage),
 		execution.NewTerminalStatusReporter(),
+		execution.NewLocalHistoryStatusReporter(execution.DefaultInstallHistoryDirectory()),
+	}
+
+	// Offline installs cannot reach NerdGraph to report status to NerdStorage.
+	if !ic.Offline {
+		ers = append([]execution.StatusSubscriber{execution.NewNerdStorageStatusReporter(&nrClient.NerdStorage)}, ers...)
+	}
+
+	if ic.ReportPath != "" {
+		ers = append(ers, execution.NewReportStatusReporter(ic.ReportPath, execution.ReportFormat(ic.ReportFormat)))
+	}
+
+	gre := execution.NewGoTaskRecipeExecutor()
+	gre.Answers = ic.Answers
+	gre.Proxy = ic.Proxy
+	gre.LogDir = execution.DefaultRecipeLogDirectory()
+
+	var re execution.RecipeExecutor = execution.NewKubernetesRecipeExecutor(gre, ic.KubernetesOutputDir)
+
+	// A dry run records recipe execution and only reports the resulting plan.
+	if ic.DryRun {
+		rre := execution.NewRecordingRecipeExecutor(re)
+		re = rre
+		ers = []execution.StatusSubscriber{
+			execution.NewPlanStatusReporter(rre, execution.PlanFormat(ic.PlanFormat)),
+		}
 	}
+
 	lkf := NewServiceLicenseKeyFetcher(&nrClient.NerdGraph)
+	if ic.Offline {
+		lkf = NewProfileLicenseKeyFetcher()
+	}
 	statusRollup := execution.NewInstallStatus(ers)
 
-	d := discovery.NewPSUtilDiscoverer(pf)
-	gff := discovery.NewGlobFileFilterer()
-	re := execution.NewGoTaskRecipeExecutor()
-	v := validation.NewPollingRecipeValidator(&nrClient.Nrdb)
+	var d discovery.Discoverer = discovery.NewPSUtilDiscovererWithContainers(pf, discovery.DefaultContainerListers()...)
+	d = discovery.NewPackageDiscoverer(d, discovery.DefaultPackageListers()...)
+	d = discovery.NewKubernetesDiscoverer(d)
+	gff := discovery.NewLogSourceFilterer(discovery.NewJournalctlReader())
+	v := validation.NewPollingRecipeValidatorWithConfig(&nrClient.NerdGraph, validation.PollingConfig{
+		Timeout:  ic.ValidationTimeout,
+		Interval: ic.ValidationInterval,
+	})
 	p := ux.NewPromptUIPrompter()
 	pi := ux.NewPlainProgress()
 
@@ -82,6 +137,68 @@ func NewRecipeInstaller(ic InstallerContext, nrClient *newrelic.NewRelic) *Recip
 	return &i
 }
 
+// newRecipeRepositoriesFetcher layers the given recipe repositories over a base
+// fetcher.  Repositories that cannot be parsed are skipped with a warning.
+func newRecipeRepositoriesFetcher(specs []string, base recipes.RecipeFetcher, opts recipes.RecipeRepositoryOptions) recipes.RecipeFetcher {
+	fetchers := []recipes.RecipeFetcher{}
+	for _, spec := range specs {
+		f, err := recipes.NewRecipeRepositoryFetcher(spec, opts)
+		if err != nil {
+			log.Warnf("skipping recipe repository: %s", err)
+			continue
+		}
+
+		fetchers = append(fetchers, f)
+	}
+
+	return recipes.NewCompositeRecipeFetcher(append(fetchers, base)...)
+}
+
+func recipeRepositoryCacheDirectory() string {
+	return filepath.Join(config.DefaultConfigDirectory, "recipes")
+}
+
+func recipeCacheDirectory() string {
+	return filepath.Join(config.DefaultConfigDirectory, "recipes", "service")
+}
+
+// trustedRecipeKeysDirectory is the trust store of public keys that recipe file
+// signatures are verified against.
+func trustedRecipeKeysDirectory() string {
+	return filepath.Join(config.DefaultConfigDirectory, "trusted-recipe-keys")
+}
+
+// ResumeFrom prepares the installer to resume a previous install.  Only the
+// recipes that failed or were canceled are installed again, and status is
+// reported under the previous install's document ID.  A guided install is
+// resumed through the guided path, so that logging discovery and prompts run
+// for the recipes installed again.
+func (i *RecipeInstaller) ResumeFrom(prior *execution.InstallStatus) error {
+	if i.RecipesProvided() {
+		return errors.New("resuming an install cannot be combined with --recipe or --recipePath")
+	}
+
+	names := prior.ResumableRecipeNames()
+	if len(names) == 0 {
+		return fmt.Errorf("install %s has no failed or canceled recipes to resume", prior.DocumentID)
+	}
+
+	log.WithFields(log.Fields{
+		"documentID": prior.DocumentID,
+		"names":      names,
+	}).Debug("resuming install")
+
+	if prior.IsTargetedInstall() {
+		i.RecipeNames = names
+	} else {
+		i.ResumedRecipeNames = names
+	}
+
+	i.status.ResumeFrom(prior)
+
+	return nil
+}
+
 func (i *RecipeInstaller) Install() error {
 	fmt.Printf(`
    _   _                 ____      _ _
@@ -167,33 +284,110 @@ func (i *RecipeInstaller) assertDiscoveryValid(ctx context.Context, m *types.Dis
 	return nil
 }
 
+// recipeValidationResult is the outcome of validating a recipe's data.
+type recipeValidationResult struct {
+	recipe types.Recipe
+	vars   types.RecipeVars
+	event  execution.RecipeStatusEvent
+	err    error
+}
+
+// installRecipes executes the given recipes in order.  Each recipe's
+// validation starts as soon as its execution completes and runs concurrently
+// with the execution of the remaining recipes.
 func (i *RecipeInstaller) installRecipes(ctx context.Context, m *types.DiscoveryManifest, recipes []types.Recipe) error {
 	log.WithFields(log.Fields{
 		"recipe_count": len(recipes),
 	}).Debug("installing recipes")
 
-	for _, r := range recipes {
-		var err error
+	results := make(chan recipeValidationResult, len(recipes))
+	validating := 0
 
+	for _, r := range recipes {
 		log.WithFields(log.Fields{
 			"name": r.Name,
 		}).Debug("installing recipe")
 
-		_, err = i.executeAndValidateWithProgress(ctx, m, &r)
+		// Validations of the previous recipes keep running, but their progress
+		// would interleave with this recipe's prompts and output.
+		i.recipeValidator.SuspendProgress()
+		vars, err := i.executeWithProgress(ctx, m, &r)
+		i.recipeValidator.ResumeProgress()
+
 		if err != nil {
 			if err == types.ErrInterrupt {
 				return err
 			}
 
-			log.Debugf("Failed while executing and validating with progress for recipe name %s, detail:%s", r.Name, err)
+			log.Debugf("Failed while executing with progress for recipe name %s, detail:%s", r.Name, err)
 			log.Warn(err)
 			log.Warn(i.failMessage(r.DisplayName))
 
 			if len(recipes) == 1 {
 				return err
 			}
+
+			continue
 		}
-		log.Debugf("Done executing and validating with progress for recipe name %s.", r.Name)
+
+		validating++
+		go func(r types.Recipe, vars types.RecipeVars) {
+			event, err := i.validate(ctx, m, &r)
+			results <- recipeValidationResult{recipe: r, vars: vars, event: event, err: err}
+		}(r, vars)
+	}
+
+	for ; validating > 0; validating-- {
+		result := <-results
+
+		i.recipeValidator.SuspendProgress()
+		err := i.reportValidation(result.event, result.err)
+		if err != nil {
+			i.rollback(ctx, m, &result.recipe, result.vars)
+
+			log.Debugf("Failed while validating recipe name %s, detail:%s", result.recipe.Name, err)
+			log.Warn(err)
+			log.Warn(i.failMessage(result.recipe.DisplayName))
+		}
+		i.recipeValidator.ResumeProgress()
+
+		if err != nil && len(recipes) == 1 {
+			return err
+		}
+
+		log.Debugf("Done executing and validating recipe name %s.", result.recipe.Name)
+	}
+
+	return nil
+}
+
+// assertInputVarsProvided ensures every input var of the given recipes has a
+// value when installing from a vars file, so that an unattended install fails
+// before anything runs rather than midway through.
+func (i *RecipeInstaller) assertInputVarsProvided(recipesForInstall []types.Recipe) error {
+	if i.Answers == nil {
+		return nil
+	}
+
+	missing := []string{}
+	for _, r := range recipesForInstall {
+		f, err := recipes.RecipeToRecipeFile(r)
+		if err != nil {
+			return err
+		}
+
+		names, err := i.Answers.MissingInputVars(r.Name, f.InputVars)
+		if err != nil {
+			return err
+		}
+
+		for _, n := range names {
+			missing = append(missing, fmt.Sprintf("%s (%s)", n, r.Name))
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("no value found in the vars file, the environment or a default for: %s", strings.Join(missing, ", "))
 	}
 
 	return nil
@@ -211,54 +405,134 @@ func (i *RecipeInstaller) discover(ctx context.Context) (*types.DiscoveryManifes
 }
 
 func (i *RecipeInstaller) executeAndValidate(ctx context.Context, m *types.DiscoveryManifest, r *types.Recipe, vars types.RecipeVars) (string, error) {
+	if err := i.execute(ctx, m, r, vars); err != nil {
+		return "", err
+	}
+
+	event, err := i.validate(ctx, m, r)
+	if err = i.reportValidation(event, err); err != nil {
+		i.rollback(ctx, m, r, vars)
+		return "", err
+	}
+
+	return event.EntityGUID, nil
+}
+
+func (i *RecipeInstaller) execute(ctx context.Context, m *types.DiscoveryManifest, r *types.Recipe, vars types.RecipeVars) error {
 	i.status.RecipeInstalling(execution.RecipeStatusEvent{Recipe: *r})
 
 	// Execute the recipe steps.
 	if err := i.recipeExecutor.Execute(ctx, *m, *r, vars); err != nil {
 		if err == types.ErrInterrupt {
-			return "", err
+			return err
 		}
 
 		msg := fmt.Sprintf("encountered an error while executing %s: %s", r.Name, err)
 		i.status.RecipeFailed(execution.RecipeStatusEvent{
-			Recipe: *r,
-			Msg:    msg,
+			Recipe:  *r,
+			Msg:     msg,
+			Details: execution.TaskErrorDetails(err),
 		})
-		return "", errors.New(msg)
+		i.rollback(ctx, m, r, vars)
+
+		return errors.New(msg)
+	}
+
+	return nil
+}
+
+// rollback runs the rollback tasks of a recipe that failed to execute or
+// validate, so that a partial install does not leave broken configuration
+// behind.  Recipes without a rollback section are left as they are, as are all
+// recipes when rollback is disabled.
+func (i *RecipeInstaller) rollback(ctx context.Context, m *types.DiscoveryManifest, r *types.Recipe, vars types.RecipeVars) {
+	if i.NoRollback || i.DryRun {
+		return
+	}
+
+	f, err := recipes.RecipeToRecipeFile(*r)
+	if err != nil || !f.HasRollback() {
+		return
+	}
+
+	log.Infof("Rolling back %s", r.Name)
+
+	event := execution.RecipeStatusEvent{Recipe: *r}
+	if err := i.recipeExecutor.Rollback(ctx, *m, *r, vars); err != nil {
+		event.Msg = fmt.Sprintf("encountered an error while rolling back %s: %s", r.Name, err)
+		event.Details = execution.TaskErrorDetails(err)
+		log.Warn(event.Msg)
 	}
 
-	var entityGUID string
+	i.status.RecipeRolledBack(event)
+}
+
+// validate asserts data is being reported for an executed recipe.  It reports
+// no status, so that it can be run concurrently with other recipes.
+func (i *RecipeInstaller) validate(ctx context.Context, m *types.DiscoveryManifest, r *types.Recipe) (execution.RecipeStatusEvent, error) {
+	event := execution.RecipeStatusEvent{Recipe: *r}
+
 	var err error
-	var validationDurationMilliseconds int64
 	start := time.Now()
-	if r.ValidationNRQL != "" {
-		entityGUID, err = i.recipeValidator.Validate(ctx, *m, *r)
+	if i.DryRun {
+		log.Debugf("skipping validation for dry run")
+	} else if i.Offline {
+		// Offline installs cannot query NRDB for the recipe's data, so the
+		// recipe is reported installed without being validated.
+		log.Infof("skipping validation of %s while offline", r.Name)
+	} else if r.RendersKubernetesOutput() {
+		log.Debugf("skipping validation for rendered Kubernetes output")
+	} else if r.ValidationNRQL != "" {
+		event.EntityGUID, err = i.recipeValidator.Validate(ctx, *m, *r)
 		if err != nil {
-			validationDurationMilliseconds = time.Since(start).Milliseconds()
-			msg := fmt.Sprintf("encountered an error while validating receipt of data for %s: %s", r.Name, err)
-			i.status.RecipeFailed(execution.RecipeStatusEvent{
-				Recipe:                         *r,
-				Msg:                            msg,
-				ValidationDurationMilliseconds: validationDurationMilliseconds,
-			})
-			return "", errors.New(msg)
+			event.Msg = fmt.Sprintf("encountered an error while validating receipt of data for %s: %s", r.Name, err)
 		}
 	} else {
 		log.Debugf("skipping validation due to missing validation query")
 	}
 
-	validationDurationMilliseconds = time.Since(start).Milliseconds()
-	i.status.RecipeInstalled(execution.RecipeStatusEvent{
-		Recipe:                         *r,
-		EntityGUID:                     entityGUID,
-		ValidationDurationMilliseconds: validationDurationMilliseconds,
-	})
+	event.ValidationDurationMilliseconds = time.Since(start).Milliseconds()
 
-	return entityGUID, nil
+	return event, err
+}
+
+// reportValidation reports the outcome of a recipe's validation.
+func (i *RecipeInstaller) reportValidation(event execution.RecipeStatusEvent, err error) error {
+	if err != nil {
+		i.status.RecipeFailed(event)
+		return errors.New(event.Msg)
+	}
+
+	i.status.RecipeInstalled(event)
+
+	return nil
 }
 
 func (i *RecipeInstaller) executeAndValidateWithProgress(ctx context.Context, m *types.DiscoveryManifest, r *types.Recipe) (string, error) {
+	return i.withProgress(ctx, m, r, func(vars types.RecipeVars) (string, error) {
+		return i.executeAndValidate(ctx, m, r, vars)
+	})
+}
+
+// executeWithProgress executes a recipe and returns the vars it was prepared
+// with, which its rollback tasks run with should its validation fail.
+func (i *RecipeInstaller) executeWithProgress(ctx context.Context, m *types.DiscoveryManifest, r *types.Recipe) (types.RecipeVars, error) {
+	var recipeVars types.RecipeVars
+	_, err := i.withProgress(ctx, m, r, func(vars types.RecipeVars) (string, error) {
+		recipeVars = vars
+		return "", i.execute(ctx, m, r, vars)
+	})
+
+	return recipeVars, err
+}
+
+// withProgress prepares a recipe and runs it with a progress indicator.
+func (i *RecipeInstaller) withProgress(ctx context.Context, m *types.DiscoveryManifest, r *types.Recipe, run func(types.RecipeVars) (string, error)) (string, error) {
 	msg := fmt.Sprintf("Installing %s", r.Name)
+	if i.DryRun {
+		msg = fmt.Sprintf("Planning %s", r.Name)
+	}
+
 	i.progressIndicator.Start(msg)
 	defer func() { i.progressIndicator.Stop() }()
 
@@ -276,7 +550,7 @@ func (i *RecipeInstaller) executeAndValidateWithProgress(ctx context.Context, m
 		return "", err
 	}
 
-	entityGUID, err := i.executeAndValidate(ctx, m, r, vars)
+	entityGUID, err := run(vars)
 	if err != nil {
 		i.progressIndicator.Fail(msg)
 		return "", err
@@ -306,7 +580,10 @@ func (i *RecipeInstaller) fetchRecipeAndReportAvailable(ctx context.Context, m *
 		return nil, err
 	}
 
-	i.status.RecipeAvailable(*r)
+	// A resumed install keeps the status of a recipe it does not run again.
+	if i.ShouldResumeRecipe(recipeName) {
+		i.status.RecipeAvailable(*r)
+	}
 
 	return r, nil
 }
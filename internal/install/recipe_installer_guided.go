@@ -26,7 +26,10 @@ func (i *RecipeInstaller) guidedInstall(ctx context.Context, m *types.DiscoveryM
 	if err != nil {
 		return err
 	}
-	recipesForInstallation = append(recipesForInstallation, *infraAgentRecipe)
+
+	if i.ShouldResumeRecipe(types.InfraAgentRecipeName) {
+		recipesForInstallation = append(recipesForInstallation, *infraAgentRecipe)
+	}
 
 	// Fetch the logging recipe and mark it as available.
 	loggingRecipe, err := i.fetchRecipeAndReportAvailable(ctx, m, types.LoggingRecipeName)
@@ -39,8 +42,11 @@ func (i *RecipeInstaller) guidedInstall(ctx context.Context, m *types.DiscoveryM
 		return fmt.Errorf("--skipInfra is only applicable to targeted installation. Run newrelic install --help for usage")
 	}
 
-	// Mark the logging recipe as skipped if necessary.
-	if i.SkipLoggingInstall {
+	// Mark the logging recipe as skipped if necessary.  A resumed install keeps
+	// the status of a logging recipe it does not run again.
+	if !i.ShouldResumeRecipe(types.LoggingRecipeName) {
+		log.Debug("not resuming logging")
+	} else if i.SkipLoggingInstall {
 		i.status.RecipeSkipped(execution.RecipeStatusEvent{Recipe: *loggingRecipe})
 	} else {
 		recommendedIntegrations = append(recommendedIntegrations, *loggingRecipe)
@@ -78,14 +84,21 @@ func (i *RecipeInstaller) guidedInstall(ctx context.Context, m *types.DiscoveryM
 	// Remove logging from the integrations list since it will be installed explicitly.
 	selectedIntegrations = i.removeRecipes(selectedIntegrations, *loggingRecipe)
 
-	// Install the infra agent.
-	log.Debugf("Installing infrastructure agent")
-	entityGUID, err := i.executeAndValidateWithProgress(ctx, m, infraAgentRecipe)
-	if err != nil {
-		log.Error(i.failMessage(types.InfraAgentRecipeName))
+	if err = i.assertInputVarsProvided(recipesForInstallation); err != nil {
 		return err
 	}
-	log.Debugf("Done installing infrastructure agent.")
+
+	// Install the infra agent, unless a resumed install already has.
+	entityGUID := i.status.HostEntityGUID()
+	if i.ShouldResumeRecipe(types.InfraAgentRecipeName) {
+		log.Debugf("Installing infrastructure agent")
+		entityGUID, err = i.executeAndValidateWithProgress(ctx, m, infraAgentRecipe)
+		if err != nil {
+			log.Error(i.failMessage(types.InfraAgentRecipeName))
+			return err
+		}
+		log.Debugf("Done installing infrastructure agent.")
+	}
 
 	// Now that we have a host entity GUID, report recommended integrations
 	// with application targets for that host.
@@ -128,7 +141,7 @@ func (i *RecipeInstaller) installLogging(ctx context.Context, m *types.Discovery
 	log.WithFields(log.Fields{
 		"recipe_count": len(recipes),
 	}).Debug("filtering log matches")
-	logMatches, err := i.fileFilterer.Filter(utils.SignalCtx, recipes)
+	logMatches, err := i.fileFilterer.Filter(utils.SignalCtx, m, recipes)
 	if err != nil {
 		return err
 	}
@@ -162,10 +175,13 @@ func (i *RecipeInstaller) installLogging(ctx context.Context, m *types.Discovery
 	// We need to keep this var for backwards compatibility until recipes have been updated with the new var.
 	r.AddVar("DISCOVERED_LOG_FILES", loggingConfig{Logs: acceptedLogMatches})
 
-	// Build a comma-separated list of discovered log file paths
+	// Build a comma-separated list of discovered log file paths.  Journald
+	// units have no file.
 	discoveredLogFiles := []string{}
 	for _, logMatch := range acceptedLogMatches {
-		discoveredLogFiles = append(discoveredLogFiles, logMatch.File)
+		if logMatch.File != "" {
+			discoveredLogFiles = append(discoveredLogFiles, logMatch.File)
+		}
 	}
 
 	// NR_DISCOVERED_LOG_FILES will be replacing DISCOVERED_LOG_FILES in recipes.
@@ -180,6 +196,19 @@ func (i *RecipeInstaller) installLogging(ctx context.Context, m *types.Discovery
 	return err
 }
 
+// resumedRecipes leaves out the recipes a resumed install does not run again,
+// so that they are neither prompted for nor reported.
+func (i *RecipeInstaller) resumedRecipes(recipes []types.Recipe) []types.Recipe {
+	resumed := []types.Recipe{}
+	for _, r := range recipes {
+		if i.ShouldResumeRecipe(r.Name) {
+			resumed = append(resumed, r)
+		}
+	}
+
+	return resumed
+}
+
 func (i *RecipeInstaller) fetchRecommendations(m *types.DiscoveryManifest) ([]types.Recipe, error) {
 	log.Debug("fetching recommended recipes")
 
@@ -189,6 +218,8 @@ func (i *RecipeInstaller) fetchRecommendations(m *types.DiscoveryManifest) ([]ty
 	}
 
 	recommendations = i.filterRecommendations(recommendations)
+	recommendations = i.resumedRecipes(recommendations)
+	recommendations = i.skipUnsupportedRecipes(m, recommendations)
 
 	if log.IsLevelEnabled(log.DebugLevel) {
 		names := []string{}
@@ -205,6 +236,24 @@ func (i *RecipeInstaller) fetchRecommendations(m *types.DiscoveryManifest) ([]ty
 	return recommendations, nil
 }
 
+// skipUnsupportedRecipes marks recommended recipes as skipped when the packages
+// they require are missing or installed in an unsupported version, so that they
+// are not offered for install.
+func (i *RecipeInstaller) skipUnsupportedRecipes(m *types.DiscoveryManifest, recipes []types.Recipe) []types.Recipe {
+	supported := []types.Recipe{}
+	for _, r := range recipes {
+		if err := m.CheckPackageRequirements(r); err != nil {
+			log.Infof("Skipping %s: %s", r.DisplayName, err)
+			i.status.RecipeSkipped(execution.RecipeStatusEvent{Recipe: r, Msg: err.Error()})
+			continue
+		}
+
+		supported = append(supported, r)
+	}
+
+	return supported
+}
+
 // Filter out infra and logging recipes from recommendations, since they are
 // handled explicitly elsewhere.  This avoids duplicate installation.
 func (i *RecipeInstaller) filterRecommendations(recipes []types.Recipe) []types.Recipe {
@@ -238,8 +287,19 @@ func (i *RecipeInstaller) userAccepts(msg string) (bool, error) {
 }
 
 func (i *RecipeInstaller) userAcceptsLogFile(match types.LogMatch) (bool, error) {
-	msg := fmt.Sprintf("Files have been found at the following pattern: %s Do you want to watch them?", match.File)
-	return i.userAccepts(msg)
+	msg := fmt.Sprintf("Files have been found at the following pattern: %s", match.File)
+	if match.File == "" {
+		msg = fmt.Sprintf("Logs have been found in the journal of the %s unit.", match.Systemd)
+	}
+
+	// Show the latest lines so the user can tell what the log contains.
+	if match.Preview != "" {
+		msg = fmt.Sprintf("%s\n  %s\n", msg, strings.ReplaceAll(match.Preview, "\n", "\n  "))
+	} else {
+		msg += " "
+	}
+
+	return i.userAccepts(msg + "Do you want to watch them?")
 }
 
 func (i *RecipeInstaller) recipeInRecipes(recipe types.Recipe, recipes []types.Recipe) bool {
@@ -2,6 +2,7 @@ package install
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/url"
 
@@ -12,25 +13,23 @@ import (
 	"github.com/newrelic/newrelic-cli/internal/utils"
 )
 
-func (i *RecipeInstaller) resolveRecipeDependencies(ctx context.Context, recipe types.Recipe, manifest *types.DiscoveryManifest) ([]*types.Recipe, error) {
-	dependencies := []*types.Recipe{}
-
-	if len(recipe.Dependencies) == 0 {
-		return dependencies, nil
-	}
-
-	for _, recipeName := range recipe.Dependencies {
-		recipe, err := i.fetchRecipeAndReportAvailable(ctx, manifest, recipeName)
-		if err != nil {
-			return dependencies, err
+// resolveDependencyGraph resolves the transitive dependencies of the provided
+// recipes.  Dependencies that are skipped or already installed are left out of
+// the graph.
+func (i *RecipeInstaller) resolveDependencyGraph(provided []types.Recipe, fetch recipes.DependencyFetchFunc) (*recipes.DependencyGraph, error) {
+	return recipes.ResolveDependencies(provided, func(name string) (*types.Recipe, error) {
+		if i.SkipInfra && name == types.InfraAgentRecipeName {
+			log.Debugf("Skipping dependency %s, infra agent install is skipped.", name)
+			return nil, nil
 		}
 
-		if recipe != nil {
-			dependencies = append(dependencies, recipe)
+		if i.status.IsRecipeInstalled(name) {
+			log.Debugf("Skipping dependency %s, it is already installed.", name)
+			return nil, nil
 		}
-	}
 
-	return dependencies, nil
+		return fetch(name)
+	})
 }
 
 func (i *RecipeInstaller) collectRecipes(m *types.DiscoveryManifest) ([]types.Recipe, error) {
@@ -85,8 +84,6 @@ func (i *RecipeInstaller) collectRecipes(m *types.DiscoveryManifest) ([]types.Re
 }
 
 func (i *RecipeInstaller) targetedInstall(ctx context.Context, m *types.DiscoveryManifest) error {
-	var recipes []types.Recipe
-
 	i.status.SetTargetedInstall()
 
 	providedRecipes, err := i.collectRecipes(m)
@@ -94,29 +91,33 @@ func (i *RecipeInstaller) targetedInstall(ctx context.Context, m *types.Discover
 		return err
 	}
 
-	for _, r := range providedRecipes {
-		dependencies, err := i.resolveRecipeDependencies(ctx, r, m)
-		if err != nil {
-			return err
-		}
+	graph, err := i.resolveDependencyGraph(providedRecipes, func(name string) (*types.Recipe, error) {
+		return i.fetchRecipeAndReportAvailable(ctx, m, name)
+	})
+	if err != nil {
+		return err
+	}
 
-		for _, d := range dependencies {
-			if i.SkipInfra && types.InfraAgentRecipeName == d.Name {
-				continue
-			} else {
-				recipes = append(recipes, *d)
-			}
-		}
-		recipes = append(recipes, r)
+	recipesForInstallation, err := graph.InstallOrder()
+	if err != nil {
+		return err
 	}
 
 	// Show the user what will be installed.
-	i.status.RecipesAvailable(recipes)
-	i.status.RecipesSelected(recipes)
+	i.status.RecipesAvailable(recipesForInstallation)
+	i.status.RecipesSelected(recipesForInstallation)
+
+	if err := i.assertInputVarsProvided(recipesForInstallation); err != nil {
+		return err
+	}
+
+	if err := assertPackageRequirementsMet(m, recipesForInstallation); err != nil {
+		return err
+	}
 
 	// Install the requested integrations.
 	log.Debugf("Installing integrations")
-	if err := i.installRecipes(ctx, m, recipes); err != nil {
+	if err := i.installRecipes(ctx, m, recipesForInstallation); err != nil {
 		return err
 	}
 
@@ -125,6 +126,18 @@ func (i *RecipeInstaller) targetedInstall(ctx context.Context, m *types.Discover
 	return nil
 }
 
+// assertPackageRequirementsMet ensures the packages the requested recipes
+// require are installed in a supported version before anything runs.
+func assertPackageRequirementsMet(m *types.DiscoveryManifest, recipesForInstall []types.Recipe) error {
+	for _, r := range recipesForInstall {
+		if err := m.CheckPackageRequirements(r); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (i *RecipeInstaller) recipeFromPath(recipePath string) (*types.Recipe, error) {
 	recipeURL, parseErr := url.Parse(recipePath)
 	if parseErr == nil && recipeURL.Scheme != "" {
@@ -164,3 +177,25 @@ func (i *RecipeInstaller) fetchWarn(m *types.DiscoveryManifest, recipeName strin
 
 	return r
 }
+
+// DependencyGraph discovers the host and returns the dependency graph of the
+// provided recipes without installing them or reporting any status.
+func (i *RecipeInstaller) DependencyGraph(ctx context.Context) (*recipes.DependencyGraph, error) {
+	if !i.RecipesProvided() {
+		return nil, errors.New("at least one recipe name or path is required to resolve a dependency graph")
+	}
+
+	m, err := i.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	providedRecipes, err := i.collectRecipes(m)
+	if err != nil {
+		return nil, err
+	}
+
+	return i.resolveDependencyGraph(providedRecipes, func(name string) (*types.Recipe, error) {
+		return i.fetch(ctx, m, name)
+	})
+}
@@ -711,6 +711,72 @@ func TestInstall_TargetedInstall_InstallsInfraAgentDependency(t *testing.T) {
 	require.Equal(t, 1, statusReporters[0].(*execution.MockStatusReporter).InstallCompleteCallCount)
 }
 
+func TestInstall_TargetedInstall_InstallsTransitiveDependencies(t *testing.T) {
+	ic := InstallerContext{
+		RecipeNames: []string{"integration"},
+	}
+	statusReporters = []execution.StatusSubscriber{execution.NewMockStatusReporter()}
+	status = execution.NewInstallStatus(statusReporters)
+	f = recipes.NewMockRecipeFetcher()
+	f.FetchRecommendationsVal = []types.Recipe{}
+	f.FetchRecipeVals = []types.Recipe{
+		{
+			Name:           "integration",
+			ValidationNRQL: "testNrql",
+			Dependencies:   []string{"agent", "runtime"},
+		},
+		{
+			Name:           "agent",
+			ValidationNRQL: "testNrql",
+			Dependencies:   []string{"runtime"},
+		},
+		{
+			Name:           "runtime",
+			ValidationNRQL: "testNrql",
+		},
+	}
+
+	v = validation.NewMockRecipeValidator()
+
+	i := RecipeInstaller{ic, d, l, mv, f, e, v, ff, status, p, pi, lkf}
+	err := i.Install()
+	require.NoError(t, err)
+	require.Equal(t, 1, f.FetchRecipeNameCount["runtime"])
+	require.Equal(t, 3, statusReporters[0].(*execution.MockStatusReporter).RecipeInstalledCallCount)
+	require.Equal(t, 1, statusReporters[0].(*execution.MockStatusReporter).ReportInstalled["runtime"])
+}
+
+func TestInstall_TargetedInstall_ValidatesConcurrently(t *testing.T) {
+	ic := InstallerContext{
+		RecipeNames: []string{"a", "b"},
+	}
+	statusReporters = []execution.StatusSubscriber{execution.NewMockStatusReporter()}
+	status = execution.NewInstallStatus(statusReporters)
+	f = recipes.NewMockRecipeFetcher()
+	f.FetchRecommendationsVal = []types.Recipe{}
+	f.FetchRecipeVals = []types.Recipe{
+		{
+			Name:           "a",
+			ValidationNRQL: "testNrql",
+		},
+		{
+			Name:           "b",
+			ValidationNRQL: "testNrql",
+		},
+	}
+
+	v = validation.NewMockRecipeValidator()
+	v.ValidateErr = errors.New("validationErr")
+
+	i := RecipeInstaller{ic, d, l, mv, f, e, v, ff, status, p, pi, lkf}
+	err := i.Install()
+	require.NoError(t, err)
+	require.Equal(t, 2, v.ValidateCallCount)
+	require.Equal(t, 2, statusReporters[0].(*execution.MockStatusReporter).RecipeFailedCallCount)
+	require.Equal(t, 1, statusReporters[0].(*execution.MockStatusReporter).ReportFailed["a"])
+	require.Equal(t, 1, statusReporters[0].(*execution.MockStatusReporter).ReportFailed["b"])
+}
+
 func TestInstall_TargetedInstallInfraAgent_NoInfraAgentDuplicate(t *testing.T) {
 	log.SetLevel(log.TraceLevel)
 	ic := InstallerContext{
@@ -848,6 +914,343 @@ func TestInstall_GuidReport(t *testing.T) {
 	}
 }
 
+func TestInstall_DryRunSkipsValidation(t *testing.T) {
+	ic := InstallerContext{
+		DryRun:             true,
+		SkipLoggingInstall: true,
+		AssumeYes:          true,
+	}
+	statusReporters = []execution.StatusSubscriber{execution.NewMockStatusReporter()}
+	status = execution.NewInstallStatus(statusReporters)
+	f = recipes.NewMockRecipeFetcher()
+	f.FetchRecipeVals = []types.Recipe{
+		{
+			Name:           types.InfraAgentRecipeName,
+			DisplayName:    types.InfraAgentRecipeName,
+			ValidationNRQL: "testNrql",
+		},
+		{
+			Name:           types.LoggingRecipeName,
+			DisplayName:    types.LoggingRecipeName,
+			ValidationNRQL: "testNrql",
+		},
+	}
+	v = validation.NewMockRecipeValidator()
+
+	i := RecipeInstaller{ic, d, l, mv, f, e, v, ff, status, p, pi, lkf}
+	err := i.Install()
+	require.NoError(t, err)
+	require.Equal(t, 0, v.ValidateCallCount)
+	require.Equal(t, 1, statusReporters[0].(*execution.MockStatusReporter).RecipeInstalledCallCount)
+}
+
+func TestInstall_OfflineSkipsValidation(t *testing.T) {
+	ic := InstallerContext{
+		Offline:            true,
+		SkipLoggingInstall: true,
+		AssumeYes:          true,
+	}
+	statusReporters = []execution.StatusSubscriber{execution.NewMockStatusReporter()}
+	status = execution.NewInstallStatus(statusReporters)
+	f = recipes.NewMockRecipeFetcher()
+	f.FetchRecipeVals = []types.Recipe{
+		{
+			Name:           types.InfraAgentRecipeName,
+			DisplayName:    types.InfraAgentRecipeName,
+			ValidationNRQL: "testNrql",
+		},
+	}
+	v = validation.NewMockRecipeValidator()
+	e = execution.NewMockRecipeExecutor()
+
+	i := RecipeInstaller{ic, d, l, mv, f, e, v, ff, status, p, pi, lkf}
+	err := i.Install()
+	require.NoError(t, err)
+	require.Equal(t, 0, v.ValidateCallCount)
+	require.Equal(t, 0, statusReporters[0].(*execution.MockStatusReporter).RecipeFailedCallCount)
+	require.Equal(t, 1, statusReporters[0].(*execution.MockStatusReporter).RecipeInstalledCallCount)
+}
+
+func TestUninstall_RecipeUninstalled(t *testing.T) {
+	ic := InstallerContext{
+		RecipeNames: []string{testRecipeName},
+	}
+	statusReporters = []execution.StatusSubscriber{execution.NewMockStatusReporter()}
+	status = execution.NewInstallStatus(statusReporters)
+	f = recipes.NewMockRecipeFetcher()
+	f.FetchRecipeVals = []types.Recipe{
+		{
+			Name:        testRecipeName,
+			DisplayName: testRecipeName,
+			File: `
+name: Test Recipe
+uninstall:
+  version: "3"
+  tasks:
+    default:
+`,
+		},
+	}
+
+	i := RecipeInstaller{ic, d, l, mv, f, e, v, ff, status, p, pi, lkf}
+	err := i.Uninstall()
+	require.NoError(t, err)
+	require.Equal(t, 1, statusReporters[0].(*execution.MockStatusReporter).RecipeUninstalledCallCount)
+	require.Equal(t, 0, statusReporters[0].(*execution.MockStatusReporter).RecipeFailedCallCount)
+	require.True(t, status.HasUninstalledRecipes)
+}
+
+func TestUninstall_FailsWithoutUninstallSection(t *testing.T) {
+	ic := InstallerContext{
+		RecipeNames: []string{testRecipeName},
+	}
+	statusReporters = []execution.StatusSubscriber{execution.NewMockStatusReporter()}
+	status = execution.NewInstallStatus(statusReporters)
+	f = recipes.NewMockRecipeFetcher()
+	f.FetchRecipeVals = []types.Recipe{
+		{
+			Name:        testRecipeName,
+			DisplayName: testRecipeName,
+			File:        "name: Test Recipe",
+		},
+	}
+
+	i := RecipeInstaller{ic, d, l, mv, f, e, v, ff, status, p, pi, lkf}
+	err := i.Uninstall()
+	require.Error(t, err)
+	require.Equal(t, 0, statusReporters[0].(*execution.MockStatusReporter).RecipeUninstalledCallCount)
+	require.Equal(t, 1, statusReporters[0].(*execution.MockStatusReporter).RecipeFailedCallCount)
+}
+
+const testRollbackRecipeFile = `
+name: Test Recipe
+rollback:
+  version: "3"
+  tasks:
+    default:
+`
+
+func TestInstall_RollsBackRecipeFailingValidation(t *testing.T) {
+	ic := InstallerContext{
+		RecipeNames: []string{testRecipeName},
+		SkipInfra:   true,
+	}
+	statusReporter := execution.NewMockStatusReporter()
+	status = execution.NewInstallStatus([]execution.StatusSubscriber{statusReporter})
+	f = recipes.NewMockRecipeFetcher()
+	f.FetchRecipeVals = []types.Recipe{
+		{
+			Name:           testRecipeName,
+			DisplayName:    testRecipeName,
+			ValidationNRQL: "testNrql",
+			File:           testRollbackRecipeFile,
+		},
+	}
+	v = validation.NewMockRecipeValidator()
+	v.ValidateErr = errors.New("validationErr")
+
+	i := RecipeInstaller{ic, d, l, mv, f, e, v, ff, status, p, pi, lkf}
+	err := i.Install()
+	require.Error(t, err)
+	require.Equal(t, 1, statusReporter.RecipeFailedCallCount)
+	require.Equal(t, 1, statusReporter.RecipeRolledBackCallCount)
+	require.Equal(t, execution.RecipeStatusTypes.FAILED, status.Statuses[0].Status)
+	require.Equal(t, execution.RollbackStatusTypes.SUCCEEDED, status.Statuses[0].Rollback.Status)
+}
+
+func TestInstall_RollbackFailureIsRecorded(t *testing.T) {
+	ic := InstallerContext{
+		RecipeNames: []string{testRecipeName},
+		SkipInfra:   true,
+	}
+	statusReporter := execution.NewMockStatusReporter()
+	status = execution.NewInstallStatus([]execution.StatusSubscriber{statusReporter})
+	f = recipes.NewMockRecipeFetcher()
+	f.FetchRecipeVals = []types.Recipe{
+		{
+			Name:        testRecipeName,
+			DisplayName: testRecipeName,
+			File:        testRollbackRecipeFile,
+		},
+	}
+
+	i := RecipeInstaller{ic, d, l, mv, f, execution.NewMockFailingRecipeExecutor(), v, ff, status, p, pi, lkf}
+	err := i.Install()
+	require.Error(t, err)
+	require.Equal(t, 1, statusReporter.RecipeRolledBackCallCount)
+	require.Equal(t, execution.RollbackStatusTypes.FAILED, status.Statuses[0].Rollback.Status)
+	require.Contains(t, status.Statuses[0].Rollback.Error.Message, "rolling back")
+}
+
+func TestInstall_NoRollback(t *testing.T) {
+	ic := InstallerContext{
+		RecipeNames: []string{testRecipeName},
+		SkipInfra:   true,
+		NoRollback:  true,
+	}
+	statusReporter := execution.NewMockStatusReporter()
+	status = execution.NewInstallStatus([]execution.StatusSubscriber{statusReporter})
+	f = recipes.NewMockRecipeFetcher()
+	f.FetchRecipeVals = []types.Recipe{
+		{
+			Name:        testRecipeName,
+			DisplayName: testRecipeName,
+			File:        testRollbackRecipeFile,
+		},
+	}
+
+	i := RecipeInstaller{ic, d, l, mv, f, execution.NewMockFailingRecipeExecutor(), v, ff, status, p, pi, lkf}
+	err := i.Install()
+	require.Error(t, err)
+	require.Equal(t, 1, statusReporter.RecipeFailedCallCount)
+	require.Equal(t, 0, statusReporter.RecipeRolledBackCallCount)
+	require.Nil(t, status.Statuses[0].Rollback)
+}
+
+func TestInstall_ResumeSkipsInstalledRecipes(t *testing.T) {
+	ic := InstallerContext{}
+	statusReporter := execution.NewMockStatusReporter()
+	status = execution.NewInstallStatus([]execution.StatusSubscriber{statusReporter})
+	f = recipes.NewMockRecipeFetcher()
+	f.FetchRecipeVals = []types.Recipe{
+		{
+			Name:         testRecipeName,
+			DisplayName:  testRecipeName,
+			Dependencies: []string{types.InfraAgentRecipeName},
+		},
+		{
+			Name:        types.InfraAgentRecipeName,
+			DisplayName: types.InfraAgentRecipeName,
+		},
+	}
+
+	prior := &execution.InstallStatus{
+		DocumentID:      "priorDocumentID",
+		EntityGUIDs:     []string{"INFRAGUID"},
+		TargetedInstall: true,
+		Statuses: []*execution.RecipeStatus{
+			{Name: types.InfraAgentRecipeName, Status: execution.RecipeStatusTypes.INSTALLED, EntityGUID: "INFRAGUID"},
+			{Name: types.LoggingRecipeName, Status: execution.RecipeStatusTypes.SKIPPED},
+			{Name: testRecipeName, Status: execution.RecipeStatusTypes.FAILED},
+		},
+	}
+
+	i := RecipeInstaller{ic, d, l, mv, f, e, v, ff, status, p, pi, lkf}
+	err := i.ResumeFrom(prior)
+	require.NoError(t, err)
+	require.Equal(t, []string{testRecipeName}, i.RecipeNames)
+
+	err = i.Install()
+	require.NoError(t, err)
+	require.Equal(t, "priorDocumentID", status.DocumentID)
+	require.Equal(t, 1, statusReporter.ReportInstalled[testRecipeName])
+	require.Equal(t, 0, statusReporter.ReportInstalled[types.InfraAgentRecipeName])
+	require.True(t, status.IsRecipeInstalled(types.InfraAgentRecipeName))
+	require.True(t, status.IsRecipeInstalled(testRecipeName))
+}
+
+func TestInstall_ResumeGuidedInstall(t *testing.T) {
+	ic := InstallerContext{AssumeYes: true}
+	statusReporter := execution.NewMockStatusReporter()
+	status = execution.NewInstallStatus([]execution.StatusSubscriber{statusReporter})
+	f = recipes.NewMockRecipeFetcher()
+	f.FetchRecipeVals = []types.Recipe{
+		{
+			Name:        types.InfraAgentRecipeName,
+			DisplayName: types.InfraAgentRecipeName,
+		},
+		{
+			Name:        types.LoggingRecipeName,
+			DisplayName: types.LoggingRecipeName,
+		},
+	}
+	f.FetchRecommendationsVal = []types.Recipe{
+		{Name: testRecipeName, DisplayName: testRecipeName},
+		{Name: anotherTestRecipeName, DisplayName: anotherTestRecipeName},
+	}
+	fileFilterer := discovery.NewMockFileFilterer()
+
+	prior := &execution.InstallStatus{
+		DocumentID:  "priorDocumentID",
+		EntityGUIDs: []string{"INFRAGUID"},
+		Statuses: []*execution.RecipeStatus{
+			{Name: types.InfraAgentRecipeName, Status: execution.RecipeStatusTypes.INSTALLED, EntityGUID: "INFRAGUID"},
+			{Name: types.LoggingRecipeName, Status: execution.RecipeStatusTypes.FAILED},
+			{Name: testRecipeName, Status: execution.RecipeStatusTypes.INSTALLED},
+			{Name: anotherTestRecipeName, Status: execution.RecipeStatusTypes.CANCELED},
+		},
+	}
+
+	i := RecipeInstaller{ic, d, fileFilterer, mv, f, e, v, ff, status, p, pi, lkf}
+	err := i.ResumeFrom(prior)
+	require.NoError(t, err)
+	require.False(t, i.RecipesProvided())
+	require.Equal(t, []string{types.LoggingRecipeName, anotherTestRecipeName}, i.ResumedRecipeNames)
+
+	err = i.Install()
+	require.NoError(t, err)
+	require.False(t, status.IsTargetedInstall())
+	require.Equal(t, 1, fileFilterer.FilterCallCount)
+	require.Equal(t, 0, statusReporter.ReportInstalled[types.InfraAgentRecipeName])
+	require.Equal(t, 0, statusReporter.ReportInstalled[testRecipeName])
+	require.Equal(t, 1, statusReporter.ReportInstalled[types.LoggingRecipeName])
+	require.Equal(t, 1, statusReporter.ReportInstalled[anotherTestRecipeName])
+	require.True(t, status.IsRecipeInstalled(types.InfraAgentRecipeName))
+	require.True(t, status.IsRecipeInstalled(testRecipeName))
+}
+
+func TestInstall_ResumeRequiresFailedRecipes(t *testing.T) {
+	ic := InstallerContext{}
+	status = execution.NewInstallStatus([]execution.StatusSubscriber{})
+	prior := &execution.InstallStatus{
+		DocumentID: "priorDocumentID",
+		Statuses: []*execution.RecipeStatus{
+			{Name: types.InfraAgentRecipeName, Status: execution.RecipeStatusTypes.INSTALLED},
+		},
+	}
+
+	i := RecipeInstaller{ic, d, l, mv, f, e, v, ff, status, p, pi, lkf}
+	err := i.ResumeFrom(prior)
+	require.Error(t, err)
+}
+
+func TestInstall_VarsFileMissingInputVars(t *testing.T) {
+	ic := InstallerContext{
+		RecipeNames: []string{testRecipeName},
+		Answers: &execution.RecipeAnswers{
+			Recipes: map[string]map[string]execution.AnswerValue{
+				testRecipeName: {
+					"PROVIDED": {Value: "value"},
+				},
+			},
+		},
+	}
+	statusReporter := execution.NewMockStatusReporter()
+	status = execution.NewInstallStatus([]execution.StatusSubscriber{statusReporter})
+	f = recipes.NewMockRecipeFetcher()
+	f.FetchRecipeVals = []types.Recipe{
+		{
+			Name:        testRecipeName,
+			DisplayName: testRecipeName,
+			File: `
+name: Test Recipe
+inputVars:
+  - name: PROVIDED
+  - name: MISSING_ONE
+  - name: MISSING_TWO
+`,
+		},
+	}
+
+	i := RecipeInstaller{ic, d, l, mv, f, e, v, ff, status, p, pi, lkf}
+	err := i.Install()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "MISSING_ONE (Test Recipe)")
+	require.Contains(t, err.Error(), "MISSING_TWO (Test Recipe)")
+	require.NotContains(t, err.Error(), "PROVIDED")
+	require.Equal(t, 0, statusReporter.RecipeInstallingCallCount)
+}
+
 func fetchRecipeFileFunc(recipeURL *url.URL) (*recipes.RecipeFile, error) {
 	return testRecipeFile, nil
 }
@@ -855,3 +1258,28 @@ func fetchRecipeFileFunc(recipeURL *url.URL) (*recipes.RecipeFile, error) {
 func loadRecipeFileFunc(filename string) (*recipes.RecipeFile, error) {
 	return testRecipeFile, nil
 }
+
+func TestInstall_TargetedInstall_UnsupportedPackageVersion(t *testing.T) {
+	ic := InstallerContext{
+		RecipeNames: []string{testRecipeName},
+	}
+	statusReporter := execution.NewMockStatusReporter()
+	status = execution.NewInstallStatus([]execution.StatusSubscriber{statusReporter})
+	f = recipes.NewMockRecipeFetcher()
+	f.FetchRecipeVals = []types.Recipe{
+		{
+			Name:             testRecipeName,
+			DisplayName:      testRecipeName,
+			RequiredPackages: []types.PackageRequirement{{Name: "nginx", Version: ">= 1.18"}},
+		},
+	}
+
+	pd := discovery.NewMockDiscoverer()
+	pd.DiscoveryManifest.AddPackage(types.Package{Name: "nginx", Version: "1.14.0-0ubuntu1.7", Source: "dpkg"})
+
+	i := RecipeInstaller{ic, pd, l, mv, f, e, v, ff, status, p, pi, lkf}
+	err := i.Install()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "requires package nginx >= 1.18, but version 1.14.0-0ubuntu1.7 is installed")
+	require.Equal(t, 0, statusReporter.RecipeInstallingCallCount)
+}
@@ -0,0 +1,417 @@
+package install
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+
+	"github.com/newrelic/newrelic-cli/internal/install/discovery"
+	"github.com/newrelic/newrelic-cli/internal/install/execution"
+	"github.com/newrelic/newrelic-cli/internal/install/recipes"
+	"github.com/newrelic/newrelic-cli/internal/install/types"
+	"github.com/newrelic/newrelic-cli/internal/install/validation"
+)
+
+const recipeTestLicenseKey = "RECIPE-TEST-LICENSE-KEY"
+
+var (
+	templateVarRegex = regexp.MustCompile(`{{[^}]*?\.([A-Za-z_][A-Za-z0-9_]*)[^}]*}}`)
+	shellVarRegex    = regexp.MustCompile(`\$\{?([A-Za-z_][A-Za-z0-9_]*)`)
+	exitStatusRegex  = regexp.MustCompile(`exit status (\d+)`)
+)
+
+// RecipeTestSpec describes a test of a recipe: the host it runs on, the values
+// of its vars, how validation behaves, and what is expected after it runs.
+//
+//	recipe: mysql.yml
+//	manifest:
+//	  hostname: test-host
+//	  os: linux
+//	  platformFamily: debian
+//	vars:
+//	  NR_CLI_DB_USERNAME: newrelic
+//	validation:
+//	  entityGuid: TESTGUID
+//	expect:
+//	  exitCode: 0
+//	  files:
+//	    - path: "{{.HOME}}/mysql-config.yml"
+//	      contains: "username: newrelic"
+//	  varsReferenced:
+//	    - NR_CLI_DB_USERNAME
+type RecipeTestSpec struct {
+	// Recipe is the path to the recipe file, relative to the spec file.
+	Recipe     string                 `yaml:"recipe"`
+	Manifest   RecipeTestManifest     `yaml:"manifest"`
+	Vars       map[string]string      `yaml:"vars"`
+	Env        map[string]string      `yaml:"env"`
+	Validation RecipeTestValidation   `yaml:"validation"`
+	Expect     RecipeTestExpectations `yaml:"expect"`
+}
+
+// RecipeTestManifest is the fake discovery manifest a recipe test runs with.
+type RecipeTestManifest struct {
+	Hostname        string `yaml:"hostname"`
+	OS              string `yaml:"os"`
+	Platform        string `yaml:"platform"`
+	PlatformFamily  string `yaml:"platformFamily"`
+	PlatformVersion string `yaml:"platformVersion"`
+	KernelArch      string `yaml:"kernelArch"`
+	KernelVersion   string `yaml:"kernelVersion"`
+}
+
+// RecipeTestValidation stubs the validation of a recipe's data.  The stubbed
+// validator always reports data, for the given entity GUID.
+type RecipeTestValidation struct {
+	EntityGUID string `yaml:"entityGuid"`
+}
+
+// RecipeTestExpectations are asserted after a recipe test runs.  Referenced
+// vars are found by reading the install tasks, not by tracing their execution,
+// so a var that is referenced in a task that never runs still passes.
+type RecipeTestExpectations struct {
+	ExitCode       int                      `yaml:"exitCode"`
+	Files          []RecipeTestFileExpected `yaml:"files"`
+	VarsReferenced []string                 `yaml:"varsReferenced"`
+	OutputContains []string                 `yaml:"outputContains"`
+}
+
+// RecipeTestFileExpected is a file expected to exist, or not exist, after a
+// recipe test runs.  Paths are templated with HOME and WORKDIR, and relative
+// paths are resolved against the working directory.
+type RecipeTestFileExpected struct {
+	Path     string `yaml:"path"`
+	Contains string `yaml:"contains"`
+	Absent   bool   `yaml:"absent"`
+}
+
+// RecipeTestResult is the outcome of a recipe test.
+type RecipeTestResult struct {
+	SpecPath   string   `json:"specPath"`
+	RecipeName string   `json:"recipeName"`
+	ExitCode   int      `json:"exitCode"`
+	EntityGUID string   `json:"entityGuid,omitempty"`
+	Failures   []string `json:"failures"`
+	Output     string   `json:"-"`
+}
+
+// Passed returns true when every expectation of the test was met.
+func (r *RecipeTestResult) Passed() bool {
+	return len(r.Failures) == 0
+}
+
+func (r *RecipeTestResult) failf(format string, args ...interface{}) {
+	r.Failures = append(r.Failures, fmt.Sprintf(format, args...))
+}
+
+// RecipeTester runs a recipe's install tasks in an isolated working directory
+// and HOME, with a fake discovery manifest and a stubbed validator, and asserts
+// the expectations of a test spec.  Only the working directory and HOME are
+// isolated; tasks that use absolute paths or elevated privileges still affect
+// the host they run on.
+type RecipeTester struct {
+	recipeFileFetcher recipes.RecipeFileFetcher
+	output            io.Writer
+}
+
+// NewRecipeTester returns a new instance of RecipeTester.  Task output is
+// copied to w as well as being captured for assertions.
+func NewRecipeTester(w io.Writer) *RecipeTester {
+	return &RecipeTester{
+		recipeFileFetcher: recipes.NewRecipeFileFetcher(),
+		output:            w,
+	}
+}
+
+// LoadRecipeTestSpec reads a recipe test spec file.
+func LoadRecipeTestSpec(path string) (*RecipeTestSpec, error) {
+	out, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read recipe test %s: %s", path, err)
+	}
+
+	var spec RecipeTestSpec
+	if err = yaml.UnmarshalStrict(out, &spec); err != nil {
+		return nil, fmt.Errorf("could not parse recipe test %s: %s", path, err)
+	}
+
+	if spec.Recipe == "" {
+		return nil, fmt.Errorf("recipe test %s does not name a recipe", path)
+	}
+
+	return &spec, nil
+}
+
+// Run runs the recipe test defined in the spec file at specPath.
+func (t *RecipeTester) Run(ctx context.Context, specPath string) (*RecipeTestResult, error) {
+	spec, err := LoadRecipeTestSpec(specPath)
+	if err != nil {
+		return nil, err
+	}
+
+	recipePath := spec.Recipe
+	if !filepath.IsAbs(recipePath) {
+		recipePath = filepath.Join(filepath.Dir(specPath), recipePath)
+	}
+
+	f, err := t.recipeFileFetcher.LoadRecipeFile(recipePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not load recipe %s: %s", recipePath, err)
+	}
+
+	r, err := finalizeRecipe(f)
+	if err != nil {
+		return nil, err
+	}
+
+	tmp, err := ioutil.TempDir("", "newrelic-recipe-test")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	home := filepath.Join(tmp, "home")
+	workDir := filepath.Join(tmp, "work")
+	for _, dir := range []string{home, workDir} {
+		if err = os.MkdirAll(dir, 0750); err != nil {
+			return nil, err
+		}
+	}
+
+	env := map[string]string{"HOME": home}
+	for k, v := range spec.Env {
+		env[k] = v
+	}
+
+	restore, err := setenv(env)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := restore(); err != nil {
+			log.Warnf("could not restore the environment after recipe test %s: %s", specPath, err)
+		}
+	}()
+
+	result := &RecipeTestResult{
+		SpecPath:   specPath,
+		RecipeName: r.Name,
+	}
+
+	var output bytes.Buffer
+	w := io.MultiWriter(&output, t.output)
+
+	e := execution.NewGoTaskRecipeExecutor()
+	e.Answers = answersFromVars(spec.Vars)
+	e.Dir = workDir
+	e.Stdout = w
+	e.Stderr = w
+
+	d := discovery.NewMockDiscoverer()
+	d.DiscoveryManifest = spec.Manifest.toDiscoveryManifest()
+
+	m, err := d.Discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	vars, err := e.Prepare(ctx, *m, *r, true, recipeTestLicenseKey)
+	if err != nil {
+		return nil, err
+	}
+
+	log.WithFields(log.Fields{
+		"name": r.Name,
+		"dir":  workDir,
+	}).Debug("running recipe test")
+
+	if err = e.Execute(ctx, *m, *r, vars); err != nil {
+		if err == types.ErrInterrupt {
+			return nil, err
+		}
+
+		result.ExitCode = exitCodeFromError(err)
+		log.Debugf("recipe test %s exited with %d: %s", specPath, result.ExitCode, err)
+	}
+
+	if result.ExitCode == 0 && r.ValidationNRQL != "" {
+		v := validation.NewMockRecipeValidator()
+		v.ValidateVal = spec.Validation.EntityGUID
+
+		if result.EntityGUID, err = v.Validate(ctx, *m, *r); err != nil {
+			result.failf("validation failed: %s", err)
+		}
+	}
+
+	result.Output = output.String()
+
+	if result.ExitCode != spec.Expect.ExitCode {
+		result.failf("expected exit code %d, got %d", spec.Expect.ExitCode, result.ExitCode)
+	}
+
+	for _, s := range spec.Expect.OutputContains {
+		if !strings.Contains(result.Output, s) {
+			result.failf("expected output to contain %q", s)
+		}
+	}
+
+	paths := map[string]string{"HOME": home, "WORKDIR": workDir}
+	for _, fe := range spec.Expect.Files {
+		assertRecipeTestFile(result, fe, paths)
+	}
+
+	referenced := referencedVars(f)
+	for _, name := range spec.Expect.VarsReferenced {
+		if !referenced[name] {
+			result.failf("expected var %s to be referenced by the install tasks", name)
+		}
+	}
+
+	return result, nil
+}
+
+func (m RecipeTestManifest) toDiscoveryManifest() *types.DiscoveryManifest {
+	dm := types.DiscoveryManifest{
+		Hostname:        m.Hostname,
+		OS:              m.OS,
+		Platform:        m.Platform,
+		PlatformFamily:  m.PlatformFamily,
+		PlatformVersion: m.PlatformVersion,
+		KernelArch:      m.KernelArch,
+		KernelVersion:   m.KernelVersion,
+	}
+
+	if dm.Hostname == "" {
+		dm.Hostname = "recipe-test-host"
+	}
+
+	if dm.OS == "" {
+		dm.OS = "linux"
+	}
+
+	return &dm
+}
+
+func answersFromVars(vars map[string]string) *execution.RecipeAnswers {
+	a := execution.RecipeAnswers{Global: map[string]execution.AnswerValue{}}
+	for k, v := range vars {
+		a.Global[k] = execution.AnswerValue{Value: v}
+	}
+
+	return &a
+}
+
+func assertRecipeTestFile(result *RecipeTestResult, fe RecipeTestFileExpected, paths map[string]string) {
+	tmpl, err := template.New("path").Parse(fe.Path)
+	if err != nil {
+		result.failf("invalid file path %q: %s", fe.Path, err)
+		return
+	}
+
+	var b bytes.Buffer
+	if err = tmpl.Execute(&b, paths); err != nil {
+		result.failf("invalid file path %q: %s", fe.Path, err)
+		return
+	}
+
+	path := b.String()
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(paths["WORKDIR"], path)
+	}
+
+	content, err := ioutil.ReadFile(path)
+	if fe.Absent {
+		if err == nil {
+			result.failf("expected file %s not to exist", fe.Path)
+		}
+		return
+	}
+
+	if err != nil {
+		result.failf("expected file %s to exist: %s", fe.Path, err)
+		return
+	}
+
+	if fe.Contains != "" && !strings.Contains(string(content), fe.Contains) {
+		result.failf("expected file %s to contain %q", fe.Path, fe.Contains)
+	}
+}
+
+// referencedVars returns the names of the vars referenced by a recipe's install
+// tasks, either as template vars or as shell variables.
+func referencedVars(f *recipes.RecipeFile) map[string]bool {
+	out, err := yaml.Marshal(f.Install)
+	if err != nil {
+		return map[string]bool{}
+	}
+
+	names := map[string]bool{}
+	for _, re := range []*regexp.Regexp{templateVarRegex, shellVarRegex} {
+		for _, m := range re.FindAllStringSubmatch(string(out), -1) {
+			names[m[1]] = true
+		}
+	}
+
+	return names
+}
+
+func exitCodeFromError(err error) int {
+	if m := exitStatusRegex.FindStringSubmatch(err.Error()); m != nil {
+		if code, convErr := strconv.Atoi(m[1]); convErr == nil {
+			return code
+		}
+	}
+
+	return 1
+}
+
+// setenv sets environment variables and returns a func that restores their
+// previous values.  Variables already set are restored when one cannot be set.
+func setenv(env map[string]string) (func() error, error) {
+	prior := map[string]*string{}
+	restore := func() error {
+		for k, v := range prior {
+			var err error
+			if v == nil {
+				err = os.Unsetenv(k)
+			} else {
+				err = os.Setenv(k, *v)
+			}
+
+			if err != nil {
+				return fmt.Errorf("could not restore %s: %s", k, err)
+			}
+		}
+
+		return nil
+	}
+
+	for k, v := range env {
+		if old, ok := os.LookupEnv(k); ok {
+			prior[k] = &old
+		} else {
+			prior[k] = nil
+		}
+
+		if err := os.Setenv(k, v); err != nil {
+			if restoreErr := restore(); restoreErr != nil {
+				log.Warn(restoreErr)
+			}
+
+			return nil, fmt.Errorf("could not set %s: %s", k, err)
+		}
+	}
+
+	return restore, nil
+}
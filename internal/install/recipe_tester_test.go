@@ -0,0 +1,156 @@
+// +build unit
+
+package install
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testerRecipe = `name: tester-recipe
+displayName: Tester Recipe
+description: A recipe for the recipe tester
+installTargets:
+  - type: host
+    os: linux
+inputVars:
+  - name: NR_CLI_DB_USERNAME
+    prompt: Database username
+validationNrql: "SELECT count(*) FROM SystemSample WHERE hostname like '{{.HOSTNAME}}'"
+install:
+  version: "3"
+  tasks:
+    default:
+      cmds:
+        - 'echo "username: {{.NR_CLI_DB_USERNAME}}" > $HOME/config.yml'
+        - echo "installed on {{.HOSTNAME}}"
+        - touch created.txt
+`
+
+func writeRecipeTest(t *testing.T, dir string, spec string) string {
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "recipe.yml"), []byte(testerRecipe), 0600))
+
+	path := filepath.Join(dir, "recipe.test.yml")
+	require.NoError(t, ioutil.WriteFile(path, []byte(spec), 0600))
+
+	return path
+}
+
+func TestRecipeTester_Passes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "newrelic-recipe-tester")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	home := os.Getenv("HOME")
+
+	path := writeRecipeTest(t, dir, `recipe: recipe.yml
+manifest:
+  hostname: test-host
+vars:
+  NR_CLI_DB_USERNAME: newrelic
+validation:
+  entityGuid: TESTGUID
+expect:
+  exitCode: 0
+  files:
+    - path: "{{.HOME}}/config.yml"
+      contains: "username: newrelic"
+    - path: created.txt
+    - path: missing.txt
+      absent: true
+  varsReferenced:
+    - NR_CLI_DB_USERNAME
+    - HOME
+  outputContains:
+    - installed on test-host
+`)
+
+	result, err := NewRecipeTester(ioutil.Discard).Run(context.Background(), path)
+	require.NoError(t, err)
+	require.Empty(t, result.Failures)
+	require.True(t, result.Passed())
+	require.Equal(t, "tester-recipe", result.RecipeName)
+	require.Equal(t, "TESTGUID", result.EntityGUID)
+	require.Equal(t, home, os.Getenv("HOME"))
+}
+
+func TestRecipeTester_Fails(t *testing.T) {
+	dir, err := ioutil.TempDir("", "newrelic-recipe-tester")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := writeRecipeTest(t, dir, `recipe: recipe.yml
+vars:
+  NR_CLI_DB_USERNAME: newrelic
+expect:
+  exitCode: 2
+  files:
+    - path: created.txt
+      absent: true
+  varsReferenced:
+    - NR_CLI_DB_PASSWORD
+`)
+
+	result, err := NewRecipeTester(ioutil.Discard).Run(context.Background(), path)
+	require.NoError(t, err)
+	require.False(t, result.Passed())
+	require.Len(t, result.Failures, 3)
+	require.Contains(t, result.Failures, "expected var NR_CLI_DB_PASSWORD to be referenced by the install tasks")
+}
+
+func TestRecipeTester_ExitCode(t *testing.T) {
+	dir, err := ioutil.TempDir("", "newrelic-recipe-tester")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "failing.yml"), []byte(`name: failing-recipe
+install:
+  version: "3"
+  tasks:
+    default:
+      cmds:
+        - exit 3
+`), 0600))
+
+	path := filepath.Join(dir, "failing.test.yml")
+	require.NoError(t, ioutil.WriteFile(path, []byte("recipe: failing.yml\nexpect:\n  exitCode: 3\n"), 0600))
+
+	result, err := NewRecipeTester(ioutil.Discard).Run(context.Background(), path)
+	require.NoError(t, err)
+	require.Equal(t, 3, result.ExitCode, result.Output)
+	require.True(t, result.Passed())
+}
+
+func TestSetenv(t *testing.T) {
+	require.NoError(t, os.Setenv("TEST_RECIPE_TESTER_SET", "prior"))
+	defer os.Unsetenv("TEST_RECIPE_TESTER_SET")
+
+	restore, err := setenv(map[string]string{"TEST_RECIPE_TESTER_SET": "test", "TEST_RECIPE_TESTER_UNSET": "test"})
+	require.NoError(t, err)
+	require.Equal(t, "test", os.Getenv("TEST_RECIPE_TESTER_SET"))
+
+	require.NoError(t, restore())
+	require.Equal(t, "prior", os.Getenv("TEST_RECIPE_TESTER_SET"))
+	_, ok := os.LookupEnv("TEST_RECIPE_TESTER_UNSET")
+	require.False(t, ok)
+
+	_, err = setenv(map[string]string{"TEST_RECIPE=TESTER": "test"})
+	require.Error(t, err)
+}
+
+func TestLoadRecipeTestSpec_UnknownField(t *testing.T) {
+	dir, err := ioutil.TempDir("", "newrelic-recipe-tester")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "bad.test.yml")
+	require.NoError(t, ioutil.WriteFile(path, []byte("recipe: recipe.yml\nexpects: {}\n"), 0600))
+
+	_, err = LoadRecipeTestSpec(path)
+	require.Error(t, err)
+}
@@ -0,0 +1,136 @@
+package install
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/newrelic/newrelic-cli/internal/install/execution"
+	"github.com/newrelic/newrelic-cli/internal/install/recipes"
+	"github.com/newrelic/newrelic-cli/internal/install/types"
+	"github.com/newrelic/newrelic-cli/internal/utils"
+)
+
+// Uninstall runs the uninstall section of each of the provided recipes.
+func (i *RecipeInstaller) Uninstall() error {
+	log.Tracef("InstallerContext: %+v", i.InstallerContext)
+
+	if !i.RecipeNamesProvided() {
+		return errors.New("at least one recipe name is required to uninstall")
+	}
+
+	i.status.SetUninstall()
+
+	ctx, cancel := context.WithCancel(utils.SignalCtx)
+	defer cancel()
+
+	errChan := make(chan error)
+	var err error
+
+	go func(ctx context.Context) {
+		errChan <- i.discoverAndUninstall(ctx)
+	}(ctx)
+
+	select {
+	case <-ctx.Done():
+		i.status.InstallCanceled()
+		return nil
+	case err = <-errChan:
+		if err == types.ErrInterrupt {
+			i.status.InstallCanceled()
+			return err
+		}
+
+		i.status.InstallComplete(err)
+
+		return err
+	}
+}
+
+func (i *RecipeInstaller) discoverAndUninstall(ctx context.Context) error {
+	m, err := i.discover(ctx)
+	if err != nil {
+		return err
+	}
+
+	i.status.DiscoveryComplete(*m)
+
+	var recipesForUninstall []types.Recipe
+	for _, n := range i.RecipeNames {
+		r, err := i.fetchRecipeAndReportAvailable(ctx, m, n)
+		if err != nil {
+			return err
+		}
+
+		recipesForUninstall = append(recipesForUninstall, *r)
+	}
+
+	for _, r := range recipesForUninstall {
+		err := i.uninstallWithProgress(ctx, m, &r)
+		if err != nil {
+			if err == types.ErrInterrupt {
+				return err
+			}
+
+			log.Warn(err)
+
+			if len(recipesForUninstall) == 1 {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (i *RecipeInstaller) uninstallWithProgress(ctx context.Context, m *types.DiscoveryManifest, r *types.Recipe) error {
+	msg := fmt.Sprintf("Uninstalling %s", r.Name)
+	i.progressIndicator.Start(msg)
+	defer func() { i.progressIndicator.Stop() }()
+
+	f, err := recipes.RecipeToRecipeFile(*r)
+	if err != nil {
+		return err
+	}
+
+	if !f.HasUninstall() {
+		i.progressIndicator.Fail(msg)
+		return i.uninstallFailed(r, fmt.Sprintf("recipe %s does not define an uninstall section", r.Name), "")
+	}
+
+	licenseKey, err := i.licenseKeyFetcher.FetchLicenseKey(ctx)
+	if err != nil {
+		return err
+	}
+
+	vars, err := i.recipeExecutor.Prepare(ctx, *m, *r, i.AssumeYes, licenseKey)
+	if err != nil {
+		return err
+	}
+
+	if err := i.recipeExecutor.Uninstall(ctx, *m, *r, vars); err != nil {
+		if err == types.ErrInterrupt {
+			return err
+		}
+
+		i.progressIndicator.Fail(msg)
+		return i.uninstallFailed(r, fmt.Sprintf("encountered an error while uninstalling %s: %s", r.Name, err), execution.TaskErrorDetails(err))
+	}
+
+	i.status.RecipeUninstalled(execution.RecipeStatusEvent{Recipe: *r})
+	i.progressIndicator.Success(msg)
+
+	return nil
+}
+
+func (i *RecipeInstaller) uninstallFailed(r *types.Recipe, msg string, details string) error {
+	i.status.RecipeFailed(execution.RecipeStatusEvent{
+		Recipe:  *r,
+		Msg:     msg,
+		Details: details,
+	})
+
+	return errors.New(msg)
+}
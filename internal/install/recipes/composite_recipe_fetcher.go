@@ -0,0 +1,193 @@
+package recipes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/newrelic/newrelic-cli/internal/install/types"
+)
+
+// CompositeRecipeFetcher is an implementation of the RecipeFetcher interface
+// that layers several fetchers in order of precedence.  A recipe defined by a
+// fetcher shadows any recipe of the same name from the fetchers after it, even
+// when it is not recommended for the host, so that a private repository can
+// replace a public recipe entirely.
+type CompositeRecipeFetcher struct {
+	fetchers []RecipeFetcher
+}
+
+// NewCompositeRecipeFetcher returns a new instance of CompositeRecipeFetcher.
+// Fetchers are given from highest to lowest precedence.
+func NewCompositeRecipeFetcher(fetchers ...RecipeFetcher) *CompositeRecipeFetcher {
+	f := CompositeRecipeFetcher{
+		fetchers: fetchers,
+	}
+
+	return &f
+}
+
+// FetchRecipe gets a recipe by name from the first fetcher that defines it.
+func (f *CompositeRecipeFetcher) FetchRecipe(ctx context.Context, manifest *types.DiscoveryManifest, friendlyName string) (*types.Recipe, error) {
+	var lastErr error
+
+	for i, fetcher := range f.fetchers {
+		last := i == len(f.fetchers)-1
+
+		if !last {
+			defined, err := defines(ctx, fetcher, manifest, friendlyName)
+			if err != nil {
+				log.Debugf("skipping recipe repository for %s: %s", friendlyName, err)
+				lastErr = err
+				continue
+			}
+
+			if !defined {
+				continue
+			}
+		}
+
+		return fetcher.FetchRecipe(ctx, manifest, friendlyName)
+	}
+
+	if lastErr != nil {
+		return nil, lastErr
+	}
+
+	return nil, fmt.Errorf("%s: %w", friendlyName, ErrRecipeNotFound)
+}
+
+// FetchRecommendations merges the recommendations of each fetcher, leaving out
+// recipes shadowed by a fetcher of higher precedence.
+func (f *CompositeRecipeFetcher) FetchRecommendations(ctx context.Context, manifest *types.DiscoveryManifest) ([]types.Recipe, error) {
+	return f.merge(ctx, manifest, func(fetcher RecipeFetcher) ([]types.Recipe, error) {
+		return fetcher.FetchRecommendations(ctx, manifest)
+	})
+}
+
+// FetchRecipes merges the recipes of each fetcher, leaving out recipes shadowed
+// by a fetcher of higher precedence.
+func (f *CompositeRecipeFetcher) FetchRecipes(ctx context.Context, manifest *types.DiscoveryManifest) ([]types.Recipe, error) {
+	return f.merge(ctx, manifest, func(fetcher RecipeFetcher) ([]types.Recipe, error) {
+		return fetcher.FetchRecipes(ctx, manifest)
+	})
+}
+
+// merge layers the recipes of each fetcher.  A fetcher that fails is skipped
+// with a warning, as in FetchRecipe, so that an unreachable repository does not
+// fail the whole install; the fetch only fails when every fetcher does.
+func (f *CompositeRecipeFetcher) merge(ctx context.Context, manifest *types.DiscoveryManifest, fetch func(RecipeFetcher) ([]types.Recipe, error)) ([]types.Recipe, error) {
+	shadowed := map[string]bool{}
+	merged := []types.Recipe{}
+
+	var lastErr error
+	failed := 0
+
+	for i, fetcher := range f.fetchers {
+		recipes, err := fetch(fetcher)
+		if err != nil {
+			log.Warnf("skipping recipe repository: %s", err)
+			lastErr = err
+			failed++
+			continue
+		}
+
+		for _, r := range recipes {
+			if !shadowed[r.Name] {
+				merged = append(merged, r)
+			}
+		}
+
+		// The names a fetcher defines shadow the fetchers after it.
+		if i < len(f.fetchers)-1 {
+			all, err := fetcher.FetchRecipes(ctx, manifest)
+			if err != nil {
+				log.Warnf("not shadowing recipes of lower precedence: %s", err)
+				continue
+			}
+
+			for _, r := range all {
+				shadowed[r.Name] = true
+			}
+		}
+	}
+
+	if failed == len(f.fetchers) && lastErr != nil {
+		return nil, lastErr
+	}
+
+	return merged, nil
+}
+
+func defines(ctx context.Context, fetcher RecipeFetcher, manifest *types.DiscoveryManifest, name string) (bool, error) {
+	recipes, err := fetcher.FetchRecipes(ctx, manifest)
+	if err != nil {
+		return false, err
+	}
+
+	for _, r := range recipes {
+		if r.Name == name {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// RecipeRepositoryOptions configures the fetchers of recipe repositories.
+type RecipeRepositoryOptions struct {
+	// CacheDir is where git checkouts are kept.
+	CacheDir string
+	// HTTPClient sends the requests of HTTP indexes, such as through a proxy.
+	HTTPClient *http.Client
+	// Verifier checks the signatures of HTTP indexes and their tarballs.
+	Verifier *RecipeVerifier
+	// AllowInsecure allows HTTP indexes served over plain http.
+	AllowInsecure bool
+}
+
+// NewRecipeRepositoryFetcher returns a fetcher for a recipe repository spec:
+//
+//	git+https://github.com/acme/recipes.git#v1.2.0   a git repository at a ref
+//	https://github.com/acme/recipes.git              a git repository at its default branch
+//	https://recipes.acme.com/index.yml               an HTTP index of recipe tarballs
+//	/opt/acme/recipes                                a local directory
+//
+// HTTP indexes must be served over https unless insecure repositories are
+// allowed.
+func NewRecipeRepositoryFetcher(spec string, opts RecipeRepositoryOptions) (RecipeFetcher, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, errors.New("recipe repository cannot be empty")
+	}
+
+	location, ref := spec, ""
+	if i := strings.LastIndex(spec, "#"); i >= 0 {
+		location, ref = spec[:i], spec[i+1:]
+	}
+
+	switch {
+	case strings.HasPrefix(location, "git+"):
+		return NewGitRecipeFetcher(strings.TrimPrefix(location, "git+"), ref, opts.CacheDir), nil
+	case strings.HasSuffix(location, ".git"):
+		return NewGitRecipeFetcher(location, ref, opts.CacheDir), nil
+	case strings.HasPrefix(location, "http://"), strings.HasPrefix(location, "https://"):
+		if ref != "" {
+			return nil, fmt.Errorf("recipe repository %s: a ref can only be given for a git repository", spec)
+		}
+
+		if strings.HasPrefix(location, "http://") && !opts.AllowInsecure {
+			return nil, fmt.Errorf("recipe repository %s: plain http is not allowed, use https or allow insecure recipe repositories", spec)
+		}
+
+		return NewHTTPRecipeFetcher(location, opts.HTTPClient, opts.Verifier), nil
+	case strings.Contains(location, "://"):
+		return nil, fmt.Errorf("recipe repository %s: unsupported scheme", spec)
+	}
+
+	return &LocalRecipeFetcher{Path: spec}, nil
+}
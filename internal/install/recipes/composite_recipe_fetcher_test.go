@@ -0,0 +1,173 @@
+// +build unit
+
+package recipes
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/newrelic/newrelic-cli/internal/install/types"
+)
+
+func TestCompositeRecipeFetcher_FetchRecipe_PrefersFirstDefiningFetcher(t *testing.T) {
+	repo := NewMockRecipeFetcher()
+	repo.FetchRecipesVal = []types.Recipe{{Name: "a", DisplayName: "repo"}}
+	repo.FetchRecipeVal = &types.Recipe{Name: "a", DisplayName: "repo"}
+
+	base := NewMockRecipeFetcher()
+	base.FetchRecipeVal = &types.Recipe{Name: "a", DisplayName: "service"}
+
+	f := NewCompositeRecipeFetcher(repo, base)
+
+	r, err := f.FetchRecipe(context.Background(), &types.DiscoveryManifest{}, "a")
+	require.NoError(t, err)
+	require.Equal(t, "repo", r.DisplayName)
+	require.Equal(t, 0, base.FetchRecipeCallCount)
+}
+
+func TestCompositeRecipeFetcher_FetchRecipe_FallsThroughWhenNotDefined(t *testing.T) {
+	repo := NewMockRecipeFetcher()
+	repo.FetchRecipesVal = []types.Recipe{{Name: "other"}}
+
+	base := NewMockRecipeFetcher()
+	base.FetchRecipeVal = &types.Recipe{Name: "a", DisplayName: "service"}
+
+	f := NewCompositeRecipeFetcher(repo, base)
+
+	r, err := f.FetchRecipe(context.Background(), &types.DiscoveryManifest{}, "a")
+	require.NoError(t, err)
+	require.Equal(t, "service", r.DisplayName)
+	require.Equal(t, 0, repo.FetchRecipeCallCount)
+}
+
+func TestCompositeRecipeFetcher_FetchRecipe_ShadowedRecipeIsNotFetchedFromBase(t *testing.T) {
+	repo := NewMockRecipeFetcher()
+	repo.FetchRecipesVal = []types.Recipe{{Name: "a"}}
+	repo.FetchRecipeErr = ErrRecipeNotFound
+
+	base := NewMockRecipeFetcher()
+	base.FetchRecipeVal = &types.Recipe{Name: "a", DisplayName: "service"}
+
+	f := NewCompositeRecipeFetcher(repo, base)
+
+	_, err := f.FetchRecipe(context.Background(), &types.DiscoveryManifest{}, "a")
+	require.ErrorIs(t, err, ErrRecipeNotFound)
+	require.Equal(t, 0, base.FetchRecipeCallCount)
+}
+
+func TestCompositeRecipeFetcher_FetchRecipe_SkipsFailingRepository(t *testing.T) {
+	repo := NewMockRecipeFetcher()
+	repo.FetchRecipesErr = errors.New("unreachable")
+
+	base := NewMockRecipeFetcher()
+	base.FetchRecipeVal = &types.Recipe{Name: "a", DisplayName: "service"}
+
+	f := NewCompositeRecipeFetcher(repo, base)
+
+	r, err := f.FetchRecipe(context.Background(), &types.DiscoveryManifest{}, "a")
+	require.NoError(t, err)
+	require.Equal(t, "service", r.DisplayName)
+}
+
+func TestCompositeRecipeFetcher_FetchRecommendations_ShadowsByName(t *testing.T) {
+	first := NewMockRecipeFetcher()
+	first.FetchRecipesVal = []types.Recipe{{Name: "a"}, {Name: "b"}}
+	first.FetchRecommendationsVal = []types.Recipe{{Name: "b", DisplayName: "first"}}
+
+	second := NewMockRecipeFetcher()
+	second.FetchRecipesVal = []types.Recipe{{Name: "b"}, {Name: "c"}}
+	second.FetchRecommendationsVal = []types.Recipe{{Name: "b", DisplayName: "second"}, {Name: "c", DisplayName: "second"}}
+
+	base := NewMockRecipeFetcher()
+	base.FetchRecommendationsVal = []types.Recipe{{Name: "a"}, {Name: "c"}, {Name: "d", DisplayName: "service"}}
+
+	f := NewCompositeRecipeFetcher(first, second, base)
+
+	recipes, err := f.FetchRecommendations(context.Background(), &types.DiscoveryManifest{})
+	require.NoError(t, err)
+	require.Equal(t, []types.Recipe{
+		{Name: "b", DisplayName: "first"},
+		{Name: "c", DisplayName: "second"},
+		{Name: "d", DisplayName: "service"},
+	}, recipes)
+}
+
+func TestCompositeRecipeFetcher_FetchRecipes_FirstDefinitionWins(t *testing.T) {
+	repo := NewMockRecipeFetcher()
+	repo.FetchRecipesVal = []types.Recipe{{Name: "a", DisplayName: "repo"}}
+
+	base := NewMockRecipeFetcher()
+	base.FetchRecipesVal = []types.Recipe{{Name: "a", DisplayName: "service"}, {Name: "b", DisplayName: "service"}}
+
+	f := NewCompositeRecipeFetcher(repo, base)
+
+	recipes, err := f.FetchRecipes(context.Background(), &types.DiscoveryManifest{})
+	require.NoError(t, err)
+	require.Equal(t, []types.Recipe{
+		{Name: "a", DisplayName: "repo"},
+		{Name: "b", DisplayName: "service"},
+	}, recipes)
+}
+
+func TestCompositeRecipeFetcher_FetchRecommendations_SkipsFailingRepository(t *testing.T) {
+	repo := NewMockRecipeFetcher()
+	repo.FetchRecommendationsErr = errors.New("unreachable")
+	repo.FetchRecipesErr = errors.New("unreachable")
+
+	base := NewMockRecipeFetcher()
+	base.FetchRecommendationsVal = []types.Recipe{{Name: "a", DisplayName: "service"}}
+
+	f := NewCompositeRecipeFetcher(repo, base)
+
+	recipes, err := f.FetchRecommendations(context.Background(), &types.DiscoveryManifest{})
+	require.NoError(t, err)
+	require.Equal(t, []types.Recipe{{Name: "a", DisplayName: "service"}}, recipes)
+
+	base.FetchRecommendationsErr = errors.New("service unavailable")
+
+	_, err = f.FetchRecommendations(context.Background(), &types.DiscoveryManifest{})
+	require.Error(t, err)
+}
+
+func TestNewRecipeRepositoryFetcher(t *testing.T) {
+	opts := RecipeRepositoryOptions{CacheDir: "/tmp/cache"}
+
+	f, err := NewRecipeRepositoryFetcher("git+https://github.com/acme/recipes#v1.2.0", opts)
+	require.NoError(t, err)
+	require.IsType(t, &GitRecipeFetcher{}, f)
+	require.Equal(t, "https://github.com/acme/recipes", f.(*GitRecipeFetcher).URL)
+	require.Equal(t, "v1.2.0", f.(*GitRecipeFetcher).Ref)
+
+	f, err = NewRecipeRepositoryFetcher("https://github.com/acme/recipes.git", opts)
+	require.NoError(t, err)
+	require.IsType(t, &GitRecipeFetcher{}, f)
+	require.Equal(t, defaultGitRef, f.(*GitRecipeFetcher).Ref)
+
+	f, err = NewRecipeRepositoryFetcher("https://recipes.acme.com/index.yml", opts)
+	require.NoError(t, err)
+	require.IsType(t, &HTTPRecipeFetcher{}, f)
+
+	f, err = NewRecipeRepositoryFetcher("/opt/acme/recipes", opts)
+	require.NoError(t, err)
+	require.Equal(t, &LocalRecipeFetcher{Path: "/opt/acme/recipes"}, f)
+
+	_, err = NewRecipeRepositoryFetcher("https://recipes.acme.com/index.yml#main", opts)
+	require.Error(t, err)
+
+	_, err = NewRecipeRepositoryFetcher("s3://acme/recipes", opts)
+	require.Error(t, err)
+
+	_, err = NewRecipeRepositoryFetcher(" ", opts)
+	require.Error(t, err)
+
+	_, err = NewRecipeRepositoryFetcher("http://recipes.acme.com/index.yml", opts)
+	require.Error(t, err)
+
+	opts.AllowInsecure = true
+	f, err = NewRecipeRepositoryFetcher("http://recipes.acme.com/index.yml", opts)
+	require.NoError(t, err)
+	require.IsType(t, &HTTPRecipeFetcher{}, f)
+}
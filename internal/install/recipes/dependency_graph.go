@@ -0,0 +1,228 @@
+package recipes
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/newrelic/newrelic-cli/internal/install/types"
+)
+
+// DependencyFetchFunc returns the recipe for a dependency name.  A nil recipe
+// with a nil error means the dependency should be left out of the graph.
+type DependencyFetchFunc func(name string) (*types.Recipe, error)
+
+// DependencyGraph is a directed graph of recipes and the recipes they depend on.
+type DependencyGraph struct {
+	roots        []string
+	recipes      map[string]types.Recipe
+	dependencies map[string][]string
+}
+
+// NewDependencyGraph returns a new, empty dependency graph.
+func NewDependencyGraph() *DependencyGraph {
+	return &DependencyGraph{
+		recipes:      map[string]types.Recipe{},
+		dependencies: map[string][]string{},
+	}
+}
+
+// ResolveDependencies builds the graph of the given recipes and all of their
+// transitive dependencies.  Each dependency is fetched once, no matter how many
+// recipes depend on it, including dependencies left out of the graph.
+func ResolveDependencies(requested []types.Recipe, fetch DependencyFetchFunc) (*DependencyGraph, error) {
+	g := NewDependencyGraph()
+	skipped := map[string]bool{}
+
+	queue := []types.Recipe{}
+	for _, r := range requested {
+		if g.Has(r.Name) {
+			continue
+		}
+
+		g.roots = append(g.roots, r.Name)
+		g.add(r)
+		queue = append(queue, r)
+	}
+
+	for len(queue) > 0 {
+		r := queue[0]
+		queue = queue[1:]
+
+		for _, name := range r.Dependencies {
+			if skipped[name] {
+				continue
+			}
+
+			if !g.Has(name) {
+				d, err := fetch(name)
+				if err != nil {
+					return nil, err
+				}
+
+				if d == nil {
+					skipped[name] = true
+					continue
+				}
+
+				// A recipe of another name would be installed in place of the
+				// dependency, and leave it unsatisfied.
+				if d.Name != name {
+					return nil, fmt.Errorf("recipe %s depends on %s, but %s was fetched instead", r.Name, name, d.Name)
+				}
+
+				g.add(*d)
+				queue = append(queue, *d)
+			}
+
+			g.dependencies[r.Name] = append(g.dependencies[r.Name], name)
+		}
+	}
+
+	return g, nil
+}
+
+func (g *DependencyGraph) add(r types.Recipe) {
+	g.recipes[r.Name] = r
+}
+
+// Has returns true when the named recipe is part of the graph.
+func (g *DependencyGraph) Has(name string) bool {
+	_, ok := g.recipes[name]
+	return ok
+}
+
+// Dependencies returns the names of the direct dependencies of a recipe.
+func (g *DependencyGraph) Dependencies(name string) []string {
+	return g.dependencies[name]
+}
+
+// InstallOrder returns the recipes of the graph ordered so that every recipe
+// comes after all of its dependencies.  Requested recipes keep their relative
+// order where their dependencies allow it.  An error is returned if the graph
+// contains a cycle.
+func (g *DependencyGraph) InstallOrder() ([]types.Recipe, error) {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	state := map[string]int{}
+	ordered := []types.Recipe{}
+	path := []string{}
+
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("recipe dependency cycle detected: %s", strings.Join(cyclePath(path, name), " -> "))
+		}
+
+		state[name] = visiting
+		path = append(path, name)
+
+		for _, d := range g.dependencies[name] {
+			if err := visit(d); err != nil {
+				return err
+			}
+		}
+
+		path = path[:len(path)-1]
+		state[name] = visited
+		ordered = append(ordered, g.recipes[name])
+
+		return nil
+	}
+
+	for _, name := range g.roots {
+		if err := visit(name); err != nil {
+			return nil, err
+		}
+	}
+
+	return ordered, nil
+}
+
+// cyclePath returns the part of the path that forms a cycle back to name.
+func cyclePath(path []string, name string) []string {
+	for i, p := range path {
+		if p == name {
+			return append(append([]string{}, path[i:]...), name)
+		}
+	}
+
+	return append(path, name)
+}
+
+// Text renders the graph as the install order followed by each recipe's
+// direct dependencies.
+func (g *DependencyGraph) Text() (string, error) {
+	ordered, err := g.InstallOrder()
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+
+	b.WriteString("Install order:\n")
+	for i, r := range ordered {
+		fmt.Fprintf(&b, "  %d. %s\n", i+1, r.Name)
+	}
+
+	b.WriteString("\nDependencies:\n")
+	for _, r := range ordered {
+		deps := g.dependencies[r.Name]
+		if len(deps) == 0 {
+			fmt.Fprintf(&b, "  %s (none)\n", r.Name)
+			continue
+		}
+
+		fmt.Fprintf(&b, "  %s -> %s\n", r.Name, strings.Join(deps, ", "))
+	}
+
+	return b.String(), nil
+}
+
+// DOT renders the graph in the Graphviz DOT language, with edges pointing from
+// a recipe to the recipes it depends on.  Cycles are rendered as they are,
+// which makes DOT useful for finding them.
+func (g *DependencyGraph) DOT() string {
+	var b strings.Builder
+
+	b.WriteString("digraph recipes {\n")
+
+	names := []string{}
+	seen := map[string]bool{}
+	var walk func(name string)
+	walk = func(name string) {
+		if seen[name] {
+			return
+		}
+		seen[name] = true
+		names = append(names, name)
+
+		for _, d := range g.dependencies[name] {
+			walk(d)
+		}
+	}
+
+	for _, name := range g.roots {
+		walk(name)
+	}
+
+	for _, name := range names {
+		fmt.Fprintf(&b, "  %q;\n", name)
+	}
+
+	for _, name := range names {
+		for _, d := range g.dependencies[name] {
+			fmt.Fprintf(&b, "  %q -> %q;\n", name, d)
+		}
+	}
+
+	b.WriteString("}\n")
+
+	return b.String()
+}
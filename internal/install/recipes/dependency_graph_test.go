@@ -0,0 +1,160 @@
+// +build unit
+
+package recipes
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/newrelic/newrelic-cli/internal/install/types"
+)
+
+func graphFetcher(available []types.Recipe, fetched map[string]int) DependencyFetchFunc {
+	return func(name string) (*types.Recipe, error) {
+		fetched[name]++
+		for _, r := range available {
+			if r.Name == name {
+				return &r, nil
+			}
+		}
+
+		return nil, errors.New("recipe not found")
+	}
+}
+
+func recipeNames(rr []types.Recipe) []string {
+	names := []string{}
+	for _, r := range rr {
+		names = append(names, r.Name)
+	}
+
+	return names
+}
+
+func TestResolveDependencies_TransitiveInstallOrder(t *testing.T) {
+	fetched := map[string]int{}
+	available := []types.Recipe{
+		{Name: "agent", Dependencies: []string{"runtime"}},
+		{Name: "runtime"},
+	}
+
+	g, err := ResolveDependencies([]types.Recipe{{Name: "integration", Dependencies: []string{"agent"}}}, graphFetcher(available, fetched))
+	require.NoError(t, err)
+
+	ordered, err := g.InstallOrder()
+	require.NoError(t, err)
+	require.Equal(t, []string{"runtime", "agent", "integration"}, recipeNames(ordered))
+}
+
+func TestResolveDependencies_SharedDependencyOnce(t *testing.T) {
+	fetched := map[string]int{}
+	available := []types.Recipe{
+		{Name: "runtime"},
+	}
+	requested := []types.Recipe{
+		{Name: "a", Dependencies: []string{"runtime"}},
+		{Name: "b", Dependencies: []string{"runtime"}},
+	}
+
+	g, err := ResolveDependencies(requested, graphFetcher(available, fetched))
+	require.NoError(t, err)
+	require.Equal(t, 1, fetched["runtime"])
+
+	ordered, err := g.InstallOrder()
+	require.NoError(t, err)
+	require.Equal(t, []string{"runtime", "a", "b"}, recipeNames(ordered))
+}
+
+func TestResolveDependencies_RequestedDependency(t *testing.T) {
+	fetched := map[string]int{}
+	requested := []types.Recipe{
+		{Name: "a", Dependencies: []string{"b"}},
+		{Name: "b"},
+	}
+
+	g, err := ResolveDependencies(requested, graphFetcher(nil, fetched))
+	require.NoError(t, err)
+	require.Empty(t, fetched)
+
+	ordered, err := g.InstallOrder()
+	require.NoError(t, err)
+	require.Equal(t, []string{"b", "a"}, recipeNames(ordered))
+}
+
+func TestResolveDependencies_SkippedDependency(t *testing.T) {
+	fetched := map[string]int{}
+	skip := func(name string) (*types.Recipe, error) {
+		fetched[name]++
+		return nil, nil
+	}
+
+	requested := []types.Recipe{
+		{Name: "a", Dependencies: []string{"b"}},
+		{Name: "c", Dependencies: []string{"b"}},
+	}
+
+	g, err := ResolveDependencies(requested, skip)
+	require.NoError(t, err)
+	require.False(t, g.Has("b"))
+	require.Empty(t, g.Dependencies("a"))
+	require.Equal(t, 1, fetched["b"])
+}
+
+func TestResolveDependencies_NameMismatch(t *testing.T) {
+	mismatched := func(name string) (*types.Recipe, error) {
+		return &types.Recipe{Name: "other"}, nil
+	}
+
+	_, err := ResolveDependencies([]types.Recipe{{Name: "a", Dependencies: []string{"b"}}}, mismatched)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "recipe a depends on b, but other was fetched instead")
+}
+
+func TestResolveDependencies_FetchError(t *testing.T) {
+	_, err := ResolveDependencies([]types.Recipe{{Name: "a", Dependencies: []string{"missing"}}}, graphFetcher(nil, map[string]int{}))
+	require.Error(t, err)
+}
+
+func TestInstallOrder_Cycle(t *testing.T) {
+	available := []types.Recipe{
+		{Name: "b", Dependencies: []string{"c"}},
+		{Name: "c", Dependencies: []string{"b"}},
+	}
+
+	g, err := ResolveDependencies([]types.Recipe{{Name: "a", Dependencies: []string{"b"}}}, graphFetcher(available, map[string]int{}))
+	require.NoError(t, err)
+
+	_, err = g.InstallOrder()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "b -> c -> b")
+}
+
+func TestDependencyGraph_DOT(t *testing.T) {
+	available := []types.Recipe{
+		{Name: "runtime"},
+	}
+
+	g, err := ResolveDependencies([]types.Recipe{{Name: "agent", Dependencies: []string{"runtime"}}}, graphFetcher(available, map[string]int{}))
+	require.NoError(t, err)
+
+	dot := g.DOT()
+	require.Contains(t, dot, "digraph recipes {")
+	require.Contains(t, dot, `"agent" -> "runtime";`)
+}
+
+func TestDependencyGraph_Text(t *testing.T) {
+	available := []types.Recipe{
+		{Name: "runtime"},
+	}
+
+	g, err := ResolveDependencies([]types.Recipe{{Name: "agent", Dependencies: []string{"runtime"}}}, graphFetcher(available, map[string]int{}))
+	require.NoError(t, err)
+
+	text, err := g.Text()
+	require.NoError(t, err)
+	require.Contains(t, text, "1. runtime")
+	require.Contains(t, text, "2. agent")
+	require.Contains(t, text, "agent -> runtime")
+}
@@ -0,0 +1,156 @@
+package recipes
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/newrelic/newrelic-cli/internal/install/types"
+)
+
+const defaultGitRef = "HEAD"
+
+// GitRecipeFetcher is an implementation of the RecipeFetcher interface that
+// loads recipes from a ref of a git repository.  The repository is checked out
+// shallowly into a cache directory the first time recipes are fetched, and the
+// checkout is updated to the ref on each run.  The git binary must be on the
+// PATH.
+type GitRecipeFetcher struct {
+	URL      string
+	Ref      string
+	CacheDir string
+
+	mu      sync.Mutex
+	recipes []types.Recipe
+	loaded  bool
+}
+
+// NewGitRecipeFetcher returns a new instance of GitRecipeFetcher.  An empty ref
+// checks out the repository's default branch.
+func NewGitRecipeFetcher(url string, ref string, cacheDir string) *GitRecipeFetcher {
+	if ref == "" {
+		ref = defaultGitRef
+	}
+
+	f := GitRecipeFetcher{
+		URL:      url,
+		Ref:      ref,
+		CacheDir: cacheDir,
+	}
+
+	return &f
+}
+
+func (f *GitRecipeFetcher) FetchRecipe(ctx context.Context, manifest *types.DiscoveryManifest, friendlyName string) (*types.Recipe, error) {
+	recipes, err := f.FetchRecommendations(ctx, manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, recipe := range recipes {
+		if recipe.Name == friendlyName {
+			return &recipe, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%s: %w", friendlyName, ErrRecipeNotFound)
+}
+
+func (f *GitRecipeFetcher) FetchRecommendations(ctx context.Context, manifest *types.DiscoveryManifest) ([]types.Recipe, error) {
+	recipes, err := f.FetchRecipes(ctx, manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	return manifest.ConstrainRecipes(recipes), nil
+}
+
+func (f *GitRecipeFetcher) FetchRecipes(ctx context.Context, manifest *types.DiscoveryManifest) ([]types.Recipe, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.loaded {
+		return f.recipes, nil
+	}
+
+	dir, err := f.checkout(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	recipes, err := loadRecipesFromDir(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	f.recipes = recipes
+	f.loaded = true
+
+	return f.recipes, nil
+}
+
+// CheckoutDir returns the directory the repository is checked out into.
+func (f *GitRecipeFetcher) CheckoutDir() string {
+	sum := sha256.Sum256([]byte(f.URL))
+	return filepath.Join(f.CacheDir, "git", fmt.Sprintf("%x", sum[:8]))
+}
+
+// checkout fetches the ref into the cache directory and checks it out.
+// Fetching by ref rather than cloning a branch allows tags and commit SHAs to
+// be used as well as branch names.
+func (f *GitRecipeFetcher) checkout(ctx context.Context) (string, error) {
+	dir := f.CheckoutDir()
+
+	log.WithFields(log.Fields{
+		"url": f.URL,
+		"ref": f.Ref,
+		"dir": dir,
+	}).Debug("checking out recipe repository")
+
+	if _, err := os.Stat(filepath.Join(dir, ".git")); os.IsNotExist(err) {
+		if err = os.MkdirAll(dir, 0750); err != nil {
+			return "", err
+		}
+
+		if err = runGit(ctx, dir, "init", "--quiet"); err != nil {
+			return "", err
+		}
+	}
+
+	if err := runGit(ctx, dir, "fetch", "--quiet", "--depth", "1", f.URL, f.Ref); err != nil {
+		return "", fmt.Errorf("could not fetch %s from recipe repository %s: %s", f.Ref, f.URL, err)
+	}
+
+	if err := runGit(ctx, dir, "checkout", "--quiet", "--force", "FETCH_HEAD"); err != nil {
+		return "", fmt.Errorf("could not check out %s from recipe repository %s: %s", f.Ref, f.URL, err)
+	}
+
+	return dir, nil
+}
+
+func runGit(ctx context.Context, dir string, args ...string) error {
+	var stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...)
+	cmd.Stderr = &stderr
+	// Never prompt for credentials; fail instead.
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%s: %s", err, msg)
+		}
+
+		return err
+	}
+
+	return nil
+}
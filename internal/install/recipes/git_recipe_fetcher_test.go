@@ -0,0 +1,76 @@
+// +build integration
+
+package recipes
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/newrelic/newrelic-cli/internal/install/types"
+)
+
+func TestGitRecipeFetcher_FetchRecipes(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	tmp, err := ioutil.TempDir("/tmp", "newrelic")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmp)
+
+	repo := filepath.Join(tmp, "repo")
+	require.NoError(t, os.MkdirAll(filepath.Join(repo, ".github"), 0750))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(repo, "infra.yml"), []byte(sampleRecipe), 0600))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(repo, ".github", "ci.yml"), []byte("name: ci\n"), 0600))
+
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", repo, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		out, cmdErr := cmd.CombinedOutput()
+		require.NoError(t, cmdErr, string(out))
+	}
+
+	git("init", "--quiet")
+	git("add", "-A")
+	git("commit", "--quiet", "-m", "v1")
+	git("tag", "v1")
+	require.NoError(t, os.Remove(filepath.Join(repo, "infra.yml")))
+	git("commit", "--quiet", "-am", "remove")
+
+	cache := filepath.Join(tmp, "cache")
+
+	f := NewGitRecipeFetcher(repo, "v1", cache)
+	recipes, err := f.FetchRecipes(context.Background(), &types.DiscoveryManifest{})
+	require.NoError(t, err)
+	require.Len(t, recipes, 1)
+	require.Equal(t, "infrastructure-agent-installer", recipes[0].Name)
+
+	recipe, err := f.FetchRecipe(context.Background(), &types.DiscoveryManifest{OS: "linux", Platform: "debian"}, "infrastructure-agent-installer")
+	require.NoError(t, err)
+	require.Equal(t, "infrastructure-agent-installer", recipe.Name)
+
+	// The existing checkout is updated to the default branch.
+	f = NewGitRecipeFetcher(repo, "", cache)
+	recipes, err = f.FetchRecipes(context.Background(), &types.DiscoveryManifest{})
+	require.NoError(t, err)
+	require.Empty(t, recipes)
+}
+
+func TestGitRecipeFetcher_UnknownRef(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	tmp, err := ioutil.TempDir("/tmp", "newrelic")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmp)
+
+	f := NewGitRecipeFetcher(filepath.Join(tmp, "missing"), "main", filepath.Join(tmp, "cache"))
+	_, err = f.FetchRecipes(context.Background(), &types.DiscoveryManifest{})
+	require.Error(t, err)
+}
@@ -0,0 +1,328 @@
+package recipes
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+
+	"github.com/newrelic/newrelic-cli/internal/install/types"
+)
+
+const (
+	// maxRecipeFileSize limits how much of a recipe file is read from a tarball.
+	maxRecipeFileSize = 10 << 20
+
+	// maxRecipeTarballSize limits how much of a recipe tarball is downloaded.
+	maxRecipeTarballSize = 100 << 20
+)
+
+// RecipeIndex lists the recipe tarballs served by an HTTP recipe repository.
+// Tarball URLs are resolved relative to the URL of the index.  The index may be
+// written in YAML or JSON.
+//
+//	recipes:
+//	  - name: mysql-open-source-integration
+//	    url: mysql-open-source-integration.tar.gz
+type RecipeIndex struct {
+	Recipes []RecipeIndexEntry `yaml:"recipes" json:"recipes"`
+}
+
+// RecipeIndexEntry is a gzipped tarball of recipe files in a RecipeIndex.
+type RecipeIndexEntry struct {
+	Name string `yaml:"name" json:"name"`
+	URL  string `yaml:"url" json:"url"`
+}
+
+// HTTPRecipeFetcher is an implementation of the RecipeFetcher interface that
+// loads recipes from tarballs listed in an HTTP index.  A recipe fetched by name
+// only downloads the tarball the index lists for that name.  When a verifier is
+// given, the index and each tarball are checked against their detached
+// signatures, found by appending .sig to their URLs.
+type HTTPRecipeFetcher struct {
+	IndexURL string
+	client   *http.Client
+	verifier *RecipeVerifier
+
+	mu       sync.Mutex
+	index    *RecipeIndex
+	tarballs map[string][]types.Recipe
+}
+
+// NewHTTPRecipeFetcher returns a new instance of HTTPRecipeFetcher.  Requests
+// are sent with the given client, such as one sending them through a proxy, or
+// with the default client when it is nil.  A nil verifier leaves signatures
+// unchecked.
+func NewHTTPRecipeFetcher(indexURL string, client *http.Client, verifier *RecipeVerifier) *HTTPRecipeFetcher {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	f := HTTPRecipeFetcher{
+		IndexURL: indexURL,
+		client:   client,
+		verifier: verifier,
+		tarballs: map[string][]types.Recipe{},
+	}
+
+	return &f
+}
+
+func (f *HTTPRecipeFetcher) FetchRecipe(ctx context.Context, manifest *types.DiscoveryManifest, friendlyName string) (*types.Recipe, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	index, err := f.fetchIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range index.Recipes {
+		if e.Name != friendlyName {
+			continue
+		}
+
+		recipes, err := f.fetchTarball(ctx, e)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, r := range manifest.ConstrainRecipes(recipes) {
+			if r.Name == friendlyName {
+				return &r, nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("%s: %w", friendlyName, ErrRecipeNotFound)
+}
+
+func (f *HTTPRecipeFetcher) FetchRecommendations(ctx context.Context, manifest *types.DiscoveryManifest) ([]types.Recipe, error) {
+	recipes, err := f.FetchRecipes(ctx, manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	return manifest.ConstrainRecipes(recipes), nil
+}
+
+func (f *HTTPRecipeFetcher) FetchRecipes(ctx context.Context, manifest *types.DiscoveryManifest) ([]types.Recipe, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	index, err := f.fetchIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	recipes := []types.Recipe{}
+	for _, e := range index.Recipes {
+		r, err := f.fetchTarball(ctx, e)
+		if err != nil {
+			return nil, err
+		}
+
+		recipes = append(recipes, r...)
+	}
+
+	return recipes, nil
+}
+
+func (f *HTTPRecipeFetcher) fetchIndex(ctx context.Context) (*RecipeIndex, error) {
+	if f.index != nil {
+		return f.index, nil
+	}
+
+	body, err := f.get(ctx, f.IndexURL)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	content, err := ioutil.ReadAll(io.LimitReader(body, maxRecipeFileSize))
+	if err != nil {
+		return nil, err
+	}
+
+	if err = f.verify(ctx, f.IndexURL, content); err != nil {
+		return nil, err
+	}
+
+	var index RecipeIndex
+	if err = yaml.Unmarshal(content, &index); err != nil {
+		return nil, fmt.Errorf("could not parse recipe index %s: %s", f.IndexURL, err)
+	}
+
+	f.index = &index
+
+	return f.index, nil
+}
+
+func (f *HTTPRecipeFetcher) fetchTarball(ctx context.Context, e RecipeIndexEntry) ([]types.Recipe, error) {
+	if recipes, ok := f.tarballs[e.URL]; ok {
+		return recipes, nil
+	}
+
+	tarballURL, err := f.resolve(e.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	log.WithFields(log.Fields{
+		"name": e.Name,
+		"url":  tarballURL,
+	}).Debug("fetching recipe tarball")
+
+	body, err := f.get(ctx, tarballURL)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	content, err := ioutil.ReadAll(io.LimitReader(body, maxRecipeTarballSize))
+	if err != nil {
+		return nil, err
+	}
+
+	if err = f.verify(ctx, tarballURL, content); err != nil {
+		return nil, err
+	}
+
+	recipes, err := recipesFromTarball(bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("could not read recipe tarball %s: %s", tarballURL, err)
+	}
+
+	f.tarballs[e.URL] = recipes
+
+	return recipes, nil
+}
+
+func (f *HTTPRecipeFetcher) resolve(ref string) (string, error) {
+	base, err := url.Parse(f.IndexURL)
+	if err != nil {
+		return "", err
+	}
+
+	u, err := url.Parse(ref)
+	if err != nil {
+		return "", err
+	}
+
+	return base.ResolveReference(u).String(), nil
+}
+
+func (f *HTTPRecipeFetcher) get(ctx context.Context, u string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("received non-2xx status code %d when retrieving %s", resp.StatusCode, u)
+	}
+
+	return resp.Body, nil
+}
+
+// verify checks the content downloaded from a URL against its detached
+// signature.  A missing signature leaves the content unsigned.
+func (f *HTTPRecipeFetcher) verify(ctx context.Context, u string, content []byte) error {
+	if f.verifier == nil {
+		return nil
+	}
+
+	signature, err := f.fetchSignature(ctx, u+RecipeSignatureExtension)
+	if err != nil {
+		if err = f.verifier.SignatureUnavailable(u, err); err != nil {
+			return err
+		}
+	}
+
+	return f.verifier.Verify(u, content, signature)
+}
+
+// fetchSignature returns the signature at a URL, or nil when there is none.
+func (f *HTTPRecipeFetcher) fetchSignature(ctx context.Context, u string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve signature %s: %s", u, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("received non-2xx status code %d when retrieving signature %s", resp.StatusCode, u)
+	}
+
+	return ioutil.ReadAll(io.LimitReader(resp.Body, maxRecipeFileSize))
+}
+
+// recipesFromTarball reads the recipe files in a gzipped tarball.
+func recipesFromTarball(r io.Reader) ([]types.Recipe, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	recipes := []types.Recipe{}
+
+	tr := tar.NewReader(gz)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		ext := filepath.Ext(h.Name)
+		if h.Typeflag != tar.TypeReg || (ext != ".yml" && ext != ".yaml") {
+			continue
+		}
+
+		content, err := ioutil.ReadAll(io.LimitReader(tr, maxRecipeFileSize))
+		if err != nil {
+			return nil, err
+		}
+
+		rec, err := recipeFromContent(content)
+		if err != nil {
+			log.Errorf("%s: %s", h.Name, err)
+			continue
+		}
+
+		if rec != nil {
+			recipes = append(recipes, *rec)
+		}
+	}
+
+	return recipes, nil
+}
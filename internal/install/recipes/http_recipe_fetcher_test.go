@@ -0,0 +1,194 @@
+// +build unit
+
+package recipes
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ed25519"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/newrelic/newrelic-cli/internal/install/types"
+)
+
+const httpTestIndex = `
+recipes:
+  - name: mysql
+    url: tarballs/mysql.tar.gz
+  - name: redis
+    url: tarballs/redis.tar.gz
+`
+
+func TestHTTPRecipeFetcher_FetchRecipe_OnlyFetchesNamedTarball(t *testing.T) {
+	srv, requests := newRecipeIndexServer(t)
+	defer srv.Close()
+
+	f := NewHTTPRecipeFetcher(srv.URL+"/index.yml", srv.Client(), nil)
+
+	r, err := f.FetchRecipe(context.Background(), &types.DiscoveryManifest{OS: "linux"}, "mysql")
+	require.NoError(t, err)
+	require.Equal(t, "mysql", r.Name)
+	require.Equal(t, 1, requests("/tarballs/mysql.tar.gz"))
+	require.Equal(t, 0, requests("/tarballs/redis.tar.gz"))
+
+	_, err = f.FetchRecipe(context.Background(), &types.DiscoveryManifest{OS: "linux"}, "mysql")
+	require.NoError(t, err)
+	require.Equal(t, 1, requests("/index.yml"))
+	require.Equal(t, 1, requests("/tarballs/mysql.tar.gz"))
+
+	_, err = f.FetchRecipe(context.Background(), &types.DiscoveryManifest{OS: "linux"}, "postgres")
+	require.ErrorIs(t, err, ErrRecipeNotFound)
+}
+
+func TestHTTPRecipeFetcher_FetchRecommendations(t *testing.T) {
+	srv, _ := newRecipeIndexServer(t)
+	defer srv.Close()
+
+	f := NewHTTPRecipeFetcher(srv.URL+"/index.yml", srv.Client(), nil)
+
+	recipes, err := f.FetchRecipes(context.Background(), &types.DiscoveryManifest{})
+	require.NoError(t, err)
+	require.Len(t, recipes, 2)
+
+	recipes, err = f.FetchRecommendations(context.Background(), &types.DiscoveryManifest{OS: "windows"})
+	require.NoError(t, err)
+	require.Len(t, recipes, 1)
+	require.Equal(t, "redis", recipes[0].Name)
+}
+
+func TestHTTPRecipeFetcher_IndexNotFound(t *testing.T) {
+	srv, _ := newRecipeIndexServer(t)
+	defer srv.Close()
+
+	f := NewHTTPRecipeFetcher(srv.URL+"/missing.yml", srv.Client(), nil)
+
+	_, err := f.FetchRecipes(context.Background(), &types.DiscoveryManifest{})
+	require.Error(t, err)
+}
+
+func TestHTTPRecipeFetcher_VerifiesSignatures(t *testing.T) {
+	dir, priv := newTestTrustStore(t)
+
+	files := recipeIndexFiles(t)
+	files["/index.yml.sig"] = ed25519.Sign(priv, files["/index.yml"])
+	files["/tarballs/mysql.tar.gz.sig"] = ed25519.Sign(priv, files["/tarballs/mysql.tar.gz"])
+
+	srv, _ := newRecipeFileServer(files)
+	defer srv.Close()
+
+	f := NewHTTPRecipeFetcher(srv.URL+"/index.yml", srv.Client(), NewRecipeVerifier(dir, true))
+
+	r, err := f.FetchRecipe(context.Background(), &types.DiscoveryManifest{OS: "linux"}, "mysql")
+	require.NoError(t, err)
+	require.Equal(t, "mysql", r.Name)
+
+	_, err = f.FetchRecipes(context.Background(), &types.DiscoveryManifest{})
+	require.ErrorIs(t, err, ErrUnsignedRecipe)
+
+	files["/index.yml.sig"] = ed25519.Sign(priv, []byte("recipes: []\n"))
+	f = NewHTTPRecipeFetcher(srv.URL+"/index.yml", srv.Client(), NewRecipeVerifier(dir, false))
+
+	_, err = f.FetchRecipes(context.Background(), &types.DiscoveryManifest{})
+	require.Error(t, err)
+}
+
+func TestHTTPRecipeFetcher_UnavailableSignature(t *testing.T) {
+	files := recipeIndexFiles(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Buckets that hide missing files answer 403 instead of 404.
+		content, ok := files[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		_, _ = w.Write(content)
+	}))
+	defer srv.Close()
+
+	missing := filepath.Join(os.TempDir(), "newrelic-missing-trust-store")
+
+	f := NewHTTPRecipeFetcher(srv.URL+"/index.yml", srv.Client(), NewRecipeVerifier(missing, false))
+	recipes, err := f.FetchRecipes(context.Background(), &types.DiscoveryManifest{})
+	require.NoError(t, err)
+	require.Len(t, recipes, 2)
+
+	f = NewHTTPRecipeFetcher(srv.URL+"/index.yml", srv.Client(), NewRecipeVerifier(missing, true))
+	_, err = f.FetchRecipes(context.Background(), &types.DiscoveryManifest{})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "403")
+}
+
+func newRecipeIndexServer(t *testing.T) (*httptest.Server, func(string) int) {
+	return newRecipeFileServer(recipeIndexFiles(t))
+}
+
+func recipeIndexFiles(t *testing.T) map[string][]byte {
+	return map[string][]byte{
+		"/index.yml":             []byte(httpTestIndex),
+		"/tarballs/mysql.tar.gz": recipeTarball(t, "mysql", "linux"),
+		"/tarballs/redis.tar.gz": recipeTarball(t, "redis", "windows"),
+	}
+}
+
+func newRecipeFileServer(files map[string][]byte) (*httptest.Server, func(string) int) {
+	var mu sync.Mutex
+	counts := map[string]int{}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		counts[r.URL.Path]++
+		content, ok := files[r.URL.Path]
+		mu.Unlock()
+
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		_, _ = w.Write(content)
+	}))
+
+	return srv, func(path string) int {
+		mu.Lock()
+		defer mu.Unlock()
+		return counts[path]
+	}
+}
+
+func recipeTarball(t *testing.T, name string, os string) []byte {
+	var b bytes.Buffer
+	gz := gzip.NewWriter(&b)
+	tw := tar.NewWriter(gz)
+
+	files := map[string]string{
+		name + "/" + name + ".yml": "name: " + name + "\ninstallTargets:\n  - type: host\n    os: " + os + "\n",
+		name + "/README.md":        "not a recipe",
+	}
+
+	for path, content := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{
+			Name:     path,
+			Mode:     0600,
+			Size:     int64(len(content)),
+			Typeflag: tar.TypeReg,
+		}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+
+	return b.Bytes()
+}
@@ -6,6 +6,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 
 	log "github.com/sirupsen/logrus"
 	"gopkg.in/yaml.v2"
@@ -64,9 +65,15 @@ func loadRecipesFromDir(ctx context.Context, path string) ([]types.Recipe, error
 		"path": path,
 	}).Debug("loading recipes")
 
+	root := path
 	err := filepath.Walk(
 		path,
 		func(path string, info os.FileInfo, err error) error {
+			// Skip hidden directories, such as .git and .github in a recipe repository.
+			if info != nil && info.IsDir() && path != root && strings.HasPrefix(info.Name(), ".") {
+				return filepath.SkipDir
+			}
+
 			ext := filepath.Ext(path)
 
 			if ext == ".yml" || ext == ".yaml" {
@@ -83,21 +90,13 @@ func loadRecipesFromDir(ctx context.Context, path string) ([]types.Recipe, error
 	recipes := []types.Recipe{}
 
 	for _, path := range recipePaths {
-		var r RecipeFile
-
 		content, err := ioutil.ReadFile(path)
 		if err != nil {
 			log.Error(err)
 			continue
 		}
 
-		err = yaml.Unmarshal(content, &r)
-		if err != nil {
-			log.Error(err)
-			continue
-		}
-
-		rec, err := r.ToRecipe()
+		rec, err := recipeFromContent(content)
 		if err != nil {
 			log.Error(err)
 			continue
@@ -110,3 +109,14 @@ func loadRecipesFromDir(ctx context.Context, path string) ([]types.Recipe, error
 
 	return recipes, nil
 }
+
+func recipeFromContent(content []byte) (*types.Recipe, error) {
+	var r RecipeFile
+
+	err := yaml.Unmarshal(content, &r)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.ToRecipe()
+}
@@ -6,7 +6,9 @@ import (
 )
 
 type mockNerdGraphClient struct {
-	respBody interface{}
+	respBody  interface{}
+	err       error
+	callCount int
 }
 
 func newMockNerdGraphClient() *mockNerdGraphClient {
@@ -16,6 +18,12 @@ func newMockNerdGraphClient() *mockNerdGraphClient {
 }
 
 func (c *mockNerdGraphClient) QueryWithResponseAndContext(ctx context.Context, query string, variables map[string]interface{}, respBody interface{}) error {
+	c.callCount++
+
+	if c.err != nil {
+		return c.err
+	}
+
 	respBodyPtrValue := reflect.ValueOf(respBody)
 	respBodyValue := reflect.Indirect(respBodyPtrValue)
 	respBodyValue.Set(reflect.ValueOf(c.respBody))
@@ -0,0 +1,162 @@
+package recipes
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// DefaultRecipeCacheTTL is how long cached recipe service responses are used
+// before the recipe service is queried again.
+const DefaultRecipeCacheTTL = 24 * time.Hour
+
+// ErrRecipeCacheMiss is used when an offline install needs a recipe service
+// response that has not been cached.
+var ErrRecipeCacheMiss = errors.New("no cached recipes found")
+
+// RecipeCache caches recipe service responses on disk.  Fresh responses are
+// served without querying the recipe service.  Stale responses are served when
+// the recipe service cannot be reached, and every response is served from the
+// cache when offline.
+type RecipeCache struct {
+	Dir     string
+	TTL     time.Duration
+	Offline bool
+	now     func() time.Time
+}
+
+type recipeCacheEntry struct {
+	FetchedAt time.Time       `json:"fetchedAt"`
+	Response  json.RawMessage `json:"response"`
+}
+
+// NewRecipeCache returns a new instance of RecipeCache.  A zero TTL uses
+// DefaultRecipeCacheTTL.
+func NewRecipeCache(dir string, ttl time.Duration, offline bool) *RecipeCache {
+	if ttl <= 0 {
+		ttl = DefaultRecipeCacheTTL
+	}
+
+	c := RecipeCache{
+		Dir:     dir,
+		TTL:     ttl,
+		Offline: offline,
+		now:     time.Now,
+	}
+
+	return &c
+}
+
+// Query runs a NerdGraph query through the cache, unmarshaling the response
+// into resp.  Responses are cached by query and variables, so each install
+// target and set of discovered processes is cached separately.
+func (c *RecipeCache) Query(ctx context.Context, client NerdGraphClient, query string, vars map[string]interface{}, resp interface{}) error {
+	path, err := c.path(query, vars)
+	if err != nil {
+		return err
+	}
+
+	entry := c.read(path)
+
+	if c.Offline {
+		if entry == nil {
+			return fmt.Errorf("%w for this host; run an install without --offline first to fill the cache", ErrRecipeCacheMiss)
+		}
+
+		log.Debugf("using recipes cached at %s", entry.FetchedAt.Format(time.RFC3339))
+		return json.Unmarshal(entry.Response, resp)
+	}
+
+	if entry != nil && c.now().Sub(entry.FetchedAt) < c.TTL {
+		log.Debugf("using recipes cached at %s", entry.FetchedAt.Format(time.RFC3339))
+		return json.Unmarshal(entry.Response, resp)
+	}
+
+	if err = client.QueryWithResponseAndContext(ctx, query, vars, resp); err != nil {
+		if entry == nil {
+			return err
+		}
+
+		log.Warnf("Could not reach the recipe service, using recipes cached at %s: %s", entry.FetchedAt.Format(time.RFC3339), err)
+		return json.Unmarshal(entry.Response, resp)
+	}
+
+	if err = c.write(path, resp); err != nil {
+		log.Debugf("could not cache recipe service response: %s", err)
+	}
+
+	return nil
+}
+
+func (c *RecipeCache) path(query string, vars map[string]interface{}) (string, error) {
+	v, err := json.Marshal(vars)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(append([]byte(query), v...))
+
+	return filepath.Join(c.Dir, fmt.Sprintf("%x.json", sum)), nil
+}
+
+func (c *RecipeCache) read(path string) *recipeCacheEntry {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	var entry recipeCacheEntry
+	if err = json.Unmarshal(content, &entry); err != nil {
+		log.Debugf("ignoring unreadable recipe cache entry %s: %s", path, err)
+		return nil
+	}
+
+	return &entry
+}
+
+// write stores a response by renaming a temp file over the entry, so
+// concurrent installs never read a partially written entry.
+func (c *RecipeCache) write(path string, resp interface{}) error {
+	r, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+
+	content, err := json.Marshal(recipeCacheEntry{
+		FetchedAt: c.now(),
+		Response:  r,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(c.Dir, 0750); err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(c.Dir, ".entry")
+	if err != nil {
+		return err
+	}
+
+	if _, err = tmp.Write(content); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	if err = tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
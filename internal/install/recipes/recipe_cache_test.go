@@ -0,0 +1,119 @@
+// +build unit
+
+package recipes
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/newrelic/newrelic-cli/internal/install/types"
+)
+
+func TestRecipeCache_ServesFreshResponses(t *testing.T) {
+	cache, now := newTestRecipeCache(t)
+
+	c := newMockNerdGraphClient()
+	c.respBody = wrapRecipes([]types.OpenInstallationRecipe{{Name: "cached"}})
+
+	s := NewServiceRecipeFetcherWithCache(c, cache)
+
+	recipes, err := s.FetchRecipes(context.Background(), &types.DiscoveryManifest{OS: "linux"})
+	require.NoError(t, err)
+	require.Equal(t, "cached", recipes[0].Name)
+
+	c.respBody = wrapRecipes([]types.OpenInstallationRecipe{{Name: "updated"}})
+	*now = now.Add(time.Hour)
+
+	recipes, err = s.FetchRecipes(context.Background(), &types.DiscoveryManifest{OS: "linux"})
+	require.NoError(t, err)
+	require.Equal(t, "cached", recipes[0].Name)
+	require.Equal(t, 1, c.callCount)
+
+	// A different install target is cached separately.
+	recipes, err = s.FetchRecipes(context.Background(), &types.DiscoveryManifest{OS: "windows"})
+	require.NoError(t, err)
+	require.Equal(t, "updated", recipes[0].Name)
+	require.Equal(t, 2, c.callCount)
+}
+
+func TestRecipeCache_RefreshesExpiredResponses(t *testing.T) {
+	cache, now := newTestRecipeCache(t)
+
+	c := newMockNerdGraphClient()
+	c.respBody = wrapRecommendations([]types.OpenInstallationRecipe{{Name: "cached"}})
+
+	s := NewServiceRecipeFetcherWithCache(c, cache)
+
+	_, err := s.FetchRecommendations(context.Background(), &types.DiscoveryManifest{})
+	require.NoError(t, err)
+
+	c.respBody = wrapRecommendations([]types.OpenInstallationRecipe{{Name: "updated"}})
+	*now = now.Add(DefaultRecipeCacheTTL + time.Minute)
+
+	recipes, err := s.FetchRecommendations(context.Background(), &types.DiscoveryManifest{})
+	require.NoError(t, err)
+	require.Equal(t, "updated", recipes[0].Name)
+	require.Equal(t, 2, c.callCount)
+}
+
+func TestRecipeCache_ServesStaleResponsesWhenServiceFails(t *testing.T) {
+	cache, now := newTestRecipeCache(t)
+
+	c := newMockNerdGraphClient()
+	c.respBody = wrapRecipes([]types.OpenInstallationRecipe{{Name: "cached"}})
+
+	s := NewServiceRecipeFetcherWithCache(c, cache)
+
+	_, err := s.FetchRecipe(context.Background(), &types.DiscoveryManifest{}, "cached")
+	require.NoError(t, err)
+
+	c.err = errors.New("timeout")
+	*now = now.Add(DefaultRecipeCacheTTL + time.Minute)
+
+	r, err := s.FetchRecipe(context.Background(), &types.DiscoveryManifest{}, "cached")
+	require.NoError(t, err)
+	require.Equal(t, "cached", r.Name)
+
+	_, err = s.FetchRecipe(context.Background(), &types.DiscoveryManifest{}, "uncached")
+	require.EqualError(t, err, "timeout")
+}
+
+func TestRecipeCache_Offline(t *testing.T) {
+	cache, _ := newTestRecipeCache(t)
+
+	c := newMockNerdGraphClient()
+	c.respBody = wrapRecipes([]types.OpenInstallationRecipe{{Name: "cached"}})
+
+	_, err := NewServiceRecipeFetcherWithCache(c, cache).FetchRecipes(context.Background(), &types.DiscoveryManifest{})
+	require.NoError(t, err)
+
+	cache.Offline = true
+	s := NewServiceRecipeFetcherWithCache(c, cache)
+
+	recipes, err := s.FetchRecipes(context.Background(), &types.DiscoveryManifest{})
+	require.NoError(t, err)
+	require.Equal(t, "cached", recipes[0].Name)
+
+	_, err = s.FetchRecommendations(context.Background(), &types.DiscoveryManifest{})
+	require.ErrorIs(t, err, ErrRecipeCacheMiss)
+	require.Equal(t, 1, c.callCount)
+}
+
+func newTestRecipeCache(t *testing.T) (*RecipeCache, *time.Time) {
+	dir, err := ioutil.TempDir("", "newrelic-recipe-cache")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	now := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	cache := NewRecipeCache(dir, 0, false)
+	cache.now = func() time.Time { return now }
+
+	return cache, &now
+}
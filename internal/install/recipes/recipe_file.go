@@ -19,6 +19,8 @@ type RecipeFile struct {
 	Description       string                                         `yaml:"description"`
 	InputVars         []VariableConfig                               `yaml:"inputVars"`
 	Install           map[string]interface{}                         `yaml:"install"`
+	Uninstall         map[string]interface{}                         `yaml:"uninstall,omitempty"`
+	Rollback          map[string]interface{}                         `yaml:"rollback,omitempty"`
 	InstallTargets    []types.OpenInstallationRecipeInstallTarget    `yaml:"installTargets"`
 	Keywords          []string                                       `yaml:"keywords"`
 	LogMatch          []types.LogMatch                               `yaml:"logMatch"`
@@ -27,6 +29,10 @@ type RecipeFile struct {
 	PreInstall        types.OpenInstallationPreInstallConfiguration  `yaml:"preInstall"`
 	PostInstall       types.OpenInstallationPostInstallConfiguration `yaml:"postInstall"`
 	ProcessMatch      []string                                       `yaml:"processMatch"`
+	ImageMatch        []string                                       `yaml:"imageMatch,omitempty"`
+	PortMatch         []int                                          `yaml:"portMatch,omitempty"`
+	RequiredPackages  []types.PackageRequirement                     `yaml:"requiredPackages,omitempty"`
+	Kubernetes        types.KubernetesOutput                         `yaml:"kubernetes,omitempty"`
 	Repository        string                                         `yaml:"repository"`
 	ValidationNRQL    string                                         `yaml:"validationNrql"`
 	SuccessLinkConfig types.OpenInstallationSuccessLinkConfig        `yaml:"successLinkConfig"`
@@ -46,6 +52,7 @@ type VariableConfig struct {
 
 type RecipeFileFetcherImpl struct {
 	HTTPGetFunc  func(string) (*http.Response, error)
+	Verifier     *RecipeVerifier
 	readFileFunc func(string) ([]byte, error)
 }
 
@@ -56,6 +63,17 @@ func NewRecipeFileFetcher() RecipeFileFetcher {
 	return &f
 }
 
+// NewVerifyingRecipeFileFetcher returns a RecipeFileFetcher that downloads
+// recipe files with the given HTTP client and checks their signatures with the
+// given verifier.
+func NewVerifyingRecipeFileFetcher(v *RecipeVerifier, client *http.Client) RecipeFileFetcher {
+	f := RecipeFileFetcherImpl{}
+	f.HTTPGetFunc = client.Get
+	f.Verifier = v
+	f.readFileFunc = defaultReadFileFunc
+	return &f
+}
+
 func defaultHTTPGetFunc(recipeURL string) (*http.Response, error) {
 	return http.Get(recipeURL)
 }
@@ -64,23 +82,81 @@ func defaultReadFileFunc(filename string) ([]byte, error) {
 	return ioutil.ReadFile(filename)
 }
 
+// FetchRecipeFile downloads a recipe file.  A sha256 digest pinned in the URL's
+// fragment is checked against the file, as is the file's detached signature
+// when a verifier is set.
 func (f *RecipeFileFetcherImpl) FetchRecipeFile(recipeURL *url.URL) (*RecipeFile, error) {
-	response, err := f.HTTPGetFunc(recipeURL.String())
+	digest, err := RecipeDigest(recipeURL.Fragment)
 	if err != nil {
 		return nil, err
 	}
 
-	if response.StatusCode < 200 || response.StatusCode > 299 {
-		return nil, fmt.Errorf("received non-2xx status code %d when retrieving recipe", response.StatusCode)
+	u := *recipeURL
+	u.Fragment = ""
+
+	body, status, err := f.get(u.String())
+	if err != nil {
+		return nil, err
 	}
 
+	if status < 200 || status > 299 {
+		return nil, fmt.Errorf("received non-2xx status code %d when retrieving recipe", status)
+	}
+
+	if digest != "" {
+		if err = VerifyRecipeDigest(u.String(), body, digest); err != nil {
+			return nil, err
+		}
+	}
+
+	if f.Verifier != nil {
+		sigURL := u.String() + RecipeSignatureExtension
+
+		signature, status, err := f.get(sigURL)
+
+		switch {
+		case err != nil:
+			err = fmt.Errorf("could not retrieve recipe signature %s: %s", sigURL, err)
+		case status == http.StatusNotFound:
+			signature = nil
+		case status < 200 || status > 299:
+			err = fmt.Errorf("received non-2xx status code %d when retrieving recipe signature %s", status, sigURL)
+		}
+
+		if err != nil {
+			if err = f.Verifier.SignatureUnavailable(u.String(), err); err != nil {
+				return nil, err
+			}
+
+			signature = nil
+		}
+
+		if err = f.Verifier.Verify(u.String(), body, signature); err != nil {
+			return nil, err
+		}
+	}
+
+	return StringToRecipeFile(string(body))
+}
+
+// get returns the status code of a URL, and its body when the request succeeded.
+func (f *RecipeFileFetcherImpl) get(u string) ([]byte, int, error) {
+	response, err := f.HTTPGetFunc(u)
+	if err != nil {
+		return nil, 0, err
+	}
 	defer response.Body.Close()
 
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return nil, response.StatusCode, nil
+	}
+
 	body, err := ioutil.ReadAll(response.Body)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
-	return StringToRecipeFile(string(body))
+
+	return body, response.StatusCode, nil
 }
 
 func (f *RecipeFileFetcherImpl) LoadRecipeFile(filename string) (*RecipeFile, error) {
@@ -118,6 +194,16 @@ func (f *RecipeFile) String() (string, error) {
 	return string(out), nil
 }
 
+// HasUninstall returns true when the recipe file defines an uninstall section.
+func (f *RecipeFile) HasUninstall() bool {
+	return len(f.Uninstall) > 0
+}
+
+// HasRollback returns true when the recipe file defines a rollback section.
+func (f *RecipeFile) HasRollback() bool {
+	return len(f.Rollback) > 0
+}
+
 func (f *RecipeFile) ToRecipe() (*types.Recipe, error) {
 	fileStr, err := f.String()
 	if err != nil {
@@ -134,6 +220,10 @@ func (f *RecipeFile) ToRecipe() (*types.Recipe, error) {
 		PreInstall:        f.PreInstall,
 		PostInstall:       f.PostInstall,
 		ProcessMatch:      f.ProcessMatch,
+		ImageMatch:        f.ImageMatch,
+		PortMatch:         f.PortMatch,
+		RequiredPackages:  f.RequiredPackages,
+		Kubernetes:        f.Kubernetes,
 		SuccessLinkConfig: f.SuccessLinkConfig,
 		LogMatch:          f.LogMatch,
 		ValidationNRQL:    f.ValidationNRQL,
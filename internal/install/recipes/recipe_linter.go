@@ -0,0 +1,563 @@
+package recipes
+
+import (
+	"fmt"
+	"html/template"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-task/task/v3/taskfile"
+	"gopkg.in/yaml.v3"
+
+	"github.com/newrelic/newrelic-cli/internal/install/types"
+)
+
+// LintSeverity is the severity of a lint diagnostic.
+type LintSeverity string
+
+// LintSeverities enumerates the severities of lint diagnostics.
+var LintSeverities = struct {
+	ERROR   LintSeverity
+	WARNING LintSeverity
+}{
+	ERROR:   "error",
+	WARNING: "warning",
+}
+
+// LintDiagnostic is a single problem found in a recipe file.
+type LintDiagnostic struct {
+	File     string       `json:"file"`
+	Line     int          `json:"line"`
+	Severity LintSeverity `json:"severity"`
+	Message  string       `json:"message"`
+}
+
+// maxPort is the highest valid TCP or UDP port.
+const maxPort = 65535
+
+func (d LintDiagnostic) String() string {
+	return fmt.Sprintf("%s:%d: %s: %s", d.File, d.Line, d.Severity, d.Message)
+}
+
+var (
+	yamlLineRegex   = regexp.MustCompile(`line (\d+)`)
+	inputVarRegex   = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	requiredFields  = []string{"name", "displayName", "description", "installTargets", "install"}
+	suggestedFields = []string{"repository", "validationNrql"}
+	inputVarFields  = []string{"name", "prompt", "secret", "default"}
+	packageFields   = []string{"name", "version"}
+	targetFields    = []string{"type", "os", "platform", "platformFamily", "platformVersion", "kernelVersion", "kernelArch"}
+)
+
+// IsRecipeFilePath returns true when the path has a recipe file extension.
+func IsRecipeFilePath(path string) bool {
+	ext := filepath.Ext(path)
+	return ext == ".yml" || ext == ".yaml"
+}
+
+// LintRecipePaths lints the recipe files found at the given paths.
+// Directories are walked for recipe files.
+func LintRecipePaths(paths []string) ([]LintDiagnostic, error) {
+	diagnostics := []LintDiagnostic{}
+
+	for _, p := range paths {
+		err := filepath.Walk(p, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if info.IsDir() || (path != p && !IsRecipeFilePath(path)) {
+				return nil
+			}
+
+			content, err := ioutil.ReadFile(path)
+			if err != nil {
+				return err
+			}
+
+			diagnostics = append(diagnostics, LintRecipeFile(path, content)...)
+
+			return nil
+		})
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return diagnostics, nil
+}
+
+// LintRecipeFile statically checks the content of a recipe file.
+func LintRecipeFile(file string, content []byte) []LintDiagnostic {
+	l := recipeLinter{file: file}
+	l.lint(content)
+
+	sort.SliceStable(l.diagnostics, func(i, j int) bool {
+		return l.diagnostics[i].Line < l.diagnostics[j].Line
+	})
+
+	return l.diagnostics
+}
+
+// HasLintErrors returns true when any of the diagnostics is an error.
+func HasLintErrors(diagnostics []LintDiagnostic) bool {
+	for _, d := range diagnostics {
+		if d.Severity == LintSeverities.ERROR {
+			return true
+		}
+	}
+
+	return false
+}
+
+type recipeLinter struct {
+	file        string
+	diagnostics []LintDiagnostic
+}
+
+func (l *recipeLinter) errorf(line int, format string, args ...interface{}) {
+	l.report(LintSeverities.ERROR, line, format, args...)
+}
+
+func (l *recipeLinter) warnf(line int, format string, args ...interface{}) {
+	l.report(LintSeverities.WARNING, line, format, args...)
+}
+
+func (l *recipeLinter) report(severity LintSeverity, line int, format string, args ...interface{}) {
+	l.diagnostics = append(l.diagnostics, LintDiagnostic{
+		File:     l.file,
+		Line:     line,
+		Severity: severity,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// yamlErrorf reports a YAML error, using the line number embedded in the
+// error message when there is one.
+func (l *recipeLinter) yamlErrorf(line int, err error, format string) {
+	if m := yamlLineRegex.FindStringSubmatch(err.Error()); m != nil {
+		if n, convErr := strconv.Atoi(m[1]); convErr == nil {
+			line = n
+		}
+	}
+
+	l.errorf(line, format, err)
+}
+
+func (l *recipeLinter) lint(content []byte) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		l.yamlErrorf(1, err, "invalid YAML: %s")
+		return
+	}
+
+	if len(doc.Content) == 0 {
+		l.errorf(1, "recipe file is empty")
+		return
+	}
+
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		l.errorf(root.Line, "recipe must be a YAML mapping")
+		return
+	}
+
+	// The recipe must load the same way the installer loads it.
+	var f RecipeFile
+	if err := root.Decode(&f); err != nil {
+		l.yamlErrorf(root.Line, err, "recipe does not match the recipe file format: %s")
+	}
+
+	known := recipeFileFields()
+	for idx := 0; idx < len(root.Content)-1; idx += 2 {
+		k := root.Content[idx]
+		if !known[k.Value] {
+			l.warnf(k.Line, "unknown field %q", k.Value)
+		}
+	}
+
+	for _, name := range requiredFields {
+		if v := mappingValue(root, name); v == nil || isEmptyNode(v) {
+			l.errorf(root.Line, "missing required field %q", name)
+		}
+	}
+
+	for _, name := range suggestedFields {
+		if v := mappingValue(root, name); v == nil || isEmptyNode(v) {
+			l.warnf(root.Line, "missing field %q", name)
+		}
+	}
+
+	l.lintInstallTargets(mappingValue(root, "installTargets"))
+	l.lintPatterns("processMatch", mappingValue(root, "processMatch"))
+	l.lintPatterns("imageMatch", mappingValue(root, "imageMatch"))
+	l.lintPorts("portMatch", mappingValue(root, "portMatch"))
+	l.lintPortMatchHasProcessMatch(mappingValue(root, "portMatch"), mappingValue(root, "processMatch"))
+	l.lintRequiredPackages(mappingValue(root, "requiredPackages"))
+	l.lintValidationNRQL(mappingValue(root, "validationNrql"))
+	l.lintInputVars(mappingValue(root, "inputVars"))
+	l.lintTaskfile("install", mappingValue(root, "install"))
+
+	if v := mappingValue(root, "uninstall"); v != nil {
+		l.lintTaskfile("uninstall", v)
+	}
+
+	if v := mappingValue(root, "rollback"); v != nil {
+		l.lintTaskfile("rollback", v)
+	}
+}
+
+func (l *recipeLinter) lintInstallTargets(n *yaml.Node) {
+	if n == nil || isEmptyNode(n) {
+		return
+	}
+
+	if n.Kind != yaml.SequenceNode {
+		l.errorf(n.Line, "installTargets must be a list")
+		return
+	}
+
+	for _, t := range n.Content {
+		if t.Kind != yaml.MappingNode {
+			l.errorf(t.Line, "install target must be a mapping")
+			continue
+		}
+
+		l.lintKeys(t, "install target", targetFields)
+		l.lintVersionRange(mappingValue(t, "kernelVersion"), "install target kernelVersion")
+		l.lintVersionRange(mappingValue(t, "platformVersion"), "install target platformVersion")
+
+		l.lintEnum(mappingValue(t, "type"), "install target type", []string{
+			string(types.OpenInstallationTargetTypeTypes.APPLICATION),
+			string(types.OpenInstallationTargetTypeTypes.CLOUD),
+			string(types.OpenInstallationTargetTypeTypes.DOCKER),
+			string(types.OpenInstallationTargetTypeTypes.HOST),
+			string(types.OpenInstallationTargetTypeTypes.KUBERNETES),
+			string(types.OpenInstallationTargetTypeTypes.SERVERLESS),
+		})
+
+		l.lintEnum(mappingValue(t, "os"), "install target os", []string{
+			string(types.OpenInstallationOperatingSystemTypes.DARWIN),
+			string(types.OpenInstallationOperatingSystemTypes.LINUX),
+			string(types.OpenInstallationOperatingSystemTypes.WINDOWS),
+		})
+
+		l.lintEnum(mappingValue(t, "platform"), "install target platform", []string{
+			string(types.OpenInstallationPlatformTypes.AMAZON),
+			string(types.OpenInstallationPlatformTypes.CENTOS),
+			string(types.OpenInstallationPlatformTypes.DEBIAN),
+			string(types.OpenInstallationPlatformTypes.REDHAT),
+			string(types.OpenInstallationPlatformTypes.SUSE),
+			string(types.OpenInstallationPlatformTypes.UBUNTU),
+		})
+
+		l.lintEnum(mappingValue(t, "platformFamily"), "install target platformFamily", []string{
+			string(types.OpenInstallationPlatformFamilyTypes.DEBIAN),
+			string(types.OpenInstallationPlatformFamilyTypes.RHEL),
+			string(types.OpenInstallationPlatformFamilyTypes.SUSE),
+		})
+	}
+}
+
+// lintVersionRange checks a version expression parses when it is a range.
+// Exact versions are compared as they are.
+func (l *recipeLinter) lintVersionRange(n *yaml.Node, field string) {
+	if n == nil || !types.IsVersionRange(n.Value) {
+		return
+	}
+
+	if _, err := types.ParseVersionRange(n.Value); err != nil {
+		l.errorf(n.Line, "invalid %s range %q: %s", field, n.Value, err)
+	}
+}
+
+// lintEnum checks a scalar is one of the valid values of an enum.  Values are
+// matched case insensitively, as they are when matching recipes.
+func (l *recipeLinter) lintEnum(n *yaml.Node, field string, valid []string) {
+	if n == nil || n.Value == "" {
+		return
+	}
+
+	for _, v := range valid {
+		if strings.EqualFold(n.Value, v) {
+			return
+		}
+	}
+
+	l.errorf(n.Line, "invalid %s %q, valid values are %s", field, n.Value, strings.Join(valid, ", "))
+}
+
+func (l *recipeLinter) lintPatterns(field string, n *yaml.Node) {
+	if n == nil || isEmptyNode(n) {
+		return
+	}
+
+	if n.Kind != yaml.SequenceNode {
+		l.errorf(n.Line, "%s must be a list", field)
+		return
+	}
+
+	for _, p := range n.Content {
+		if _, err := regexp.Compile(p.Value); err != nil {
+			l.errorf(p.Line, "invalid %s pattern %q: %s", field, p.Value, err)
+		}
+	}
+}
+
+func (l *recipeLinter) lintPorts(field string, n *yaml.Node) {
+	if n == nil || isEmptyNode(n) {
+		return
+	}
+
+	if n.Kind != yaml.SequenceNode {
+		l.errorf(n.Line, "%s must be a list", field)
+		return
+	}
+
+	for _, p := range n.Content {
+		port, err := strconv.Atoi(p.Value)
+		if err != nil || port < 1 || port > maxPort {
+			l.errorf(p.Line, "invalid %s port %q, ports must be between 1 and %d", field, p.Value, maxPort)
+		}
+	}
+}
+
+// lintPortMatchHasProcessMatch requires processMatch alongside portMatch.  A
+// process matched by port is recommended by the recipe's first processMatch
+// pattern, so a recipe without one is never recommended.
+func (l *recipeLinter) lintPortMatchHasProcessMatch(portMatch *yaml.Node, processMatch *yaml.Node) {
+	if portMatch == nil || isEmptyNode(portMatch) {
+		return
+	}
+
+	if processMatch == nil || isEmptyNode(processMatch) {
+		l.errorf(portMatch.Line, "portMatch requires processMatch, recipes matched by port are recommended by their first processMatch pattern")
+	}
+}
+
+func (l *recipeLinter) lintValidationNRQL(n *yaml.Node) {
+	if n == nil || n.Value == "" {
+		return
+	}
+
+	if _, err := template.New("validationNRQL").Parse(n.Value); err != nil {
+		l.errorf(n.Line, "invalid validationNrql template: %s", err)
+	}
+}
+
+func (l *recipeLinter) lintInputVars(n *yaml.Node) {
+	if n == nil || isEmptyNode(n) {
+		return
+	}
+
+	if n.Kind != yaml.SequenceNode {
+		l.errorf(n.Line, "inputVars must be a list")
+		return
+	}
+
+	seen := map[string]bool{}
+	for _, v := range n.Content {
+		if v.Kind != yaml.MappingNode {
+			l.errorf(v.Line, "input var must be a mapping")
+			continue
+		}
+
+		l.lintKeys(v, "input var", inputVarFields)
+
+		name := mappingValue(v, "name")
+		if name == nil || name.Value == "" {
+			l.errorf(v.Line, "input var is missing a name")
+			continue
+		}
+
+		if !inputVarRegex.MatchString(name.Value) {
+			l.errorf(name.Line, "input var name %q is not a valid environment variable name", name.Value)
+		}
+
+		if seen[name.Value] {
+			l.errorf(name.Line, "input var %q is defined more than once", name.Value)
+		}
+		seen[name.Value] = true
+
+		if s := mappingValue(v, "secret"); s != nil {
+			if _, err := strconv.ParseBool(s.Value); err != nil {
+				l.errorf(s.Line, "input var secret must be true or false")
+			}
+		}
+	}
+}
+
+func (l *recipeLinter) lintRequiredPackages(n *yaml.Node) {
+	if n == nil || isEmptyNode(n) {
+		return
+	}
+
+	if n.Kind != yaml.SequenceNode {
+		l.errorf(n.Line, "requiredPackages must be a list")
+		return
+	}
+
+	for _, p := range n.Content {
+		if p.Kind != yaml.MappingNode {
+			l.errorf(p.Line, "required package must be a mapping")
+			continue
+		}
+
+		l.lintKeys(p, "required package", packageFields)
+
+		if name := mappingValue(p, "name"); name == nil || name.Value == "" {
+			l.errorf(p.Line, "required package is missing a name")
+		}
+
+		if v := mappingValue(p, "version"); v != nil && v.Value != "" {
+			if _, err := types.ParseVersionRange(v.Value); err != nil {
+				l.errorf(v.Line, "invalid required package version range %q: %s", v.Value, err)
+			}
+		}
+	}
+}
+
+// lintTaskfile checks a recipe section is a go-task v3 Taskfile with a default
+// task, and that every task it calls exists.
+func (l *recipeLinter) lintTaskfile(section string, n *yaml.Node) {
+	if n == nil || isEmptyNode(n) {
+		return
+	}
+
+	if n.Kind != yaml.MappingNode {
+		l.errorf(n.Line, "%s must be a Taskfile mapping", section)
+		return
+	}
+
+	var tf taskfile.Taskfile
+	if err := n.Decode(&tf); err != nil {
+		l.yamlErrorf(n.Line, err, section+" is not a valid Taskfile: %s")
+		return
+	}
+
+	if v := mappingValue(n, "version"); v == nil {
+		l.errorf(n.Line, "%s is missing the Taskfile version", section)
+	} else if version, err := tf.ParsedVersion(); err != nil || version != 3 {
+		l.errorf(v.Line, "%s must be a Taskfile version 3, found %q", section, v.Value)
+	}
+
+	tasks := mappingValue(n, "tasks")
+	if tasks == nil || len(tf.Tasks) == 0 {
+		l.errorf(n.Line, "%s does not define any tasks", section)
+		return
+	}
+
+	if _, ok := tf.Tasks["default"]; !ok {
+		l.errorf(mappingKey(n, "tasks").Line, "%s does not define a default task", section)
+	}
+
+	names := make([]string, 0, len(tf.Tasks))
+	for name := range tf.Tasks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		t := tf.Tasks[name]
+		line := tasks.Line
+		if tn := mappingKey(tasks, name); tn != nil {
+			line = tn.Line
+		}
+
+		for _, c := range t.Cmds {
+			if c != nil && c.Task != "" {
+				if _, ok := tf.Tasks[c.Task]; !ok {
+					l.errorf(line, "%s task %q calls undefined task %q", section, name, c.Task)
+				}
+			}
+		}
+
+		for _, d := range t.Deps {
+			if d != nil && d.Task != "" {
+				if _, ok := tf.Tasks[d.Task]; !ok {
+					l.errorf(line, "%s task %q depends on undefined task %q", section, name, d.Task)
+				}
+			}
+		}
+	}
+}
+
+// lintKeys warns about keys of a mapping that are not in the known list.
+func (l *recipeLinter) lintKeys(n *yaml.Node, what string, known []string) {
+	for idx := 0; idx < len(n.Content)-1; idx += 2 {
+		k := n.Content[idx]
+
+		found := false
+		for _, name := range known {
+			if k.Value == name {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			l.warnf(k.Line, "unknown %s field %q", what, k.Value)
+		}
+	}
+}
+
+// recipeFileFields returns the YAML field names of a RecipeFile.
+func recipeFileFields() map[string]bool {
+	fields := map[string]bool{}
+
+	t := reflect.TypeOf(RecipeFile{})
+	for idx := 0; idx < t.NumField(); idx++ {
+		tag := strings.Split(t.Field(idx).Tag.Get("yaml"), ",")[0]
+		if tag != "" && tag != "-" {
+			fields[tag] = true
+		}
+	}
+
+	return fields
+}
+
+func mappingKey(n *yaml.Node, key string) *yaml.Node {
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	for idx := 0; idx < len(n.Content)-1; idx += 2 {
+		if n.Content[idx].Value == key {
+			return n.Content[idx]
+		}
+	}
+
+	return nil
+}
+
+func mappingValue(n *yaml.Node, key string) *yaml.Node {
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	for idx := 0; idx < len(n.Content)-1; idx += 2 {
+		if n.Content[idx].Value == key {
+			return n.Content[idx+1]
+		}
+	}
+
+	return nil
+}
+
+func isEmptyNode(n *yaml.Node) bool {
+	switch n.Kind {
+	case yaml.ScalarNode:
+		return n.Value == "" || n.Tag == "!!null"
+	case yaml.SequenceNode, yaml.MappingNode:
+		return len(n.Content) == 0
+	}
+
+	return false
+}
@@ -0,0 +1,208 @@
+// +build unit
+
+package recipes
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const validLintRecipe = `name: test-recipe
+displayName: Test Recipe
+description: A recipe for testing
+repository: https://github.com/newrelic/open-install-library
+
+installTargets:
+  - type: host
+    os: linux
+    platformFamily: debian
+
+processMatch:
+  - mysqld
+
+inputVars:
+  - name: NR_CLI_DB_USERNAME
+    prompt: Database username
+  - name: NR_CLI_DB_PASSWORD
+    prompt: Database password
+    secret: true
+
+validationNrql: "SELECT count(*) FROM SystemSample WHERE hostname like '{{.HOSTNAME}}' SINCE 10 minutes ago"
+
+install:
+  version: "3"
+  tasks:
+    default:
+      cmds:
+        - task: setup
+    setup:
+      cmds:
+        - echo setup
+`
+
+func TestLintRecipeFile_Valid(t *testing.T) {
+	diagnostics := LintRecipeFile("recipe.yml", []byte(validLintRecipe))
+	require.Empty(t, diagnostics)
+	require.False(t, HasLintErrors(diagnostics))
+}
+
+func TestLintRecipeFile_InvalidYAML(t *testing.T) {
+	diagnostics := LintRecipeFile("recipe.yml", []byte("name: a\n  bad: [\n"))
+	require.Len(t, diagnostics, 1)
+	require.Equal(t, LintSeverities.ERROR, diagnostics[0].Severity)
+	require.Equal(t, 2, diagnostics[0].Line)
+}
+
+func TestLintRecipeFile_MissingRequiredFields(t *testing.T) {
+	diagnostics := LintRecipeFile("recipe.yml", []byte("name: test-recipe\n"))
+	require.True(t, HasLintErrors(diagnostics))
+	requireDiagnostic(t, diagnostics, 1, `missing required field "displayName"`)
+	requireDiagnostic(t, diagnostics, 1, `missing required field "install"`)
+}
+
+func TestLintRecipeFile_InvalidInstallTarget(t *testing.T) {
+	content := replaceLine(validLintRecipe, "    os: linux", "    os: plan9")
+
+	diagnostics := LintRecipeFile("recipe.yml", []byte(content))
+	requireDiagnostic(t, diagnostics, 8, `invalid install target os "plan9"`)
+}
+
+func TestLintRecipeFile_InvalidVersionRange(t *testing.T) {
+	content := replaceLine(validLintRecipe, "    platformFamily: debian", "    platformFamily: debian\n    platformVersion: \">=bionic\"")
+
+	diagnostics := LintRecipeFile("recipe.yml", []byte(content))
+	requireDiagnostic(t, diagnostics, 10, `invalid install target platformVersion range ">=bionic"`)
+}
+
+func TestLintRecipeFile_InvalidProcessMatch(t *testing.T) {
+	content := replaceLine(validLintRecipe, "  - mysqld", "  - mysql(d")
+
+	diagnostics := LintRecipeFile("recipe.yml", []byte(content))
+	requireDiagnostic(t, diagnostics, 12, "invalid processMatch pattern")
+}
+
+func TestLintRecipeFile_InvalidImageMatch(t *testing.T) {
+	content := replaceLine(validLintRecipe, "  - mysqld", "  - mysqld\nimageMatch:\n  - mysql(")
+
+	diagnostics := LintRecipeFile("recipe.yml", []byte(content))
+	requireDiagnostic(t, diagnostics, 14, "invalid imageMatch pattern")
+	for _, d := range diagnostics {
+		require.NotContains(t, d.Message, "unknown field")
+	}
+}
+
+func TestLintRecipeFile_InvalidPortMatch(t *testing.T) {
+	content := replaceLine(validLintRecipe, "  - mysqld", "  - mysqld\nportMatch:\n  - 3306\n  - 70000")
+
+	diagnostics := LintRecipeFile("recipe.yml", []byte(content))
+	require.Len(t, diagnostics, 1)
+	requireDiagnostic(t, diagnostics, 15, `invalid portMatch port "70000"`)
+}
+
+func TestLintRecipeFile_PortMatchWithoutProcessMatch(t *testing.T) {
+	content := replaceLine(validLintRecipe, "processMatch:", "portMatch:")
+	content = replaceLine(content, "  - mysqld", "  - 3306")
+
+	diagnostics := LintRecipeFile("recipe.yml", []byte(content))
+	require.Len(t, diagnostics, 1)
+	requireDiagnostic(t, diagnostics, 12, "portMatch requires processMatch")
+}
+
+func TestLintRecipeFile_InvalidRequiredPackages(t *testing.T) {
+	content := replaceLine(validLintRecipe, "  - mysqld", "  - mysqld\nrequiredPackages:\n  - name: mysql-server\n    version: \">= 5.7\"\n  - version: \"not a range\"")
+
+	diagnostics := LintRecipeFile("recipe.yml", []byte(content))
+	requireDiagnostic(t, diagnostics, 16, "required package is missing a name")
+	requireDiagnostic(t, diagnostics, 16, `invalid required package version range "not a range"`)
+	require.Len(t, diagnostics, 2)
+}
+
+func TestLintRecipeFile_InvalidValidationNRQL(t *testing.T) {
+	content := replaceLine(validLintRecipe, "validationNrql: \"SELECT count(*) FROM SystemSample WHERE hostname like '{{.HOSTNAME}}' SINCE 10 minutes ago\"", "validationNrql: \"SELECT count(*) FROM SystemSample WHERE hostname like '{{.HOSTNAME'\"")
+
+	diagnostics := LintRecipeFile("recipe.yml", []byte(content))
+	requireDiagnostic(t, diagnostics, 21, "invalid validationNrql template")
+}
+
+func TestLintRecipeFile_InvalidInputVars(t *testing.T) {
+	content := replaceLine(validLintRecipe, "  - name: NR_CLI_DB_PASSWORD", "  - name: NR_CLI_DB_USERNAME")
+	content = replaceLine(content, "    secret: true", "    secret: maybe")
+
+	diagnostics := LintRecipeFile("recipe.yml", []byte(content))
+	requireDiagnostic(t, diagnostics, 17, `input var "NR_CLI_DB_USERNAME" is defined more than once`)
+	requireDiagnostic(t, diagnostics, 19, "input var secret must be true or false")
+}
+
+func TestLintRecipeFile_InvalidTaskfile(t *testing.T) {
+	content := replaceLine(validLintRecipe, `  version: "3"`, `  version: "2"`)
+	content = replaceLine(content, "        - task: setup", "        - task: missing")
+
+	diagnostics := LintRecipeFile("recipe.yml", []byte(content))
+	requireDiagnostic(t, diagnostics, 24, "install must be a Taskfile version 3")
+	requireDiagnostic(t, diagnostics, 26, `install task "default" calls undefined task "missing"`)
+}
+
+func TestLintRecipeFile_MissingDefaultTask(t *testing.T) {
+	content := replaceLine(validLintRecipe, "    default:", "    main:")
+
+	diagnostics := LintRecipeFile("recipe.yml", []byte(content))
+	requireDiagnostic(t, diagnostics, 25, "install does not define a default task")
+}
+
+func TestLintRecipeFile_InvalidRollbackTaskfile(t *testing.T) {
+	content := validLintRecipe + `
+rollback:
+  version: "3"
+  tasks:
+    default:
+      cmds:
+        - task: missing
+`
+
+	diagnostics := LintRecipeFile("recipe.yml", []byte(content))
+	require.Len(t, diagnostics, 1)
+	requireDiagnostic(t, diagnostics, 36, `rollback task "default" calls undefined task "missing"`)
+}
+
+func TestLintRecipeFile_UnknownField(t *testing.T) {
+	diagnostics := LintRecipeFile("recipe.yml", []byte(validLintRecipe+"instal: {}\n"))
+	require.False(t, HasLintErrors(diagnostics))
+	requireDiagnostic(t, diagnostics, 32, `unknown field "instal"`)
+}
+
+func TestLintRecipePaths(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "newrelic-lint")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmp)
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(tmp, "valid.yml"), []byte(validLintRecipe), 0600))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(tmp, "invalid.yaml"), []byte("name: test-recipe\n"), 0600))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(tmp, "README.md"), []byte("# recipes\n"), 0600))
+
+	diagnostics, err := LintRecipePaths([]string{tmp})
+	require.NoError(t, err)
+	require.True(t, HasLintErrors(diagnostics))
+
+	for _, d := range diagnostics {
+		require.Equal(t, filepath.Join(tmp, "invalid.yaml"), d.File)
+	}
+}
+
+func requireDiagnostic(t *testing.T, diagnostics []LintDiagnostic, line int, message string) {
+	for _, d := range diagnostics {
+		if d.Line == line && strings.Contains(d.Message, message) {
+			return
+		}
+	}
+
+	t.Fatalf("expected diagnostic on line %d containing %q, got %v", line, message, diagnostics)
+}
+
+func replaceLine(content string, old string, new string) string {
+	return strings.Replace(content, old+"\n", new+"\n", 1)
+}
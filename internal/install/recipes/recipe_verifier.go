@@ -0,0 +1,245 @@
+package recipes
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// RecipeSignatureExtension is appended to a recipe file's URL to locate its
+	// detached signature.
+	RecipeSignatureExtension = ".sig"
+
+	recipeDigestPrefix    = "sha256="
+	trustedKeyExtension   = ".pub"
+	trustedKeyCommentChar = "#"
+)
+
+// ErrUnsignedRecipe is used when signed recipes are required and a recipe file
+// has no signature.
+var ErrUnsignedRecipe = errors.New("recipe is not signed")
+
+// TrustedRecipeKey is an ed25519 public key trusted to sign recipe files.
+type TrustedRecipeKey struct {
+	Name string
+	Key  ed25519.PublicKey
+}
+
+// RecipeVerifier checks the detached ed25519 signatures of remote recipe files
+// against the keys in a trust store directory.  Each key is a file with a .pub
+// extension holding a base64-encoded public key; lines starting with # are
+// ignored.
+type RecipeVerifier struct {
+	TrustStoreDir string
+	RequireSigned bool
+
+	once    sync.Once
+	keys    []TrustedRecipeKey
+	loadErr error
+}
+
+// NewRecipeVerifier returns a new instance of RecipeVerifier.  Trusted keys are
+// loaded the first time a signature is verified.
+func NewRecipeVerifier(trustStoreDir string, requireSigned bool) *RecipeVerifier {
+	v := RecipeVerifier{
+		TrustStoreDir: trustStoreDir,
+		RequireSigned: requireSigned,
+	}
+
+	return &v
+}
+
+// Verify checks a recipe file's content against its detached signature.  A nil
+// signature means the recipe is unsigned, which is only an error when signed
+// recipes are required.  A signature that does not verify against any trusted
+// key is always an error.
+func (v *RecipeVerifier) Verify(name string, content []byte, signature []byte) error {
+	if signature == nil {
+		if v.RequireSigned {
+			return fmt.Errorf("%s: %w, and signed recipes are required", name, ErrUnsignedRecipe)
+		}
+
+		log.Debugf("recipe %s is not signed", name)
+		return nil
+	}
+
+	if err := v.loadKeys(); err != nil {
+		return err
+	}
+
+	if len(v.keys) == 0 {
+		if v.RequireSigned {
+			return fmt.Errorf("could not verify the signature of %s: no trusted keys found in %s", name, v.TrustStoreDir)
+		}
+
+		log.Debugf("not verifying the signature of %s, no trusted keys found in %s", name, v.TrustStoreDir)
+		return nil
+	}
+
+	sig, err := decodeRecipeSignature(signature)
+	if err != nil {
+		return fmt.Errorf("invalid signature for %s: %s", name, err)
+	}
+
+	for _, k := range v.keys {
+		if ed25519.Verify(k.Key, content, sig) {
+			log.Debugf("recipe %s signed by trusted key %s", name, k.Name)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("the signature of %s does not match any trusted key in %s", name, v.TrustStoreDir)
+}
+
+// SignatureUnavailable decides whether a signature that could not be retrieved
+// for reasons other than not existing, such as a 403 from a bucket that hides
+// missing files, fails the recipe.  It only does when signatures are enforced,
+// because signed recipes are required or trusted keys are present; otherwise
+// the recipe is treated as unsigned.
+func (v *RecipeVerifier) SignatureUnavailable(name string, err error) error {
+	if v.enforced() {
+		return err
+	}
+
+	log.Debugf("treating %s as unsigned: %s", name, err)
+	return nil
+}
+
+// enforced returns true when recipes must be signed by a trusted key, either
+// because signed recipes are required or because trusted keys are present.
+func (v *RecipeVerifier) enforced() bool {
+	if v.RequireSigned {
+		return true
+	}
+
+	// A trust store that cannot be loaded fails verification, so signatures
+	// are enforced.
+	if err := v.loadKeys(); err != nil {
+		return true
+	}
+
+	return len(v.keys) > 0
+}
+
+func (v *RecipeVerifier) loadKeys() error {
+	v.once.Do(func() {
+		v.keys, v.loadErr = LoadTrustedRecipeKeys(v.TrustStoreDir)
+	})
+
+	return v.loadErr
+}
+
+// LoadTrustedRecipeKeys loads the public keys in a trust store directory.  A
+// missing directory holds no keys.
+func LoadTrustedRecipeKeys(dir string) ([]TrustedRecipeKey, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*"+trustedKeyExtension))
+	if err != nil {
+		return nil, err
+	}
+
+	keys := []TrustedRecipeKey{}
+	for _, path := range paths {
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+
+			return nil, err
+		}
+
+		key, err := parseTrustedRecipeKey(content)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted recipe key %s: %s", path, err)
+		}
+
+		keys = append(keys, TrustedRecipeKey{
+			Name: strings.TrimSuffix(filepath.Base(path), trustedKeyExtension),
+			Key:  key,
+		})
+	}
+
+	return keys, nil
+}
+
+func parseTrustedRecipeKey(content []byte) (ed25519.PublicKey, error) {
+	var encoded strings.Builder
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, trustedKeyCommentChar) {
+			continue
+		}
+
+		encoded.WriteString(line)
+	}
+
+	key, err := base64.StdEncoding.DecodeString(encoded.String())
+	if err != nil {
+		return nil, err
+	}
+
+	if len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("expected a %d byte ed25519 public key, got %d bytes", ed25519.PublicKeySize, len(key))
+	}
+
+	return ed25519.PublicKey(key), nil
+}
+
+// decodeRecipeSignature accepts a raw or base64-encoded ed25519 signature.
+func decodeRecipeSignature(signature []byte) ([]byte, error) {
+	if len(signature) == ed25519.SignatureSize {
+		return signature, nil
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sig) != ed25519.SignatureSize {
+		return nil, fmt.Errorf("expected a %d byte ed25519 signature, got %d bytes", ed25519.SignatureSize, len(sig))
+	}
+
+	return sig, nil
+}
+
+// RecipeDigest returns the sha256 digest pinned in a recipe URL's fragment,
+// as in https://example.com/mysql.yml#sha256=<hex digest>.
+func RecipeDigest(fragment string) (string, error) {
+	if fragment == "" {
+		return "", nil
+	}
+
+	if !strings.HasPrefix(fragment, recipeDigestPrefix) {
+		return "", fmt.Errorf("unsupported recipe URL fragment %q, expected %s<hex digest>", fragment, recipeDigestPrefix)
+	}
+
+	digest := strings.ToLower(strings.TrimPrefix(fragment, recipeDigestPrefix))
+	if b, err := hex.DecodeString(digest); err != nil || len(b) != sha256.Size {
+		return "", fmt.Errorf("invalid sha256 digest %q", digest)
+	}
+
+	return digest, nil
+}
+
+// VerifyRecipeDigest checks a recipe file's content against a pinned sha256
+// digest.
+func VerifyRecipeDigest(name string, content []byte, digest string) error {
+	sum := sha256.Sum256(content)
+	if actual := hex.EncodeToString(sum[:]); actual != digest {
+		return fmt.Errorf("%s does not match its pinned sha256 digest: expected %s, got %s", name, digest, actual)
+	}
+
+	return nil
+}
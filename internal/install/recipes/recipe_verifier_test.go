@@ -0,0 +1,204 @@
+// +build unit
+
+package recipes
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const verifierTestRecipe = "name: signed-recipe\n"
+
+func TestRecipeVerifier_Verify(t *testing.T) {
+	dir, priv := newTestTrustStore(t)
+
+	v := NewRecipeVerifier(dir, false)
+
+	sig := ed25519.Sign(priv, []byte(verifierTestRecipe))
+	require.NoError(t, v.Verify("r", []byte(verifierTestRecipe), sig))
+	require.NoError(t, v.Verify("r", []byte(verifierTestRecipe), []byte(base64.StdEncoding.EncodeToString(sig)+"\n")))
+
+	require.Error(t, v.Verify("r", []byte("name: tampered\n"), sig))
+	require.Error(t, v.Verify("r", []byte(verifierTestRecipe), []byte("not a signature")))
+
+	require.NoError(t, v.Verify("r", []byte(verifierTestRecipe), nil))
+}
+
+func TestRecipeVerifier_RequireSigned(t *testing.T) {
+	dir, _ := newTestTrustStore(t)
+
+	v := NewRecipeVerifier(dir, true)
+	require.ErrorIs(t, v.Verify("r", []byte(verifierTestRecipe), nil), ErrUnsignedRecipe)
+
+	_, otherKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	require.Error(t, v.Verify("r", []byte(verifierTestRecipe), ed25519.Sign(otherKey, []byte(verifierTestRecipe))))
+}
+
+func TestRecipeVerifier_EmptyTrustStore(t *testing.T) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	sig := ed25519.Sign(priv, []byte(verifierTestRecipe))
+
+	missing := filepath.Join(os.TempDir(), "newrelic-missing-trust-store")
+
+	require.NoError(t, NewRecipeVerifier(missing, false).Verify("r", []byte(verifierTestRecipe), sig))
+	require.Error(t, NewRecipeVerifier(missing, true).Verify("r", []byte(verifierTestRecipe), sig))
+}
+
+func TestLoadTrustedRecipeKeys_InvalidKey(t *testing.T) {
+	dir, err := ioutil.TempDir("", "newrelic-trust-store")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "bad.pub"), []byte("# comment\nc2hvcnQ=\n"), 0600))
+
+	_, err = LoadTrustedRecipeKeys(dir)
+	require.Error(t, err)
+}
+
+func TestRecipeDigest(t *testing.T) {
+	d, err := RecipeDigest("")
+	require.NoError(t, err)
+	require.Empty(t, d)
+
+	sum := sha256.Sum256([]byte(verifierTestRecipe))
+	d, err = RecipeDigest("sha256=" + hex.EncodeToString(sum[:]))
+	require.NoError(t, err)
+	require.Equal(t, hex.EncodeToString(sum[:]), d)
+
+	_, err = RecipeDigest("sha256=abc")
+	require.Error(t, err)
+
+	_, err = RecipeDigest("md5=abc")
+	require.Error(t, err)
+}
+
+func TestFetchRecipeFile_PinnedDigest(t *testing.T) {
+	sum := sha256.Sum256([]byte(verifierTestRecipe))
+
+	f := RecipeFileFetcherImpl{HTTPGetFunc: httpGetFiles(map[string][]byte{
+		"https://localhost/recipe.yml": []byte(verifierTestRecipe),
+	})}
+
+	u, err := url.Parse("https://localhost/recipe.yml#sha256=" + hex.EncodeToString(sum[:]))
+	require.NoError(t, err)
+
+	r, err := f.FetchRecipeFile(u)
+	require.NoError(t, err)
+	require.Equal(t, "signed-recipe", r.Name)
+
+	other := sha256.Sum256([]byte("other"))
+	u, err = url.Parse("https://localhost/recipe.yml#sha256=" + hex.EncodeToString(other[:]))
+	require.NoError(t, err)
+
+	_, err = f.FetchRecipeFile(u)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "does not match its pinned sha256 digest")
+}
+
+func TestFetchRecipeFile_Signature(t *testing.T) {
+	dir, priv := newTestTrustStore(t)
+
+	files := map[string][]byte{
+		"https://localhost/signed.yml":     []byte(verifierTestRecipe),
+		"https://localhost/signed.yml.sig": []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(priv, []byte(verifierTestRecipe)))),
+		"https://localhost/unsigned.yml":   []byte(verifierTestRecipe),
+	}
+
+	f := RecipeFileFetcherImpl{
+		HTTPGetFunc: httpGetFiles(files),
+		Verifier:    NewRecipeVerifier(dir, true),
+	}
+
+	u, _ := url.Parse("https://localhost/signed.yml")
+	r, err := f.FetchRecipeFile(u)
+	require.NoError(t, err)
+	require.Equal(t, "signed-recipe", r.Name)
+
+	u, _ = url.Parse("https://localhost/unsigned.yml")
+	_, err = f.FetchRecipeFile(u)
+	require.ErrorIs(t, err, ErrUnsignedRecipe)
+
+	f.Verifier = NewRecipeVerifier(dir, false)
+	_, err = f.FetchRecipeFile(u)
+	require.NoError(t, err)
+}
+
+func TestFetchRecipeFile_UnavailableSignature(t *testing.T) {
+	files := map[string][]byte{
+		"https://localhost/unsigned.yml": []byte(verifierTestRecipe),
+	}
+
+	// Buckets that hide missing files answer 403 instead of 404.
+	forbidden := func(u string) (*http.Response, error) {
+		if content, ok := files[u]; ok {
+			return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewReader(content))}, nil
+		}
+
+		return &http.Response{StatusCode: http.StatusForbidden, Body: ioutil.NopCloser(bytes.NewReader(nil))}, nil
+	}
+
+	missing := filepath.Join(os.TempDir(), "newrelic-missing-trust-store")
+	f := RecipeFileFetcherImpl{
+		HTTPGetFunc: forbidden,
+		Verifier:    NewRecipeVerifier(missing, false),
+	}
+
+	u, _ := url.Parse("https://localhost/unsigned.yml")
+	_, err := f.FetchRecipeFile(u)
+	require.NoError(t, err)
+
+	dir, _ := newTestTrustStore(t)
+	f.Verifier = NewRecipeVerifier(dir, false)
+	_, err = f.FetchRecipeFile(u)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "403")
+
+	f.Verifier = NewRecipeVerifier(missing, true)
+	_, err = f.FetchRecipeFile(u)
+	require.Error(t, err)
+}
+
+func newTestTrustStore(t *testing.T) (string, ed25519.PrivateKey) {
+	dir, err := ioutil.TempDir("", "newrelic-trust-store")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	content := "# recipe signing key\n" + base64.StdEncoding.EncodeToString(pub) + "\n"
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "acme.pub"), []byte(content), 0600))
+
+	return dir, priv
+}
+
+func httpGetFiles(files map[string][]byte) func(string) (*http.Response, error) {
+	return func(u string) (*http.Response, error) {
+		content, ok := files[u]
+		if !ok {
+			return &http.Response{
+				StatusCode: http.StatusNotFound,
+				Body:       ioutil.NopCloser(bytes.NewReader(nil)),
+			}, nil
+		}
+
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(bytes.NewReader(content)),
+		}, nil
+	}
+}
@@ -15,6 +15,7 @@ import (
 // relies on the Neerdgraph-stitched recipe service to source its results.
 type ServiceRecipeFetcher struct {
 	client NerdGraphClient
+	cache  *RecipeCache
 }
 
 // NewServiceRecipeFetcher returns a new instance of ServiceRecipeFetcher.
@@ -26,6 +27,25 @@ func NewServiceRecipeFetcher(client NerdGraphClient) RecipeFetcher {
 	return &f
 }
 
+// NewServiceRecipeFetcherWithCache returns a new instance of ServiceRecipeFetcher
+// that caches recipe service responses.
+func NewServiceRecipeFetcherWithCache(client NerdGraphClient, cache *RecipeCache) RecipeFetcher {
+	f := ServiceRecipeFetcher{
+		client: client,
+		cache:  cache,
+	}
+
+	return &f
+}
+
+func (f *ServiceRecipeFetcher) query(ctx context.Context, query string, vars map[string]interface{}, resp interface{}) error {
+	if f.cache != nil {
+		return f.cache.Query(ctx, f.client, query, vars, resp)
+	}
+
+	return f.client.QueryWithResponseAndContext(ctx, query, vars, resp)
+}
+
 // FetchRecipe gets a recipe by name from the recipe service.
 func (f *ServiceRecipeFetcher) FetchRecipe(ctx context.Context, manifest *types.DiscoveryManifest, friendlyName string) (*types.Recipe, error) {
 	log.WithFields(log.Fields{
@@ -42,7 +62,7 @@ func (f *ServiceRecipeFetcher) FetchRecipe(ctx context.Context, manifest *types.
 	}
 
 	var resp recipeSearchQueryResult
-	if err := f.client.QueryWithResponseAndContext(ctx, recipeSearchQuery, vars, &resp); err != nil {
+	if err := f.query(ctx, recipeSearchQuery, vars, &resp); err != nil {
 		return nil, err
 	}
 
@@ -81,12 +101,16 @@ func (f *ServiceRecipeFetcher) FetchRecommendations(ctx context.Context, manifes
 	}
 
 	var resp recommendationsQueryResult
-	if err := f.client.QueryWithResponseAndContext(ctx, recommendationsQuery, vars, &resp); err != nil {
+	if err := f.query(ctx, recommendationsQuery, vars, &resp); err != nil {
 		return nil, err
 	}
 
 	allRecipes := resp.Docs.OpenInstallation.Recommendations.ToRecipes()
 
+	if manifest.Kubernetes != nil {
+		allRecipes = append(allRecipes, f.fetchKubernetesRecipes(ctx)...)
+	}
+
 	r := []types.Recipe{}
 
 	recipeIncluded := func(recipe types.Recipe, recipes []types.Recipe) bool {
@@ -122,13 +146,35 @@ func (f *ServiceRecipeFetcher) FetchRecipes(ctx context.Context, manifest *types
 		"criteria": criteria,
 	}
 
-	if err := f.client.QueryWithResponseAndContext(ctx, recipeSearchQuery, vars, &resp); err != nil {
+	if err := f.query(ctx, recipeSearchQuery, vars, &resp); err != nil {
 		return nil, err
 	}
 
 	return resp.Docs.OpenInstallation.RecipeSearch.ToRecipes(), nil
 }
 
+// fetchKubernetesRecipes searches for the recipes that target Kubernetes, which
+// are recommended when discovery finds a cluster.  Recommendations are still
+// made for the host when the search fails.
+func (f *ServiceRecipeFetcher) fetchKubernetesRecipes(ctx context.Context) []types.Recipe {
+	var resp recipeSearchQueryResult
+
+	vars := map[string]interface{}{
+		"criteria": recipeSearchInput{
+			InstallTarget: installTarget{
+				Type: string(types.OpenInstallationTargetTypeTypes.KUBERNETES),
+			},
+		},
+	}
+
+	if err := f.query(ctx, recipeSearchQuery, vars, &resp); err != nil {
+		log.Debugf("could not search for Kubernetes recipes: %s", err)
+		return nil
+	}
+
+	return resp.Docs.OpenInstallation.RecipeSearch.ToRecipes()
+}
+
 type recommendationsQueryResult struct {
 	Docs recommendationsQueryDocs `json:"docs"`
 }
@@ -244,6 +290,8 @@ func createRecipes(results []types.OpenInstallationRecipe) []types.Recipe {
 }
 
 func createRecipe(result types.OpenInstallationRecipe) types.Recipe {
+	f := recipeFileFromResult(result.File)
+
 	return types.Recipe{
 		ID:                result.ID,
 		Description:       result.Description,
@@ -254,6 +302,10 @@ func createRecipe(result types.OpenInstallationRecipe) types.Recipe {
 		LogMatch:          createLogMatches(result.LogMatch),
 		Name:              result.Name,
 		ProcessMatch:      result.ProcessMatch,
+		ImageMatch:        f.ImageMatch,
+		PortMatch:         f.PortMatch,
+		RequiredPackages:  f.RequiredPackages,
+		Kubernetes:        f.Kubernetes,
 		Repository:        result.Repository,
 		ValidationNRQL:    string(result.ValidationNRQL),
 		PreInstall:        result.PreInstall,
@@ -267,6 +319,21 @@ func createRecipe(result types.OpenInstallationRecipe) types.Recipe {
 	}
 }
 
+// recipeFileFromResult reads the fields of a recipe that the recipe service does not
+// return on their own from the recipe's file.
+func recipeFileFromResult(file string) RecipeFile {
+	if file == "" {
+		return RecipeFile{}
+	}
+
+	f, err := NewRecipeFile(file)
+	if err != nil {
+		return RecipeFile{}
+	}
+
+	return *f
+}
+
 func createLogMatches(results []types.OpenInstallationLogMatch) []types.LogMatch {
 	r := make([]types.LogMatch, len(results))
 	for _, result := range results {
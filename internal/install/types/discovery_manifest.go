@@ -1,21 +1,57 @@
 package types
 
 import (
+	"encoding/json"
+	"fmt"
+	"sort"
 	"strings"
 
 	log "github.com/sirupsen/logrus"
 )
 
-// DiscoveryManifest contains the discovered information about the host.
+// DiscoveryManifest contains the discovered information about the host.  The
+// host's package inventory is only used to check recipe package requirements,
+// and is left out of the install statuses the manifest is reported in.
 type DiscoveryManifest struct {
-	Hostname        string           `json:"hostname"`
-	KernelArch      string           `json:"kernelArch"`
-	KernelVersion   string           `json:"kernelVersion"`
-	OS              string           `json:"os"`
-	Platform        string           `json:"platform"`
-	PlatformFamily  string           `json:"platformFamily"`
-	PlatformVersion string           `json:"platformVersion"`
-	Processes       []MatchedProcess `json:"processes"`
+	Hostname        string             `json:"hostname"`
+	KernelArch      string             `json:"kernelArch"`
+	KernelVersion   string             `json:"kernelVersion"`
+	OS              string             `json:"os"`
+	Platform        string             `json:"platform"`
+	PlatformFamily  string             `json:"platformFamily"`
+	PlatformVersion string             `json:"platformVersion"`
+	Processes       []MatchedProcess   `json:"processes"`
+	Containers      []Container        `json:"containers,omitempty"`
+	Packages        []Package          `json:"-"`
+	Kubernetes      *KubernetesCluster `json:"kubernetes,omitempty"`
+}
+
+// KubernetesCluster is a Kubernetes cluster the host can reach, found through
+// a kubeconfig or an in-cluster service account.
+type KubernetesCluster struct {
+	Name    string `json:"name"`
+	Context string `json:"context,omitempty"`
+	Server  string `json:"server"`
+	Version string `json:"version,omitempty"`
+	Source  string `json:"source"`
+}
+
+// Container is a container discovered through a container runtime's API.
+type Container struct {
+	ID      string            `json:"id"`
+	Name    string            `json:"name"`
+	Image   string            `json:"image"`
+	Labels  map[string]string `json:"labels,omitempty"`
+	Ports   []ContainerPort   `json:"ports,omitempty"`
+	Runtime string            `json:"runtime"`
+}
+
+// ContainerPort is a port exposed by a container.  PublicPort is zero when the
+// port is not published on the host.
+type ContainerPort struct {
+	PrivatePort int    `json:"privatePort"`
+	PublicPort  int    `json:"publicPort,omitempty"`
+	Protocol    string `json:"protocol"`
 }
 
 // GenericProcess is an abstracted representation of a process.
@@ -23,12 +59,41 @@ type GenericProcess interface {
 	Name() (string, error)
 	Cmdline() (string, error)
 	PID() int32
+	ListeningPorts() ([]ListeningPort, error)
+}
+
+// ListeningPort is a TCP or UDP port a process listens on.
+type ListeningPort struct {
+	Port     int    `json:"port"`
+	Protocol string `json:"protocol"`
 }
 
 type MatchedProcess struct {
 	Command         string `json:"command"`
 	Process         GenericProcess
 	MatchingPattern string
+	Ports           []ListeningPort `json:"ports,omitempty"`
+}
+
+// UnmarshalJSON restores a MatchedProcess from a previously serialized
+// manifest.  The underlying process is not restored, since it only existed for
+// the lifetime of the CLI process that discovered it.
+func (p *MatchedProcess) UnmarshalJSON(b []byte) error {
+	var v struct {
+		Command         string `json:"command"`
+		MatchingPattern string
+		Ports           []ListeningPort `json:"ports"`
+	}
+
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+
+	p.Command = v.Command
+	p.MatchingPattern = v.MatchingPattern
+	p.Ports = v.Ports
+
+	return nil
 }
 
 // AddMatchedProcess adds a discovered process to the underlying manifest.
@@ -36,6 +101,79 @@ func (d *DiscoveryManifest) AddMatchedProcess(p MatchedProcess) {
 	d.Processes = append(d.Processes, p)
 }
 
+// AddContainer adds a discovered container to the underlying manifest.
+func (d *DiscoveryManifest) AddContainer(c Container) {
+	d.Containers = append(d.Containers, c)
+}
+
+// MatchedPorts returns every port the processes matched for a recipe listen
+// on, whether they were matched by its processMatch patterns or its portMatch
+// ports.  Ports in the recipe's portMatch come first.
+func (d *DiscoveryManifest) MatchedPorts(r Recipe) []int {
+	ports := []int{}
+	seen := map[int]bool{}
+
+	for _, p := range d.RecipeProcesses(r) {
+		for _, lp := range p.Ports {
+			if seen[lp.Port] {
+				continue
+			}
+
+			seen[lp.Port] = true
+			ports = append(ports, lp.Port)
+		}
+	}
+
+	sort.SliceStable(ports, func(i, j int) bool {
+		return containsPort(r.PortMatch, ports[i]) && !containsPort(r.PortMatch, ports[j])
+	})
+
+	return ports
+}
+
+// RecipeProcesses returns the processes matched for a recipe, by its
+// processMatch patterns or the ports in its portMatch.
+func (d *DiscoveryManifest) RecipeProcesses(r Recipe) []MatchedProcess {
+	processes := []MatchedProcess{}
+
+	for _, p := range d.Processes {
+		matched := containsString(r.ProcessMatch, p.MatchingPattern)
+		for _, lp := range p.Ports {
+			matched = matched || containsPort(r.PortMatch, lp.Port)
+		}
+
+		if matched {
+			processes = append(processes, p)
+		}
+	}
+
+	return processes
+}
+
+func containsPort(ports []int, port int) bool {
+	for _, p := range ports {
+		if p == port {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ConstrainRecipes returns the recipes with an install target matching the
+// manifest.  The platformVersion and kernelVersion of a target are either exact
+// versions or ranges such as ">=18.04 <22.04".  Why each recipe was included or
+// excluded is logged at debug level.
 func (d *DiscoveryManifest) ConstrainRecipes(allRecipes []Recipe) []Recipe {
 	var recipes []Recipe
 
@@ -44,48 +182,93 @@ func (d *DiscoveryManifest) ConstrainRecipes(allRecipes []Recipe) []Recipe {
 			log.Warnf("recipe has no InstallTargets: %s", recipe.Name)
 		}
 
-		for _, target := range recipe.InstallTargets {
-			if target.KernelArch != "" {
-				if !strings.EqualFold(target.KernelArch, d.KernelArch) {
-					continue
-				}
-			}
-
-			if target.KernelVersion != "" {
-				if !strings.EqualFold(target.KernelVersion, d.KernelVersion) {
-					continue
-				}
-			}
+		if err := d.CheckPackageRequirements(recipe); err != nil {
+			log.Debugf("excluding recipe %s: %s", recipe.Name, err)
+			continue
+		}
 
-			if target.Os != "" {
-				if !strings.EqualFold(string(target.Os), d.OS) {
-					continue
-				}
-			}
+		reasons := []string{}
+		matched := false
 
-			if target.Platform != "" {
-				if !strings.EqualFold(string(target.Platform), d.Platform) {
-					continue
-				}
+		for i, target := range recipe.InstallTargets {
+			reason := d.mismatchedTarget(target)
+			if reason == "" {
+				log.Debugf("including recipe %s: install target %d matches", recipe.Name, i+1)
+				matched = true
+				break
 			}
 
-			if target.PlatformFamily != "" {
-				if !strings.EqualFold(string(target.PlatformFamily), d.PlatformFamily) {
-					continue
-				}
-			}
+			reasons = append(reasons, fmt.Sprintf("install target %d %s", i+1, reason))
+		}
 
-			if target.PlatformVersion != "" {
-				if !strings.EqualFold(target.PlatformVersion, d.PlatformVersion) {
-					continue
-				}
+		if !matched {
+			if len(reasons) > 0 {
+				log.Debugf("excluding recipe %s: %s", recipe.Name, strings.Join(reasons, "; "))
 			}
 
-			recipes = append(recipes, recipe)
+			continue
 		}
+
+		recipes = append(recipes, recipe)
 	}
 
 	log.Debugf("%d recipes found for manifest", len(recipes))
 
 	return recipes
 }
+
+// mismatchedTarget returns why an install target does not match the manifest,
+// or an empty string when it matches.
+func (d *DiscoveryManifest) mismatchedTarget(target OpenInstallationRecipeInstallTarget) string {
+	// Kubernetes targets describe a cluster rather than the host.
+	if strings.EqualFold(string(target.Type), string(OpenInstallationTargetTypeTypes.KUBERNETES)) {
+		if d.Kubernetes == nil {
+			return "requires a Kubernetes cluster, none was discovered"
+		}
+
+		return ""
+	}
+
+	fields := []struct {
+		name     string
+		expected string
+		actual   string
+	}{
+		{"kernelArch", target.KernelArch, d.KernelArch},
+		{"os", string(target.Os), d.OS},
+		{"platform", string(target.Platform), d.Platform},
+		{"platformFamily", string(target.PlatformFamily), d.PlatformFamily},
+	}
+
+	for _, f := range fields {
+		if f.expected != "" && !strings.EqualFold(f.expected, f.actual) {
+			return fmt.Sprintf("requires %s %s, found %q", f.name, f.expected, f.actual)
+		}
+	}
+
+	versions := []struct {
+		name   string
+		expr   string
+		actual string
+	}{
+		{"kernelVersion", target.KernelVersion, d.KernelVersion},
+		{"platformVersion", target.PlatformVersion, d.PlatformVersion},
+	}
+
+	for _, v := range versions {
+		if v.expr == "" {
+			continue
+		}
+
+		ok, err := MatchVersion(v.expr, v.actual)
+		if err != nil {
+			return fmt.Sprintf("could not compare %s %q with %s: %s", v.name, v.actual, v.expr, err)
+		}
+
+		if !ok {
+			return fmt.Sprintf("requires %s %s, found %q", v.name, v.expr, v.actual)
+		}
+	}
+
+	return ""
+}
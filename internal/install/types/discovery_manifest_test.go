@@ -150,3 +150,153 @@ func TestDiscoveryManifest_ConstrainRecipes(t *testing.T) {
 	}
 
 }
+
+func TestDiscoveryManifest_ConstrainRecipes_Kubernetes(t *testing.T) {
+	recipes := []Recipe{
+		{
+			Name: "host",
+			InstallTargets: []OpenInstallationRecipeInstallTarget{
+				{
+					Os:   OpenInstallationOperatingSystemTypes.LINUX,
+					Type: OpenInstallationTargetTypeTypes.HOST,
+				},
+			},
+		},
+		{
+			Name: "kubernetes",
+			InstallTargets: []OpenInstallationRecipeInstallTarget{
+				{
+					Type: OpenInstallationTargetTypeTypes.KUBERNETES,
+				},
+			},
+		},
+	}
+
+	m := DiscoveryManifest{OS: "darwin"}
+	require.Empty(t, m.ConstrainRecipes(recipes))
+
+	m.Kubernetes = &KubernetesCluster{Name: "test-cluster"}
+	r := m.ConstrainRecipes(recipes)
+	require.Len(t, r, 1)
+	require.Equal(t, "kubernetes", r[0].Name)
+}
+
+func TestDiscoveryManifest_MatchedPorts(t *testing.T) {
+	m := DiscoveryManifest{
+		Processes: []MatchedProcess{
+			{
+				Command:         "java -jar cache.jar",
+				MatchingPattern: "redis-server",
+				Ports:           []ListeningPort{{Port: 6379, Protocol: "tcp"}, {Port: 8080, Protocol: "tcp"}},
+			},
+			{
+				Command:         "postgres",
+				MatchingPattern: "postgres",
+				Ports:           []ListeningPort{{Port: 5432, Protocol: "tcp"}},
+			},
+		},
+	}
+
+	require.Equal(t, []int{6379, 8080}, m.MatchedPorts(Recipe{ProcessMatch: []string{"redis-server"}, PortMatch: []int{6379}}))
+	require.Equal(t, []int{5432}, m.MatchedPorts(Recipe{ProcessMatch: []string{"postgres"}}))
+	require.Empty(t, m.MatchedPorts(Recipe{ProcessMatch: []string{"mysqld"}}))
+}
+
+func TestDiscoveryManifest_MatchedPorts_ProcessMatchWithPortMatch(t *testing.T) {
+	m := DiscoveryManifest{
+		Processes: []MatchedProcess{
+			{
+				Command:         "redis-server *:6380",
+				MatchingPattern: "redis-server",
+				Ports:           []ListeningPort{{Port: 6380, Protocol: "tcp"}},
+			},
+			{
+				Command:         "java -jar cache.jar",
+				MatchingPattern: "redis-server",
+				Ports:           []ListeningPort{{Port: 6379, Protocol: "tcp"}},
+			},
+		},
+	}
+
+	require.Equal(t, []int{6379, 6380}, m.MatchedPorts(Recipe{ProcessMatch: []string{"redis-server"}, PortMatch: []int{6379}}))
+}
+
+func TestDiscoveryManifest_ConstrainRecipes_VersionRanges(t *testing.T) {
+	recipes := []Recipe{
+		{
+			Name: "ubuntu-lts",
+			InstallTargets: []OpenInstallationRecipeInstallTarget{
+				{
+					Os:              OpenInstallationOperatingSystemTypes.LINUX,
+					Platform:        OpenInstallationPlatformTypes.UBUNTU,
+					PlatformVersion: ">=18.04 <22.04",
+				},
+			},
+		},
+		{
+			Name: "centos-7",
+			InstallTargets: []OpenInstallationRecipeInstallTarget{
+				{
+					Platform:        OpenInstallationPlatformTypes.CENTOS,
+					PlatformVersion: "~7",
+				},
+			},
+		},
+		{
+			Name: "modern-kernel",
+			InstallTargets: []OpenInstallationRecipeInstallTarget{
+				{
+					Os:            OpenInstallationOperatingSystemTypes.LINUX,
+					KernelVersion: ">=5.4",
+				},
+			},
+		},
+	}
+
+	cases := []struct {
+		manifest DiscoveryManifest
+		results  []string
+	}{
+		{
+			manifest: DiscoveryManifest{OS: "linux", Platform: "ubuntu", PlatformVersion: "20.04", KernelVersion: "5.4.0-1045-aws"},
+			results:  []string{"ubuntu-lts", "modern-kernel"},
+		},
+		{
+			manifest: DiscoveryManifest{OS: "linux", Platform: "ubuntu", PlatformVersion: "16.04", KernelVersion: "4.4.0-210-generic"},
+			results:  []string{},
+		},
+		{
+			manifest: DiscoveryManifest{OS: "linux", Platform: "centos", PlatformVersion: "7.9.2009", KernelVersion: "3.10.0-1160.el7.x86_64"},
+			results:  []string{"centos-7"},
+		},
+	}
+
+	for _, c := range cases {
+		names := []string{}
+		for _, r := range c.manifest.ConstrainRecipes(recipes) {
+			names = append(names, r.Name)
+		}
+
+		require.Equal(t, c.results, names)
+	}
+}
+
+func TestDiscoveryManifest_RecipeProcesses(t *testing.T) {
+	m := DiscoveryManifest{
+		Processes: []MatchedProcess{
+			{Command: "nginx", MatchingPattern: "nginx"},
+			{Command: "redis-server", MatchingPattern: "6379", Ports: []ListeningPort{{Port: 6379, Protocol: "tcp"}}},
+			{Command: "java", MatchingPattern: "java"},
+		},
+	}
+
+	processes := m.RecipeProcesses(Recipe{ProcessMatch: []string{"nginx"}})
+	require.Len(t, processes, 1)
+	require.Equal(t, "nginx", processes[0].Command)
+
+	processes = m.RecipeProcesses(Recipe{PortMatch: []int{6379}})
+	require.Len(t, processes, 1)
+	require.Equal(t, "redis-server", processes[0].Command)
+
+	require.Empty(t, m.RecipeProcesses(Recipe{ProcessMatch: []string{"mysqld"}}))
+}
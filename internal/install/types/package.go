@@ -0,0 +1,85 @@
+package types
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Package is a package installed on the host, as recorded by its package
+// manager.
+type Package struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Source  string `json:"source"`
+}
+
+// PackageRequirement is a package a recipe requires to be installed, optionally
+// in a semver range such as ">= 1.18" or "~1.20".
+type PackageRequirement struct {
+	Name    string `json:"name" yaml:"name"`
+	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+}
+
+func (r PackageRequirement) String() string {
+	if r.Version == "" {
+		return r.Name
+	}
+
+	return fmt.Sprintf("%s %s", r.Name, r.Version)
+}
+
+// AddPackage adds an installed package to the underlying manifest.
+func (d *DiscoveryManifest) AddPackage(p Package) {
+	d.Packages = append(d.Packages, p)
+}
+
+// FindPackage returns the installed package with the given name, or nil when
+// it is not installed.
+func (d *DiscoveryManifest) FindPackage(name string) *Package {
+	for i, p := range d.Packages {
+		if p.Name == name {
+			return &d.Packages[i]
+		}
+	}
+
+	return nil
+}
+
+// CheckPackageRequirements returns an error describing the first package a
+// recipe requires that is missing or installed in an unsupported version.
+// Requirements cannot be checked, and are assumed to be met, when no packages
+// were discovered on the host.
+func (d *DiscoveryManifest) CheckPackageRequirements(r Recipe) error {
+	if len(r.RequiredPackages) > 0 && len(d.Packages) == 0 {
+		log.Debugf("not checking the packages %s requires, no packages were discovered", r.Name)
+		return nil
+	}
+
+	for _, req := range r.RequiredPackages {
+		p := d.FindPackage(req.Name)
+		if p == nil {
+			return fmt.Errorf("%s requires package %s, which is not installed", r.Name, req)
+		}
+
+		if req.Version == "" {
+			continue
+		}
+
+		c, err := ParseVersionRange(req.Version)
+		if err != nil {
+			return fmt.Errorf("%s has an invalid version range %q for package %s: %s", r.Name, req.Version, req.Name, err)
+		}
+
+		v, err := ParseVersion(p.Version)
+		if err != nil {
+			return fmt.Errorf("%s requires package %s, but installed version %s could not be compared: %s", r.Name, req, p.Version, err)
+		}
+
+		if !c.Check(v) {
+			return fmt.Errorf("%s requires package %s, but version %s is installed", r.Name, req, p.Version)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscoveryManifest_CheckPackageRequirements(t *testing.T) {
+	m := DiscoveryManifest{
+		Packages: []Package{
+			{Name: "nginx", Version: "1.18.0-0ubuntu1.2", Source: "dpkg"},
+			{Name: "redis-server", Version: "5:5.0.7-2", Source: "dpkg"},
+		},
+	}
+
+	require.NoError(t, m.CheckPackageRequirements(Recipe{Name: "none"}))
+	require.NoError(t, m.CheckPackageRequirements(Recipe{
+		Name:             "nginx",
+		RequiredPackages: []PackageRequirement{{Name: "nginx", Version: ">= 1.18"}},
+	}))
+	require.NoError(t, m.CheckPackageRequirements(Recipe{
+		Name:             "redis",
+		RequiredPackages: []PackageRequirement{{Name: "redis-server"}},
+	}))
+
+	err := m.CheckPackageRequirements(Recipe{
+		Name:             "nginx",
+		RequiredPackages: []PackageRequirement{{Name: "nginx", Version: ">= 1.20"}},
+	})
+	require.EqualError(t, err, "nginx requires package nginx >= 1.20, but version 1.18.0-0ubuntu1.2 is installed")
+
+	err = m.CheckPackageRequirements(Recipe{
+		Name:             "mysql",
+		RequiredPackages: []PackageRequirement{{Name: "mysql-server"}},
+	})
+	require.EqualError(t, err, "mysql requires package mysql-server, which is not installed")
+}
+
+func TestDiscoveryManifest_CheckPackageRequirements_NoPackages(t *testing.T) {
+	m := DiscoveryManifest{}
+
+	require.NoError(t, m.CheckPackageRequirements(Recipe{
+		Name:             "nginx",
+		RequiredPackages: []PackageRequirement{{Name: "nginx", Version: ">= 1.18"}},
+	}))
+}
+
+func TestDiscoveryManifest_PackagesNotSerialized(t *testing.T) {
+	m := DiscoveryManifest{Hostname: "test"}
+	m.AddPackage(Package{Name: "nginx", Version: "1.18.0", Source: "dpkg"})
+
+	b, err := json.Marshal(m)
+	require.NoError(t, err)
+	require.NotContains(t, string(b), "nginx")
+}
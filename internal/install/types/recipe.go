@@ -22,12 +22,40 @@ type Recipe struct {
 	PreInstall        OpenInstallationPreInstallConfiguration  `json:"preInstall" yaml:"preInstall"`
 	PostInstall       OpenInstallationPostInstallConfiguration `json:"postInstall" yaml:"postInstall"`
 	ProcessMatch      []string                                 `json:"processMatch" yaml:"processMatch"`
+	ImageMatch        []string                                 `json:"imageMatch,omitempty" yaml:"imageMatch"`
+	PortMatch         []int                                    `json:"portMatch,omitempty" yaml:"portMatch"`
+	RequiredPackages  []PackageRequirement                     `json:"requiredPackages,omitempty" yaml:"requiredPackages"`
 	Repository        string                                   `json:"repository" yaml:"repository"`
 	SuccessLinkConfig OpenInstallationSuccessLinkConfig        `json:"successLinkConfig" yaml:"successLinkConfig"`
 	ValidationNRQL    string                                   `json:"validationNrql" yaml:"validationNrql"`
+	Kubernetes        KubernetesOutput                         `json:"kubernetes,omitempty" yaml:"kubernetes"`
 	Vars              map[string]interface{}
 }
 
+// KubernetesOutput is what a Kubernetes recipe renders instead of running its
+// install tasks on the host.  Both are templates of the recipe's vars.
+type KubernetesOutput struct {
+	Manifest   string `json:"manifest,omitempty" yaml:"manifest,omitempty"`
+	HelmValues string `json:"helmValues,omitempty" yaml:"helmValues,omitempty"`
+}
+
+// TargetsKubernetes returns true when the recipe has a Kubernetes install target.
+func (r *Recipe) TargetsKubernetes() bool {
+	for _, t := range r.InstallTargets {
+		if strings.EqualFold(string(t.Type), string(OpenInstallationTargetTypeTypes.KUBERNETES)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RendersKubernetesOutput returns true when the recipe is installed by
+// rendering Kubernetes manifests or Helm values rather than running host tasks.
+func (r *Recipe) RendersKubernetesOutput() bool {
+	return r.TargetsKubernetes() && (r.Kubernetes.Manifest != "" || r.Kubernetes.HelmValues != "")
+}
+
 func (r *Recipe) PostInstallMessage() string {
 	if r.PostInstall.Info != "" {
 		return r.PostInstall.Info
@@ -51,6 +79,9 @@ type LogMatch struct {
 	Attributes LogMatchAttributes `yaml:"attributes,omitempty"`
 	Pattern    string             `yaml:"pattern,omitempty"`
 	Systemd    string             `yaml:"systemd,omitempty"`
+	// Preview holds the latest lines of a discovered log, shown when asking
+	// whether to forward it.
+	Preview string `json:"-" yaml:"-"`
 }
 
 // LogMatchAttributes contains metadata about its parent LogMatch.
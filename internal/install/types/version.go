@@ -0,0 +1,95 @@
+package types
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Masterminds/semver"
+)
+
+var (
+	// versionCoreRegex matches the leading numeric part of a version, such as
+	// 1.18.0 in 1.18.0-6ubuntu14.3.
+	versionCoreRegex = regexp.MustCompile(`^\d+(\.\d+)*`)
+
+	// versionComparisonRegex matches a single comparison of a version range,
+	// such as >=18.04 or < 22.04.
+	versionComparisonRegex = regexp.MustCompile(`(!=|>=|=>|<=|=<|~>|[<>=~^])?\s*v?[0-9xX*][0-9A-Za-z.*+-]*`)
+)
+
+// versionRangeChars are the characters that make a version expression a range
+// rather than an exact version.
+const versionRangeChars = "<>=~^!*|,"
+
+// IsVersionRange returns true when a version expression is a range, such as
+// ">=18.04 <22.04", rather than an exact version.
+func IsVersionRange(expr string) bool {
+	return strings.ContainsAny(expr, versionRangeChars) || strings.Contains(expr, " - ")
+}
+
+// ParseVersion parses a distribution, kernel or package version as semver.
+// Versions are often not semver, so the epoch and anything after the leading
+// numeric part are dropped and versions with more than three parts are
+// truncated: 1:1.18.0-6ubuntu14.3 is 1.18.0, 5.4.0-1045-aws is 5.4.0,
+// 7.9.2009 is 7.9.2009 and 2 is 2.0.0.
+func ParseVersion(version string) (*semver.Version, error) {
+	v := strings.TrimSpace(version)
+	if i := strings.Index(v, ":"); i >= 0 {
+		v = v[i+1:]
+	}
+
+	core := versionCoreRegex.FindString(strings.TrimPrefix(v, "v"))
+	if core == "" {
+		return nil, fmt.Errorf("invalid version %q", version)
+	}
+
+	if parts := strings.Split(core, "."); len(parts) > 3 {
+		core = strings.Join(parts[:3], ".")
+	}
+
+	return semver.NewVersion(core)
+}
+
+// ParseVersionRange parses a version range.  Comparisons separated by spaces or
+// commas must all be satisfied, and ranges separated by || are alternatives, as
+// in ">=18.04 <22.04 || 16.04".
+func ParseVersionRange(expr string) (*semver.Constraints, error) {
+	ors := strings.Split(expr, "||")
+	for i, or := range ors {
+		// Hyphen ranges, such as 1.2 - 1.4, are parsed as they are.
+		if strings.Contains(or, " - ") {
+			continue
+		}
+
+		comparisons := versionComparisonRegex.FindAllString(or, -1)
+		if len(comparisons) == 0 {
+			return nil, fmt.Errorf("invalid version range %q", expr)
+		}
+
+		ors[i] = strings.Join(comparisons, ",")
+	}
+
+	return semver.NewConstraint(strings.Join(ors, "||"))
+}
+
+// MatchVersion checks a discovered version against an install target's version
+// expression, which is either an exact version compared case insensitively or
+// a range.
+func MatchVersion(expr string, version string) (bool, error) {
+	if !IsVersionRange(expr) {
+		return strings.EqualFold(expr, version), nil
+	}
+
+	c, err := ParseVersionRange(expr)
+	if err != nil {
+		return false, err
+	}
+
+	v, err := ParseVersion(version)
+	if err != nil {
+		return false, err
+	}
+
+	return c.Check(v), nil
+}
@@ -0,0 +1,60 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseVersion(t *testing.T) {
+	cases := map[string]string{
+		"1.18.0-6ubuntu14.3": "1.18.0",
+		"1:8.0.26-0ubuntu0":  "8.0.26",
+		"5.4.0-1045-aws":     "5.4.0",
+		"18.04":              "18.4.0",
+		"7.9.2009":           "7.9.2009",
+		"2":                  "2.0.0",
+		"5.7.33.1":           "5.7.33",
+	}
+
+	for version, expected := range cases {
+		v, err := ParseVersion(version)
+		require.NoError(t, err, version)
+		require.Equal(t, expected, v.String(), version)
+	}
+
+	_, err := ParseVersion("latest")
+	require.Error(t, err)
+}
+
+func TestMatchVersion(t *testing.T) {
+	cases := []struct {
+		expr    string
+		version string
+		matches bool
+	}{
+		{"18.04", "18.04", true},
+		{"18.04", "20.04", false},
+		{">=18.04 <22.04", "20.04", true},
+		{">=18.04 <22.04", "22.04", false},
+		{">= 18.04, < 22.04", "16.04", false},
+		{">=8 || 7.9.2009", "7.9.2009", true},
+		{">=8 || 7.9.2009", "7.8.2003", false},
+		{">=2", "2", true},
+		{"~7.9", "7.9.2009", true},
+		{">=5.4", "5.10.0-1045-aws", true},
+		{"1.2 - 1.4", "1.3", true},
+	}
+
+	for _, c := range cases {
+		matches, err := MatchVersion(c.expr, c.version)
+		require.NoError(t, err, c.expr)
+		require.Equal(t, c.matches, matches, "%s %s", c.expr, c.version)
+	}
+
+	_, err := MatchVersion(">=18.04", "")
+	require.Error(t, err)
+
+	_, err = MatchVersion(">=bionic", "18.04")
+	require.Error(t, err)
+}
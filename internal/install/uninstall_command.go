@@ -0,0 +1,76 @@
+package install
+
+import (
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/newrelic/newrelic-cli/internal/client"
+	"github.com/newrelic/newrelic-cli/internal/config"
+	"github.com/newrelic/newrelic-cli/internal/credentials"
+	"github.com/newrelic/newrelic-cli/internal/install/types"
+	"github.com/newrelic/newrelic-cli/internal/utils"
+	"github.com/newrelic/newrelic-client-go/newrelic"
+)
+
+var (
+	uninstallRecipeNames []string
+)
+
+// UninstallCommand represents the uninstall command.
+var UninstallCommand = &cobra.Command{
+	Use:     "uninstall",
+	Short:   "Uninstall New Relic instrumentation installed by a recipe.",
+	Example: `newrelic uninstall --recipe infrastructure-agent-installer`,
+	Run: func(cmd *cobra.Command, args []string) {
+		ic := InstallerContext{
+			AssumeYes:            assumeYes,
+			LocalRecipes:         localRecipes,
+			RecipeRepositories:   configuredRecipeRepositories(recipeRepositories),
+			InsecureRepositories: allowInsecureRepos,
+			RecipeNames:          uninstallRecipeNames,
+		}
+
+		if err := assertRecipeRepositoriesAreValid(ic.RecipeRepositories, ic.InsecureRepositories); err != nil {
+			log.Fatal(err)
+		}
+
+		config.InitFileLogger()
+
+		client.WithClientAndProfile(func(nrClient *newrelic.NewRelic, profile *credentials.Profile) {
+			if trace {
+				log.SetLevel(log.TraceLevel)
+				nrClient.SetLogLevel("trace")
+			} else if debug {
+				log.SetLevel(log.DebugLevel)
+				nrClient.SetLogLevel("debug")
+			}
+
+			err := assertProfileIsValid(profile)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			i := NewRecipeInstaller(ic, nrClient)
+
+			if err := i.Uninstall(); err != nil {
+				if err == types.ErrInterrupt {
+					return
+				}
+
+				log.Fatalf("We encountered an error during the uninstall: %s", err)
+			}
+		})
+	},
+}
+
+func init() {
+	UninstallCommand.Flags().StringSliceVarP(&uninstallRecipeNames, "recipe", "n", []string{}, "the name of a recipe to uninstall")
+	UninstallCommand.Flags().BoolVar(&debug, "debug", false, "debug level logging")
+	UninstallCommand.Flags().BoolVar(&trace, "trace", false, "trace level logging")
+	UninstallCommand.Flags().BoolVarP(&assumeYes, "assumeYes", "y", false, "use \"yes\" for all questions during uninstall")
+	UninstallCommand.Flags().StringVarP(&localRecipes, "localRecipes", "", "", "a path to local recipes to load instead of service other fetching")
+	UninstallCommand.Flags().StringSliceVar(&recipeRepositories, "recipeRepository", []string{}, "a git repository (URL#ref), HTTP recipe index or directory to load recipes from before the recipe service; repeat for more, in order of precedence")
+	UninstallCommand.Flags().BoolVar(&allowInsecureRepos, "allowInsecureRecipeRepositories", false, "allows HTTP recipe indexes served over plain http")
+
+	utils.LogIfError(UninstallCommand.MarkFlagRequired("recipe"))
+}
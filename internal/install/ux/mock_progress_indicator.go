@@ -1,6 +1,9 @@
 package ux
 
-type MockProgressIndicator struct{}
+type MockProgressIndicator struct {
+	StartCallCount int
+	StopCallCount  int
+}
 
 func NewMockProgressIndicator() *MockProgressIndicator {
 	return &MockProgressIndicator{}
@@ -9,11 +12,13 @@ func NewMockProgressIndicator() *MockProgressIndicator {
 func (s *MockProgressIndicator) Fail(string) {
 }
 
-func (s MockProgressIndicator) Success(string) {
+func (s *MockProgressIndicator) Success(string) {
 }
 
 func (s *MockProgressIndicator) Start(string) {
+	s.StartCallCount++
 }
 
-func (s MockProgressIndicator) Stop() {
+func (s *MockProgressIndicator) Stop() {
+	s.StopCallCount++
 }
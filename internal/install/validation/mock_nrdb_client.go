@@ -2,15 +2,21 @@ package validation
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"sync"
 
 	"github.com/newrelic/newrelic-client-go/pkg/nrdb"
 )
 
+// MockNRDBClient answers the NRQL queries of NerdGraph requests.  Each request
+// counts as one attempt, however many queries it batches.
 type MockNRDBClient struct {
 	results  func() []nrdb.NRDBResult
 	attempts int
+	queries  int
 	error    string
+	mu       sync.Mutex
 }
 
 func NewMockNRDBClient() *MockNRDBClient {
@@ -21,16 +27,38 @@ func NewMockNRDBClient() *MockNRDBClient {
 	}
 }
 
-func (c *MockNRDBClient) QueryWithContext(ctx context.Context, accountID int, nrql nrdb.NRQL) (*nrdb.NRDBResultContainer, error) {
+func (c *MockNRDBClient) QueryWithResponseAndContext(ctx context.Context, query string, variables map[string]interface{}, respBody interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	c.attempts++
 
 	if c.error != "" {
-		return nil, errors.New(c.error)
+		return errors.New(c.error)
 	}
 
-	return &nrdb.NRDBResultContainer{
-		Results: c.results(),
-	}, nil
+	account := map[string]interface{}{}
+	for k := range variables {
+		if k == "accountId" {
+			continue
+		}
+
+		c.queries++
+		account[k] = map[string]interface{}{
+			"results": c.results(),
+		}
+	}
+
+	b, err := json.Marshal(map[string]interface{}{
+		"actor": map[string]interface{}{
+			"account": account,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(b, respBody)
 }
 
 func (c *MockNRDBClient) ThrowError(message string) {
@@ -48,5 +76,16 @@ func (c *MockNRDBClient) ReturnResultsAfterNAttempts(before []nrdb.NRDBResult, a
 }
 
 func (c *MockNRDBClient) Attempts() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	return c.attempts
 }
+
+// Queries returns how many NRQL queries were answered across all requests.
+func (c *MockNRDBClient) Queries() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.queries
+}
@@ -2,6 +2,7 @@ package validation
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	"github.com/newrelic/newrelic-cli/internal/install/types"
@@ -14,6 +15,8 @@ type MockRecipeValidator struct {
 	ValidateCallCount int
 	ValidateVal       string
 	ValidateVals      []string
+	SuspendCallCount  int
+	mu                sync.Mutex
 }
 
 func NewMockRecipeValidator() *MockRecipeValidator {
@@ -21,6 +24,7 @@ func NewMockRecipeValidator() *MockRecipeValidator {
 }
 
 func (m *MockRecipeValidator) Validate(ctx context.Context, dm types.DiscoveryManifest, r types.Recipe) (string, error) {
+	m.mu.Lock()
 	m.ValidateCallCount++
 
 	var err error
@@ -39,8 +43,19 @@ func (m *MockRecipeValidator) Validate(ctx context.Context, dm types.DiscoveryMa
 	} else {
 		val = m.ValidateVal
 	}
+	m.mu.Unlock()
 
 	time.Sleep(1 * time.Millisecond)
 
 	return val, err
 }
+
+func (m *MockRecipeValidator) SuspendProgress() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.SuspendCallCount++
+}
+
+func (m *MockRecipeValidator) ResumeProgress() {
+}
@@ -0,0 +1,9 @@
+package validation
+
+import (
+	"context"
+)
+
+type nerdGraphClient interface {
+	QueryWithResponseAndContext(context.Context, string, map[string]interface{}, interface{}) error
+}
@@ -1,11 +0,0 @@
-package validation
-
-import (
-	"context"
-
-	"github.com/newrelic/newrelic-client-go/pkg/nrdb"
-)
-
-type nrdbClient interface {
-	QueryWithContext(context.Context, int, nrdb.NRQL) (*nrdb.NRDBResultContainer, error)
-}
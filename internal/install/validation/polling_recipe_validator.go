@@ -6,6 +6,8 @@ import (
 	"errors"
 	"fmt"
 	"html/template"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/newrelic/newrelic-cli/internal/credentials"
@@ -17,27 +19,76 @@ import (
 type contextKey int
 
 const (
-	defaultMaxAttempts            = 60
-	defaultInterval               = 5 * time.Second
-	TestIdentifierKey  contextKey = iota
+	// DefaultTimeout is how long validation polls for data by default.
+	DefaultTimeout = 5 * time.Minute
+
+	// DefaultInterval is how often validation polls for data by default.
+	DefaultInterval = 5 * time.Second
+
+	TestIdentifierKey contextKey = iota
+
+	validationProgressMsg = "Checking for data in New Relic (this may take a few minutes)..."
 )
 
+// PollingConfig controls how long and how often a PollingRecipeValidator polls
+// NRDB.  Zero values are replaced with the defaults.
+type PollingConfig struct {
+	Timeout  time.Duration
+	Interval time.Duration
+}
+
 // PollingRecipeValidator is an implementation of the RecipeValidator interface
-// that polls NRDB to assert data is being reported for the given recipe.
+// that polls NRDB to assert data is being reported for the given recipe.  It is
+// safe to validate several recipes concurrently; concurrent validations share
+// a single progress indicator, and their queries are batched into NerdGraph
+// requests, running identical queries once.
 type PollingRecipeValidator struct {
 	maxAttempts       int
 	interval          time.Duration
-	client            nrdbClient
+	client            nerdGraphClient
 	progressIndicator ux.ProgressIndicator
+
+	mu         sync.Mutex
+	validating int
+	suspended  int
+	queries    map[string]*sharedQuery
+	pending    []string
+}
+
+// sharedQuery is the result of a query shared by concurrent validations.
+type sharedQuery struct {
+	done    chan struct{}
+	results []nrdb.NRDBResult
+	err     error
 }
 
 // NewPollingRecipeValidator returns a new instance of PollingRecipeValidator.
-func NewPollingRecipeValidator(c nrdbClient) *PollingRecipeValidator {
+func NewPollingRecipeValidator(c nerdGraphClient) *PollingRecipeValidator {
+	return NewPollingRecipeValidatorWithConfig(c, PollingConfig{})
+}
+
+// NewPollingRecipeValidatorWithConfig returns a new instance of
+// PollingRecipeValidator that polls according to the given config.
+func NewPollingRecipeValidatorWithConfig(c nerdGraphClient, cfg PollingConfig) *PollingRecipeValidator {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultInterval
+	}
+
+	maxAttempts := int(cfg.Timeout / cfg.Interval)
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
 	v := PollingRecipeValidator{
-		maxAttempts:       defaultMaxAttempts,
-		interval:          defaultInterval,
+		maxAttempts:       maxAttempts,
+		interval:          cfg.Interval,
 		client:            c,
 		progressIndicator: ux.NewSpinner(),
+		queries:           map[string]*sharedQuery{},
 	}
 
 	return &v
@@ -45,7 +96,74 @@ func NewPollingRecipeValidator(c nrdbClient) *PollingRecipeValidator {
 
 // Validate polls NRDB to assert data is being reported for the given recipe.
 func (m *PollingRecipeValidator) Validate(ctx context.Context, dm types.DiscoveryManifest, r types.Recipe) (string, error) {
-	return m.waitForData(ctx, dm, r)
+	m.startProgress()
+
+	entityGUID, err := m.waitForData(ctx, dm, r)
+
+	m.stopProgress(err)
+
+	return entityGUID, err
+}
+
+// startProgress starts the progress indicator for the first of any concurrent
+// validations.
+func (m *PollingRecipeValidator) startProgress() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.validating == 0 && m.suspended == 0 {
+		m.progressIndicator.Start(validationProgressMsg)
+	}
+
+	m.validating++
+}
+
+// stopProgress stops the progress indicator once the last of any concurrent
+// validations completes.
+func (m *PollingRecipeValidator) stopProgress(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.validating--
+
+	if m.validating > 0 || m.suspended > 0 {
+		return
+	}
+
+	if err != nil {
+		m.progressIndicator.Fail("")
+	} else {
+		m.progressIndicator.Success("")
+	}
+
+	m.progressIndicator.Stop()
+}
+
+// SuspendProgress stops the progress indicator of any validations in progress
+// until ResumeProgress is called, so that it does not interleave with the
+// prompts and output of recipes installed in the meantime.
+func (m *PollingRecipeValidator) SuspendProgress() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.suspended == 0 && m.validating > 0 {
+		m.progressIndicator.Stop()
+	}
+
+	m.suspended++
+}
+
+// ResumeProgress restarts the progress indicator stopped by SuspendProgress if
+// validations are still in progress.
+func (m *PollingRecipeValidator) ResumeProgress() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.suspended--
+
+	if m.suspended == 0 && m.validating > 0 {
+		m.progressIndicator.Start(validationProgressMsg)
+	}
 }
 
 func (m *PollingRecipeValidator) waitForData(ctx context.Context, dm types.DiscoveryManifest, r types.Recipe) (string, error) {
@@ -53,26 +171,19 @@ func (m *PollingRecipeValidator) waitForData(ctx context.Context, dm types.Disco
 	ticker := time.NewTicker(m.interval)
 	defer ticker.Stop()
 
-	progressMsg := "Checking for data in New Relic (this may take a few minutes)..."
-	m.progressIndicator.Start(progressMsg)
-	defer m.progressIndicator.Stop()
-
 	for {
 		if count == m.maxAttempts {
-			m.progressIndicator.Fail("")
 			return "", fmt.Errorf("reached max validation attempts")
 		}
 
 		ok, entityGUID, err := m.tryValidate(ctx, dm, r)
 		if err != nil {
-			m.progressIndicator.Fail("")
 			return "", err
 		}
 
 		count++
 
 		if ok {
-			m.progressIndicator.Success("")
 			return entityGUID, nil
 		}
 
@@ -81,7 +192,6 @@ func (m *PollingRecipeValidator) waitForData(ctx context.Context, dm types.Disco
 			continue
 
 		case <-ctx.Done():
-			m.progressIndicator.Fail("")
 			return "", fmt.Errorf("validation cancelled")
 		}
 	}
@@ -93,7 +203,7 @@ func (m *PollingRecipeValidator) tryValidate(ctx context.Context, dm types.Disco
 		return false, "", err
 	}
 
-	results, err := m.executeQuery(ctx, query)
+	results, err := m.executeSharedQuery(ctx, query)
 	if err != nil {
 		return false, "", err
 	}
@@ -146,18 +256,145 @@ func substituteHostname(dm types.DiscoveryManifest, r types.Recipe) (string, err
 	return tpl.String(), nil
 }
 
+// executeSharedQuery runs a query in a batch with the queries of concurrent
+// validations requested within half a polling interval of it, so that they are
+// sent in a single NerdGraph request.  A query already waiting in the batch is
+// shared rather than run twice.
+func (m *PollingRecipeValidator) executeSharedQuery(ctx context.Context, query string) ([]nrdb.NRDBResult, error) {
+	m.mu.Lock()
+
+	if q, ok := m.queries[query]; ok {
+		select {
+		case <-q.done:
+		default:
+			m.mu.Unlock()
+			return q.wait(ctx)
+		}
+	}
+
+	q := &sharedQuery{done: make(chan struct{})}
+	m.queries[query] = q
+
+	// The first query of a batch waits for others to join it.
+	m.pending = append(m.pending, query)
+	if len(m.pending) == 1 {
+		go m.executeBatchAfter(ctx, m.interval/2)
+	}
+
+	m.mu.Unlock()
+
+	return q.wait(ctx)
+}
+
+// wait returns the results of a shared query once it has run, unless the
+// waiting validation is canceled first.
+func (q *sharedQuery) wait(ctx context.Context) ([]nrdb.NRDBResult, error) {
+	select {
+	case <-q.done:
+		return q.results, q.err
+	case <-ctx.Done():
+		return nil, fmt.Errorf("validation cancelled")
+	}
+}
+
+// executeBatchAfter runs the pending queries in a single request once the
+// delay has passed.
+func (m *PollingRecipeValidator) executeBatchAfter(ctx context.Context, delay time.Duration) {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+
+	m.mu.Lock()
+	queries := m.pending
+	m.pending = nil
+	batch := make([]*sharedQuery, len(queries))
+	for i, query := range queries {
+		batch[i] = m.queries[query]
+	}
+	m.mu.Unlock()
+
+	if ctx.Err() != nil {
+		for _, q := range batch {
+			q.err = fmt.Errorf("validation cancelled")
+			close(q.done)
+		}
+
+		return
+	}
+
+	results, err := m.executeQueries(ctx, queries)
+
+	// A query that fails the batch, such as one with invalid NRQL, should only
+	// fail its own validation, so the queries are run again one by one.
+	if err != nil && len(queries) > 1 {
+		for i, query := range queries {
+			batch[i].results, batch[i].err = m.executeQuery(ctx, query)
+			close(batch[i].done)
+		}
+
+		return
+	}
+
+	for i, q := range batch {
+		if err == nil {
+			q.results = results[i]
+		}
+
+		q.err = err
+		close(q.done)
+	}
+}
+
 func (m *PollingRecipeValidator) executeQuery(ctx context.Context, query string) ([]nrdb.NRDBResult, error) {
+	results, err := m.executeQueries(ctx, []string{query})
+	if err != nil {
+		return nil, err
+	}
+
+	return results[0], nil
+}
+
+// executeQueries runs several NRQL queries in a single NerdGraph request, each
+// as an aliased nrql field of the account.
+func (m *PollingRecipeValidator) executeQueries(ctx context.Context, queries []string) ([][]nrdb.NRDBResult, error) {
 	profile := credentials.DefaultProfile()
 	if profile == nil || profile.AccountID == 0 {
 		return nil, errors.New("no account ID found in default profile")
 	}
 
-	nrql := nrdb.NRQL(query)
+	params := []string{"$accountId: Int!"}
+	fields := []string{}
+	vars := map[string]interface{}{
+		"accountId": profile.AccountID,
+	}
 
-	result, err := m.client.QueryWithContext(ctx, profile.AccountID, nrql)
-	if err != nil {
+	for i, query := range queries {
+		alias := fmt.Sprintf("q%d", i)
+		params = append(params, fmt.Sprintf("$%s: Nrql!", alias))
+		fields = append(fields, fmt.Sprintf("%s: nrql(query: $%s) { results }", alias, alias))
+		vars[alias] = query
+	}
+
+	gql := fmt.Sprintf("query(%s) { actor { account(id: $accountId) { %s } } }", strings.Join(params, ", "), strings.Join(fields, " "))
+
+	var resp struct {
+		Actor struct {
+			Account map[string]nrdb.NRDBResultContainer
+		}
+	}
+
+	if err := m.client.QueryWithResponseAndContext(ctx, gql, vars, &resp); err != nil {
 		return nil, err
 	}
 
-	return result.Results, nil
+	results := make([][]nrdb.NRDBResult, len(queries))
+	for i := range queries {
+		results[i] = resp.Actor.Account[fmt.Sprintf("q%d", i)].Results
+	}
+
+	return results, nil
 }
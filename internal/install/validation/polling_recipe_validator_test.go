@@ -4,6 +4,7 @@ package validation
 
 import (
 	"context"
+	"sync"
 	"testing"
 	"time"
 
@@ -144,6 +145,149 @@ func TestValidate_QueryError(t *testing.T) {
 	require.EqualError(t, err, "test error")
 }
 
+func TestValidate_ConcurrentSharesQueries(t *testing.T) {
+	credentials.SetDefaultProfile(credentials.Profile{AccountID: 12345})
+	c := NewMockNRDBClient()
+
+	c.ReturnResultsAfterNAttempts(emptyResults, nonEmptyResults, 1)
+
+	pi := ux.NewMockProgressIndicator()
+	v := NewPollingRecipeValidator(c)
+	v.progressIndicator = pi
+
+	m := types.DiscoveryManifest{}
+	recipes := []types.Recipe{
+		{Name: "a", ValidationNRQL: "SELECT count(*) FROM Log"},
+		{Name: "b", ValidationNRQL: "SELECT count(*) FROM Log"},
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(recipes))
+	for i, r := range recipes {
+		wg.Add(1)
+		go func(i int, r types.Recipe) {
+			defer wg.Done()
+			_, errs[i] = v.Validate(getTestContext(), m, r)
+		}(i, r)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+	require.Equal(t, 1, c.Attempts())
+}
+
+func TestValidate_ConcurrentBatchesDistinctQueries(t *testing.T) {
+	credentials.SetDefaultProfile(credentials.Profile{AccountID: 12345})
+	c := NewMockNRDBClient()
+
+	c.ReturnResultsAfterNAttempts(emptyResults, nonEmptyResults, 1)
+
+	pi := ux.NewMockProgressIndicator()
+	v := NewPollingRecipeValidator(c)
+	v.progressIndicator = pi
+	v.interval = 100 * time.Millisecond
+
+	m := types.DiscoveryManifest{}
+	recipes := []types.Recipe{
+		{Name: "a", ValidationNRQL: "SELECT count(*) FROM Log"},
+		{Name: "b", ValidationNRQL: "SELECT count(*) FROM SystemSample"},
+		{Name: "c", ValidationNRQL: "SELECT count(*) FROM SystemSample"},
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(recipes))
+	for i, r := range recipes {
+		wg.Add(1)
+		go func(i int, r types.Recipe) {
+			defer wg.Done()
+			_, errs[i] = v.Validate(getTestContext(), m, r)
+		}(i, r)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+	require.Equal(t, 1, c.Attempts())
+	require.Equal(t, 2, c.Queries())
+}
+
+func TestValidate_WaitingValidationIsCanceled(t *testing.T) {
+	credentials.SetDefaultProfile(credentials.Profile{AccountID: 12345})
+	c := NewMockNRDBClient()
+
+	c.ReturnResultsAfterNAttempts(emptyResults, nonEmptyResults, 1)
+
+	pi := ux.NewMockProgressIndicator()
+	v := NewPollingRecipeValidator(c)
+	v.progressIndicator = pi
+	v.interval = 2 * time.Second
+
+	m := types.DiscoveryManifest{}
+	r := types.Recipe{Name: "a", ValidationNRQL: "SELECT count(*) FROM Log"}
+
+	leaderCtx, cancelLeader := context.WithCancel(getTestContext())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = v.Validate(leaderCtx, m, r)
+	}()
+
+	ctx, cancel := context.WithTimeout(getTestContext(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := v.Validate(ctx, m, r)
+	require.Error(t, err)
+	require.Less(t, int64(time.Since(start)), int64(time.Second))
+
+	cancelLeader()
+	<-done
+}
+
+func TestValidate_SuspendProgress(t *testing.T) {
+	pi := ux.NewMockProgressIndicator()
+	v := NewPollingRecipeValidator(NewMockNRDBClient())
+	v.progressIndicator = pi
+
+	v.startProgress()
+	require.Equal(t, 1, pi.StartCallCount)
+
+	v.SuspendProgress()
+	v.SuspendProgress()
+	require.Equal(t, 1, pi.StopCallCount)
+
+	v.ResumeProgress()
+	require.Equal(t, 1, pi.StartCallCount)
+
+	v.ResumeProgress()
+	require.Equal(t, 2, pi.StartCallCount)
+
+	// A validation that completes while suspended leaves the indicator stopped.
+	v.SuspendProgress()
+	v.stopProgress(nil)
+	v.ResumeProgress()
+	require.Equal(t, 2, pi.StartCallCount)
+	require.Equal(t, 2, pi.StopCallCount)
+}
+
+func TestNewPollingRecipeValidatorWithConfig(t *testing.T) {
+	c := NewMockNRDBClient()
+
+	v := NewPollingRecipeValidatorWithConfig(c, PollingConfig{
+		Timeout:  time.Minute,
+		Interval: 10 * time.Second,
+	})
+	require.Equal(t, 6, v.maxAttempts)
+	require.Equal(t, 10*time.Second, v.interval)
+
+	v = NewPollingRecipeValidatorWithConfig(c, PollingConfig{})
+	require.Equal(t, 60, v.maxAttempts)
+	require.Equal(t, DefaultInterval, v.interval)
+}
+
 func getTestContext() context.Context {
 	return context.WithValue(context.Background(), TestIdentifierKey, true)
 }
@@ -6,7 +6,11 @@ import (
 	"github.com/newrelic/newrelic-cli/internal/install/types"
 )
 
-// RecipeValidator validates installation of a recipe.
+// RecipeValidator validates installation of a recipe.  Validations run while
+// other recipes install, so their progress can be suspended while those recipes
+// prompt for input or print output.
 type RecipeValidator interface {
 	Validate(context.Context, types.DiscoveryManifest, types.Recipe) (entityGUID string, err error)
+	SuspendProgress()
+	ResumeProgress()
 }
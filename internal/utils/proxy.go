@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// ParseProxyURL parses the URL of an HTTP, HTTPS or SOCKS5 proxy, such as
+// http://proxy.example.com:3128.
+func ParseProxyURL(proxy string) (*url.URL, error) {
+	u, err := url.Parse(strings.TrimSpace(proxy))
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL %q: %s", proxy, err)
+	}
+
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("invalid proxy URL %q, the scheme must be http, https or socks5", proxy)
+	}
+
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL %q, a host is required", proxy)
+	}
+
+	return u, nil
+}
+
+// DefaultNoProxy lists the hosts that are reached directly when a proxy is
+// configured: the local host and the cloud instance metadata endpoint.
+const DefaultNoProxy = "localhost,127.0.0.1,169.254.169.254"
+
+// NoProxy returns the hosts set by the NO_PROXY environment variable, or
+// DefaultNoProxy when it is not set.
+func NoProxy() string {
+	for _, k := range []string{"NO_PROXY", "no_proxy"} {
+		if v := os.Getenv(k); v != "" {
+			return v
+		}
+	}
+
+	return DefaultNoProxy
+}
+
+// NewHTTPTransport returns an HTTP transport that sends requests through the
+// given proxy, or through the proxy set by the HTTPS_PROXY and HTTP_PROXY
+// environment variables when proxy is empty.
+func NewHTTPTransport(proxy string) (*http.Transport, error) {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+
+	if proxy == "" {
+		return t, nil
+	}
+
+	u, err := ParseProxyURL(proxy)
+	if err != nil {
+		return nil, err
+	}
+
+	t.Proxy = http.ProxyURL(u)
+
+	return t, nil
+}
+
+// NewHTTPClient returns an HTTP client that sends requests through the given
+// proxy, as NewHTTPTransport does.
+func NewHTTPClient(proxy string) (*http.Client, error) {
+	t, err := NewHTTPTransport(proxy)
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Client{Transport: t}, nil
+}